@@ -17,9 +17,17 @@ func main() {
 	// Initialize dependencies
 	deps := app.InitializeDependencies(application)
 
+	// Grant the admin role to the configured administrators
+	if err := application.PromoteAdmins(deps); err != nil {
+		log.Fatalf("Failed to promote administrators: %v", err)
+	}
+
 	// Start guest account cleanup
 	application.StartGuestCleanup(deps)
 
+	// Embed chats missing from semantic search
+	application.StartChatIndexing(deps)
+
 	// Setup router
 	router := app.SetupRouter(application, deps)
 
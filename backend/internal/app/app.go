@@ -8,6 +8,12 @@ import (
 	"github.com/llmchatbot/backend/internal/config"
 	"github.com/llmchatbot/backend/internal/database"
 	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/internal/service"
+	"github.com/llmchatbot/backend/pkg/blobstore"
+	"github.com/llmchatbot/backend/pkg/denylist"
+	"github.com/llmchatbot/backend/pkg/mailer"
+	"github.com/llmchatbot/backend/pkg/sso"
 	"gorm.io/gorm"
 )
 
@@ -15,7 +21,13 @@ import (
 type App struct {
 	Config        *config.Config
 	DB            *gorm.DB
+	Storage       blobstore.Store
+	Denylist      denylist.Store
+	Mailer        mailer.Mailer
+	SSOProviders  map[string]sso.Provider
+	VectorIndex   service.VectorIndex
 	cleanupCancel context.CancelFunc
+	indexCancel   context.CancelFunc
 }
 
 // NewApp creates and initializes a new application instance
@@ -31,27 +43,118 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
+	// Folders and tags of deleted users were kept before they referenced users
+	if err := database.DeleteOrphanedRows("folders", "tags"); err != nil {
+		return nil, err
+	}
+
 	// Run migrations
-	if err := database.Migrate(&model.User{}, &model.ChatSession{}, &model.Message{}); err != nil {
+	if err := database.Migrate(
+		&model.User{},
+		&model.Folder{},
+		&model.Tag{},
+		&model.ChatSession{},
+		&model.Message{},
+		&model.Document{},
+		&model.DocumentChunk{},
+		&model.Attachment{},
+		&model.ChatEmbedding{},
+		&model.ChatShare{},
+		&model.AuthSession{},
+		&model.RefreshToken{},
+		&model.UserToken{},
+		&model.UserIdentity{},
+		&model.OIDCLogin{},
+		&model.TOTPFactor{},
+		&model.RecoveryCode{},
+		&model.APIKey{},
+		&model.Role{},
+	); err != nil {
+		return nil, err
+	}
+	if err := database.MigrateSearch(cfg.Search.TextConfig); err != nil {
+		return nil, err
+	}
+
+	// Initialize attachment storage
+	storage, err := service.NewBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize revoked access token storage
+	deny, err := service.NewDenylist(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize outgoing email
+	mail, err := service.NewMailer(cfg)
+	if err != nil {
 		return nil, err
 	}
 
+	// Initialize single sign-on providers
+	ssoProviders, err := service.NewSSOProviders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize document chunk search
+	vectorIndex := service.NewVectorIndex(cfg, repository.NewDocumentRepository(database.GetDB()))
+
 	return &App{
-		Config: cfg,
-		DB:     database.GetDB(),
+		Config:       cfg,
+		DB:           database.GetDB(),
+		Storage:      storage,
+		Denylist:     deny,
+		Mailer:       mail,
+		SSOProviders: ssoProviders,
+		VectorIndex:  vectorIndex,
 	}, nil
 }
 
 // Close gracefully closes application resources
 func (a *App) Close() error {
-	// Stop cleanup goroutine
+	// Stop cleanup and indexing goroutines
 	if a.cleanupCancel != nil {
 		a.cleanupCancel()
 	}
+	if a.indexCancel != nil {
+		a.indexCancel()
+	}
+	if err := a.Denylist.Close(); err != nil {
+		log.Printf("Error closing token denylist: %v", err)
+	}
+	if err := a.VectorIndex.Close(); err != nil {
+		log.Printf("Error closing vector index: %v", err)
+	}
 	return database.Close()
 }
 
-// StartGuestCleanup starts a background goroutine to clean up expired guest accounts
+// PromoteAdmins gives the admin role to the users listed in ADMIN_EMAILS
+func (a *App) PromoteAdmins(deps *Dependencies) error {
+	return deps.RoleService.PromoteAdmins(a.Config.Admin.Emails)
+}
+
+// StartChatIndexing starts the background indexer of chats with new messages and
+// embeds chats created before semantic search was enabled or after the embedding
+// model changed
+func (a *App) StartChatIndexing(deps *Dependencies) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.indexCancel = cancel
+
+	go deps.ChatEmbeddingService.RunIndexer(ctx)
+	go func() {
+		if err := deps.ChatEmbeddingService.IndexMissing(); err != nil {
+			log.Printf("Error indexing chats: %v", err)
+		}
+	}()
+}
+
+// StartGuestCleanup starts a background goroutine to clean up expired guest accounts,
+// expired refresh tokens, login sessions, emailed account tokens and unfinished
+// single sign-ons, and attachments and indexed document chunks left behind by deleted chats
 func (a *App) StartGuestCleanup(deps *Dependencies) {
 	ctx, cancel := context.WithCancel(context.Background())
 	a.cleanupCancel = cancel
@@ -64,6 +167,21 @@ func (a *App) StartGuestCleanup(deps *Dependencies) {
 		if err := deps.AuthService.CleanupExpiredGuests(); err != nil {
 			log.Printf("Error cleaning up expired guests: %v", err)
 		}
+		if err := deps.AuthService.CleanupExpiredSessions(); err != nil {
+			log.Printf("Error cleaning up login sessions: %v", err)
+		}
+		if err := deps.AccountService.CleanupExpiredTokens(); err != nil {
+			log.Printf("Error cleaning up account tokens: %v", err)
+		}
+		if err := deps.OIDCService.CleanupExpiredLogins(); err != nil {
+			log.Printf("Error cleaning up single sign-ons: %v", err)
+		}
+		if err := deps.AttachmentService.CleanupOrphaned(); err != nil {
+			log.Printf("Error cleaning up attachments: %v", err)
+		}
+		if err := deps.DocumentService.PruneIndex(); err != nil {
+			log.Printf("Error pruning vector index: %v", err)
+		}
 
 		for {
 			select {
@@ -75,6 +193,21 @@ func (a *App) StartGuestCleanup(deps *Dependencies) {
 				} else {
 					log.Println("Successfully cleaned up expired guest accounts")
 				}
+				if err := deps.AuthService.CleanupExpiredSessions(); err != nil {
+					log.Printf("Error cleaning up login sessions: %v", err)
+				}
+				if err := deps.AccountService.CleanupExpiredTokens(); err != nil {
+					log.Printf("Error cleaning up account tokens: %v", err)
+				}
+				if err := deps.OIDCService.CleanupExpiredLogins(); err != nil {
+					log.Printf("Error cleaning up single sign-ons: %v", err)
+				}
+				if err := deps.AttachmentService.CleanupOrphaned(); err != nil {
+					log.Printf("Error cleaning up attachments: %v", err)
+				}
+				if err := deps.DocumentService.PruneIndex(); err != nil {
+					log.Printf("Error pruning vector index: %v", err)
+				}
 			}
 		}
 	}()
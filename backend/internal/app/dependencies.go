@@ -9,22 +9,60 @@ import (
 // Dependencies holds all application dependencies
 type Dependencies struct {
 	// Repositories
-	UserRepo    *repository.UserRepository
-	ChatRepo    *repository.ChatRepository
-	MessageRepo *repository.MessageRepository
+	UserRepo          *repository.UserRepository
+	ChatRepo          *repository.ChatRepository
+	MessageRepo       *repository.MessageRepository
+	DocumentRepo      *repository.DocumentRepository
+	AttachmentRepo    *repository.AttachmentRepository
+	SearchRepo        *repository.SearchRepository
+	ChatEmbeddingRepo *repository.ChatEmbeddingRepository
+	ChatShareRepo     *repository.ChatShareRepository
+	FolderRepo        *repository.FolderRepository
+	TagRepo           *repository.TagRepository
 
 	// Services
-	AuthService      *service.AuthService
-	UserService      *service.UserService
-	ChatService      *service.ChatService
-	MessageService   *service.MessageService
-	StreamingService *service.StreamingService
+	AuthService          *service.AuthService
+	AccountService       *service.AccountService
+	OIDCService          *service.OIDCService
+	MFAService           *service.MFAService
+	APIKeyService        *service.APIKeyService
+	RoleService          *service.RoleService
+	AdminService         *service.AdminService
+	UserService          *service.UserService
+	ChatService          *service.ChatService
+	MessageService       *service.MessageService
+	StreamingService     *service.StreamingService
+	DocumentService      *service.DocumentService
+	AttachmentService    *service.AttachmentService
+	TableService         *service.TableService
+	SearchService        *service.SearchService
+	ChatEmbeddingService *service.ChatEmbeddingService
+	ExportService        *service.ExportService
+	ImportService        *service.ImportService
+	ShareService         *service.ShareService
+	FolderService        *service.FolderService
+	TagService           *service.TagService
+	ModelRegistry        *service.ModelRegistry
 
 	// Handlers
-	AuthHandler      *handler.AuthHandler
-	UserHandler      *handler.UserHandler
-	ChatHandler      *handler.ChatHandler
-	StreamingHandler *handler.StreamingHandler
+	AuthHandler       *handler.AuthHandler
+	SessionHandler    *handler.SessionHandler
+	OIDCHandler       *handler.OIDCHandler
+	MFAHandler        *handler.MFAHandler
+	APIKeyHandler     *handler.APIKeyHandler
+	AdminHandler      *handler.AdminHandler
+	UserHandler       *handler.UserHandler
+	ChatHandler       *handler.ChatHandler
+	StreamingHandler  *handler.StreamingHandler
+	DocumentHandler   *handler.DocumentHandler
+	AttachmentHandler *handler.AttachmentHandler
+	ModelHandler      *handler.ModelHandler
+	SearchHandler     *handler.SearchHandler
+	ExportHandler     *handler.ExportHandler
+	ImportHandler     *handler.ImportHandler
+	ShareHandler      *handler.ShareHandler
+	FolderHandler     *handler.FolderHandler
+	TagHandler        *handler.TagHandler
 }
 
 // InitializeDependencies initializes all application dependencies
@@ -33,34 +71,131 @@ func InitializeDependencies(a *App) *Dependencies {
 	userRepo := repository.NewUserRepository(a.DB)
 	chatRepo := repository.NewChatRepository(a.DB)
 	messageRepo := repository.NewMessageRepository(a.DB)
+	documentRepo := repository.NewDocumentRepository(a.DB)
+	attachmentRepo := repository.NewAttachmentRepository(a.DB)
+	searchRepo := repository.NewSearchRepository(a.DB, a.Config.Search.TextConfig)
+	chatEmbeddingRepo := repository.NewChatEmbeddingRepository(a.DB)
+	chatShareRepo := repository.NewChatShareRepository(a.DB)
+	folderRepo := repository.NewFolderRepository(a.DB)
+	tagRepo := repository.NewTagRepository(a.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(a.DB)
+	authSessionRepo := repository.NewAuthSessionRepository(a.DB)
+	userTokenRepo := repository.NewUserTokenRepository(a.DB)
+	userIdentityRepo := repository.NewUserIdentityRepository(a.DB)
+	oidcLoginRepo := repository.NewOIDCLoginRepository(a.DB)
+	mfaRepo := repository.NewMFARepository(a.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(a.DB)
+	roleRepo := repository.NewRoleRepository(a.DB)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, a.Config)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, a.Mailer, a.Config)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, userTokenRepo, a.Config)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, authSessionRepo, accountService, mfaService, a.Denylist, a.Config)
+	oidcService := service.NewOIDCService(a.SSOProviders, oidcLoginRepo, userIdentityRepo, userRepo, userTokenRepo, apiKeyRepo, mfaRepo, authService, a.Config)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, a.Config)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	adminService := service.NewAdminService(userRepo, roleService, authService, a.Config)
 	userService := service.NewUserService(userRepo)
-	chatService := service.NewChatService(chatRepo, messageRepo)
-	messageService := service.NewMessageService(messageRepo, chatRepo)
-	streamingService := service.NewStreamingService(a.Config, messageService)
+	modelRegistry := service.NewModelRegistry(a.Config)
+	attachmentService := service.NewAttachmentService(attachmentRepo, chatRepo, a.Storage, modelRegistry, a.Config)
+	chatService := service.NewChatService(chatRepo, messageRepo, documentRepo, attachmentService, a.VectorIndex)
+	messageService := service.NewMessageService(messageRepo, chatRepo, attachmentRepo)
+	streamingService := service.NewStreamingService(a.Config, messageService, attachmentService, modelRegistry)
+	embedder := service.NewEmbedder(a.Config)
+	documentService := service.NewDocumentService(
+		documentRepo,
+		chatRepo,
+		embedder,
+		a.VectorIndex,
+		a.Config,
+	)
+	tableService := service.NewTableService(attachmentService, streamingService, a.Config)
+	searchService := service.NewSearchService(searchRepo)
+	chatEmbeddingService := service.NewChatEmbeddingService(chatEmbeddingRepo, chatRepo, messageRepo, embedder, a.Config)
+	exportService := service.NewExportService(chatRepo)
+	importService := service.NewImportService(chatRepo, chatEmbeddingService, modelRegistry, a.Config)
+	shareService := service.NewShareService(chatShareRepo, chatRepo)
+	folderService := service.NewFolderService(folderRepo, chatRepo)
+	tagService := service.NewTagService(tagRepo, chatRepo)
+	guestService := service.NewGuestService(userRepo, chatRepo, a.Config)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, accountService)
+	sessionHandler := handler.NewSessionHandler(authService)
+	mfaHandler := handler.NewMFAHandler(authService, mfaService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	adminHandler := handler.NewAdminHandler(adminService, roleService)
+	oidcHandler := handler.NewOIDCHandler(
+		oidcService,
+		a.Config.Account.AppURL,
+		a.Config.Server.Environment == "production",
+		int(a.Config.OIDC.LoginExpiry.Seconds()),
+	)
 	userHandler := handler.NewUserHandler(userService)
-	chatHandler := handler.NewChatHandler(chatService)
-	streamingHandler := handler.NewStreamingHandler(streamingService, messageService, chatService)
+	chatHandler := handler.NewChatHandler(chatService, guestService)
+	streamingHandler := handler.NewStreamingHandler(streamingService, messageService, chatService, documentService, attachmentService, tableService, chatEmbeddingService, guestService)
+	documentHandler := handler.NewDocumentHandler(documentService, a.Config.RAG.MaxUploadSize)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, a.Config.Storage.MaxFileSize)
+	modelHandler := handler.NewModelHandler(modelRegistry)
+	searchHandler := handler.NewSearchHandler(searchService, chatEmbeddingService)
+	exportHandler := handler.NewExportHandler(exportService)
+	importHandler := handler.NewImportHandler(importService, a.Config.Import.MaxUploadSize)
+	shareHandler := handler.NewShareHandler(shareService)
+	folderHandler := handler.NewFolderHandler(folderService)
+	tagHandler := handler.NewTagHandler(tagService)
 
 	return &Dependencies{
-		UserRepo:    userRepo,
-		ChatRepo:    chatRepo,
-		MessageRepo: messageRepo,
+		UserRepo:          userRepo,
+		ChatRepo:          chatRepo,
+		MessageRepo:       messageRepo,
+		DocumentRepo:      documentRepo,
+		AttachmentRepo:    attachmentRepo,
+		SearchRepo:        searchRepo,
+		ChatEmbeddingRepo: chatEmbeddingRepo,
+		ChatShareRepo:     chatShareRepo,
+		FolderRepo:        folderRepo,
+		TagRepo:           tagRepo,
 
-		AuthService:      authService,
-		UserService:      userService,
-		ChatService:      chatService,
-		MessageService:   messageService,
-		StreamingService: streamingService,
+		AuthService:          authService,
+		AccountService:       accountService,
+		OIDCService:          oidcService,
+		MFAService:           mfaService,
+		APIKeyService:        apiKeyService,
+		RoleService:          roleService,
+		AdminService:         adminService,
+		UserService:          userService,
+		ChatService:          chatService,
+		MessageService:       messageService,
+		StreamingService:     streamingService,
+		DocumentService:      documentService,
+		AttachmentService:    attachmentService,
+		TableService:         tableService,
+		SearchService:        searchService,
+		ChatEmbeddingService: chatEmbeddingService,
+		ExportService:        exportService,
+		ImportService:        importService,
+		ShareService:         shareService,
+		FolderService:        folderService,
+		TagService:           tagService,
+		ModelRegistry:        modelRegistry,
 
-		AuthHandler:      authHandler,
-		UserHandler:      userHandler,
-		ChatHandler:      chatHandler,
-		StreamingHandler: streamingHandler,
+		AuthHandler:       authHandler,
+		SessionHandler:    sessionHandler,
+		OIDCHandler:       oidcHandler,
+		MFAHandler:        mfaHandler,
+		APIKeyHandler:     apiKeyHandler,
+		AdminHandler:      adminHandler,
+		UserHandler:       userHandler,
+		ChatHandler:       chatHandler,
+		StreamingHandler:  streamingHandler,
+		DocumentHandler:   documentHandler,
+		AttachmentHandler: attachmentHandler,
+		ModelHandler:      modelHandler,
+		SearchHandler:     searchHandler,
+		ExportHandler:     exportHandler,
+		ImportHandler:     importHandler,
+		ShareHandler:      shareHandler,
+		FolderHandler:     folderHandler,
+		TagHandler:        tagHandler,
 	}
 }
@@ -1,12 +1,14 @@
 package app
 
 import (
+	"log"
 	"net/http"
 	"path/filepath"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/model"
 )
 
 // SetupRouter configures all routes and middleware
@@ -18,6 +20,12 @@ func SetupRouter(a *App, deps *Dependencies) *gin.Engine {
 
 	router := gin.New()
 
+	// Client IPs are used for rate and guest limits, so X-Forwarded-For is
+	// only honoured when the request comes from a configured proxy
+	if err := router.SetTrustedProxies(a.Config.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Global middleware
 	router.Use(middleware.LoggingMiddleware())
 	router.Use(middleware.SecurityMiddleware())
@@ -59,21 +67,60 @@ func SetupRouter(a *App, deps *Dependencies) *gin.Engine {
 			auth.POST("/refresh", deps.AuthHandler.RefreshToken)
 			auth.POST("/logout", deps.AuthHandler.Logout)
 			auth.POST("/guest", deps.AuthHandler.CreateGuestSession)
+			auth.POST("/guest/upgrade",
+				middleware.JWTAuthMiddleware(deps.AuthService.GetJWTManager(), deps.AuthService.GetDenylist(), nil),
+				deps.AuthHandler.UpgradeGuest)
+			auth.POST("/password/forgot", deps.AuthHandler.ForgotPassword)
+			auth.POST("/password/reset", deps.AuthHandler.ResetPassword)
+			auth.POST("/verify-email", deps.AuthHandler.VerifyEmail)
+			auth.POST("/verify-email/resend", deps.AuthHandler.ResendVerification)
+			auth.POST("/mfa/verify", deps.MFAHandler.Verify)
+			auth.POST("/mfa/enroll", deps.MFAHandler.BeginSignInEnrollment)
+			auth.POST("/mfa/enroll/confirm", deps.MFAHandler.ConfirmSignInEnrollment)
+			auth.GET("/oidc/providers", deps.OIDCHandler.GetProviders)
+			auth.GET("/oidc/:provider/login", deps.OIDCHandler.Login)
+			auth.GET("/oidc/:provider/callback", deps.OIDCHandler.Callback)
+			auth.POST("/oidc/exchange", deps.OIDCHandler.Exchange)
 		}
 
-		// Protected routes (require authentication)
+		// Shared chats (public, the owner is recognized if authenticated)
+		shared := v1.Group("/shared")
+		shared.Use(middleware.OptionalAuthMiddleware(deps.AuthService.GetJWTManager(), deps.AuthService.GetDenylist()))
+		{
+			shared.GET("/:token", deps.ShareHandler.GetSharedChat)
+		}
+
+		// Protected routes (require authentication). Personal API keys are accepted
+		// as well, limited by their scopes; account routes require a login session.
 		protected := v1.Group("")
-		protected.Use(middleware.JWTAuthMiddleware(deps.AuthService.GetJWTManager()))
+		protected.Use(middleware.JWTAuthMiddleware(deps.AuthService.GetJWTManager(), deps.AuthService.GetDenylist(), deps.APIKeyService))
 		{
 			// User routes
 			users := protected.Group("/users")
+			users.Use(middleware.DenyAPIKeys())
 			{
 				users.GET("/me", deps.UserHandler.GetProfile)
 				users.PUT("/me", deps.UserHandler.UpdateProfile)
+				users.PUT("/me/password", middleware.DenyGuests(), deps.AuthHandler.ChangePassword)
+				users.GET("/me/storage", deps.AttachmentHandler.GetStorageUsage)
+				users.GET("/me/sessions", deps.SessionHandler.GetSessions)
+				users.DELETE("/me/sessions", deps.SessionHandler.RevokeOtherSessions)
+				users.DELETE("/me/sessions/:id", deps.SessionHandler.RevokeSession)
+				users.GET("/me/mfa", deps.MFAHandler.GetStatus)
+				users.POST("/me/mfa/totp", middleware.DenyGuests(), deps.MFAHandler.BeginEnrollment)
+				users.POST("/me/mfa/totp/confirm", middleware.DenyGuests(), deps.MFAHandler.ConfirmEnrollment)
+				users.DELETE("/me/mfa/totp", deps.MFAHandler.Disable)
+				users.POST("/me/mfa/recovery-codes", deps.MFAHandler.RegenerateRecoveryCodes)
+				users.GET("/me/identities", deps.OIDCHandler.GetIdentities)
+				users.DELETE("/me/identities/:id", deps.OIDCHandler.UnlinkIdentity)
+				users.GET("/me/api-keys", deps.APIKeyHandler.GetAPIKeys)
+				users.POST("/me/api-keys", middleware.DenyGuests(), deps.APIKeyHandler.CreateAPIKey)
+				users.DELETE("/me/api-keys/:id", deps.APIKeyHandler.RevokeAPIKey)
 			}
 
 			// Chat routes
 			chats := protected.Group("/chats")
+			chats.Use(middleware.RequireChatScope())
 			{
 				chats.GET("", deps.ChatHandler.GetChatSessions)
 				chats.POST("", deps.ChatHandler.CreateChatSession)
@@ -84,10 +131,108 @@ func SetupRouter(a *App, deps *Dependencies) *gin.Engine {
 				chats.DELETE("/:id/permanent", deps.ChatHandler.DeleteChatSession)
 				chats.POST("/restore", deps.ChatHandler.RestoreChatSessions)
 				chats.POST("/delete", deps.ChatHandler.DeleteChatSessions)
+				chats.POST("/move", deps.FolderHandler.MoveChats)
+				chats.POST("/tag", deps.TagHandler.TagChats)
+				chats.POST("/untag", deps.TagHandler.UntagChats)
+				chats.GET("/export", deps.ExportHandler.ExportChats)
+				chats.POST("/import", middleware.DenyGuests(), deps.ImportHandler.ImportChats)
+				chats.GET("/:id/export", deps.ExportHandler.ExportChat)
+				chats.GET("/:id/messages", deps.ChatHandler.GetMessages)
+				chats.GET("/:id/messages/pinned", deps.ChatHandler.GetPinnedMessages)
+				chats.POST("/:id/messages/:message_id/pin", deps.ChatHandler.PinMessage)
+				chats.DELETE("/:id/messages/:message_id/pin", deps.ChatHandler.UnpinMessage)
+				chats.POST("/:id/messages/:message_id/exclude", deps.ChatHandler.ExcludeMessage)
+				chats.DELETE("/:id/messages/:message_id/exclude", deps.ChatHandler.IncludeMessage)
+				chats.DELETE("/:id/messages/:message_id", deps.ChatHandler.DeleteMessage)
+				chats.POST("/:id/pin", deps.ChatHandler.PinChatSession)
+				chats.DELETE("/:id/pin", deps.ChatHandler.UnpinChatSession)
+				chats.GET("/:id/related", deps.SearchHandler.GetRelatedChats)
+				chats.GET("/:id/shares", deps.ShareHandler.GetChatShares)
+				chats.POST("/:id/shares", deps.ShareHandler.CreateShare)
+				chats.GET("/:id/attachments", deps.AttachmentHandler.GetAttachments)
+				chats.POST("/:id/attachments", middleware.DenyGuests(), deps.AttachmentHandler.UploadAttachment)
+			}
+
+			// Folder routes
+			folders := protected.Group("/folders")
+			folders.Use(middleware.RequireChatScope())
+			{
+				folders.GET("", deps.FolderHandler.GetFolders)
+				folders.POST("", deps.FolderHandler.CreateFolder)
+				folders.PUT("/:id", deps.FolderHandler.UpdateFolder)
+				folders.DELETE("/:id", deps.FolderHandler.DeleteFolder)
+			}
+
+			// Tag routes
+			tags := protected.Group("/tags")
+			tags.Use(middleware.RequireChatScope())
+			{
+				tags.GET("", deps.TagHandler.GetTags)
+				tags.POST("", deps.TagHandler.CreateTag)
+				tags.PUT("/:id", deps.TagHandler.UpdateTag)
+				tags.DELETE("/:id", deps.TagHandler.DeleteTag)
+			}
+
+			// Share link routes
+			shares := protected.Group("/shares")
+			shares.Use(middleware.RequireChatScope())
+			{
+				shares.GET("", deps.ShareHandler.GetShares)
+				shares.DELETE("/:id", deps.ShareHandler.RevokeShare)
+			}
+
+			// Document routes (RAG)
+			documents := protected.Group("/documents")
+			documents.Use(middleware.RequireChatScope())
+			{
+				documents.GET("", deps.DocumentHandler.GetDocuments)
+				documents.POST("", middleware.DenyGuests(), deps.DocumentHandler.UploadDocument)
+				documents.GET("/:id", deps.DocumentHandler.GetDocument)
+				documents.DELETE("/:id", deps.DocumentHandler.DeleteDocument)
+				documents.GET("/:id/chunks/:chunk_id", deps.DocumentHandler.GetDocumentChunk)
+			}
+
+			// Attachment routes
+			attachments := protected.Group("/attachments")
+			attachments.Use(middleware.RequireChatScope())
+			{
+				attachments.GET("/:id", deps.AttachmentHandler.GetAttachment)
+				attachments.GET("/:id/content", deps.AttachmentHandler.DownloadAttachment)
+				attachments.DELETE("/:id", deps.AttachmentHandler.DeleteAttachment)
+			}
+
+			// Search routes
+			protected.GET("/search", middleware.RequireChatScope(), deps.SearchHandler.Search)
+			protected.GET("/search/semantic", middleware.RequireChatScope(), deps.SearchHandler.SemanticSearch)
+
+			// Model routes
+			protected.GET("/models", deps.ModelHandler.GetModels)
+
+			// Admin routes, each limited to roles with the permission
+			admin := protected.Group("/admin")
+			admin.Use(middleware.DenyAPIKeys())
+			{
+				canReadUsers := middleware.RequirePermission(deps.RoleService, model.PermissionUsersRead)
+				canManageUsers := middleware.RequirePermission(deps.RoleService, model.PermissionUsersManage)
+				canManageGuests := middleware.RequirePermission(deps.RoleService, model.PermissionGuestsManage)
+				canManageRoles := middleware.RequirePermission(deps.RoleService, model.PermissionRolesManage)
+
+				admin.GET("/users", canReadUsers, deps.AdminHandler.ListUsers)
+				admin.GET("/users/:id", canReadUsers, deps.AdminHandler.GetUser)
+				admin.POST("/users/:id/deactivate", canManageUsers, deps.AdminHandler.DeactivateUser)
+				admin.POST("/users/:id/reactivate", canManageUsers, deps.AdminHandler.ReactivateUser)
+				admin.POST("/users/:id/logout", canManageUsers, deps.AdminHandler.ForceLogout)
+				admin.PUT("/users/:id/role", canManageRoles, deps.AdminHandler.SetUserRole)
+				admin.POST("/users/:id/guest-expiration", canManageGuests, deps.AdminHandler.ResetGuestExpiration)
+				admin.GET("/roles", canManageRoles, deps.AdminHandler.GetRoles)
+				admin.POST("/roles", canManageRoles, deps.AdminHandler.CreateRole)
+				admin.PUT("/roles/:name", canManageRoles, deps.AdminHandler.UpdateRole)
+				admin.DELETE("/roles/:name", canManageRoles, deps.AdminHandler.DeleteRole)
 			}
 
 			// Streaming routes
 			stream := protected.Group("/stream")
+			stream.Use(middleware.RequireScope(model.APIKeyScopeStream))
 			{
 				stream.GET("/chat/:session_id", deps.StreamingHandler.StreamChat)
 			}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -16,6 +17,18 @@ type Config struct {
 	JWT      JWTConfig
 	Redis    RedisConfig
 	LLM      LLMConfig
+	RAG      RAGConfig
+	Storage  StorageConfig
+	Tabular  TabularConfig
+	Search   SearchConfig
+	Import   ImportConfig
+	Guest    GuestConfig
+	Mail     MailConfig
+	Account  AccountConfig
+	OIDC     OIDCConfig
+	MFA      MFAConfig
+	APIKey   APIKeyConfig
+	Admin    AdminConfig
 }
 
 // ServerConfig holds server configuration
@@ -26,6 +39,9 @@ type ServerConfig struct {
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
 	Environment  string
+	// Reverse proxies whose X-Forwarded-For header is trusted for client IPs;
+	// empty trusts none and uses the address of the connection
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database configuration
@@ -46,6 +62,7 @@ type JWTConfig struct {
 	SecretKey          string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	DenylistBackend    string // "memory" or "redis"
 }
 
 // RedisConfig holds Redis configuration
@@ -60,6 +77,124 @@ type RedisConfig struct {
 type LLMConfig struct {
 	BaseURL string
 	Timeout time.Duration
+	Models  []string // Model registry: "name" or "name:vision" for models accepting image inputs
+}
+
+// RAGConfig holds document retrieval (RAG) configuration
+type RAGConfig struct {
+	ChunkSize     int     // Chunk size in characters
+	ChunkOverlap  int     // Overlap between neighbouring chunks in characters
+	TopK          int     // Number of chunks added to the prompt
+	MinScore      float64 // Minimum cosine similarity for a chunk to be used
+	MaxUploadSize int64   // Maximum uploaded document size in bytes
+
+	Embedder        string // "remote" (LLM service) or "local" (in-process feature hashing)
+	LocalDimensions int    // Vector size of the local embedder
+	VectorIndex     string // "postgres" (scan stored embeddings) or "memory" (in-process flat index)
+	IndexPath       string // File the in-memory index is persisted to; rebuilt from Postgres if empty
+}
+
+// StorageConfig holds attachment storage configuration
+type StorageConfig struct {
+	Backend     string // "local" or "s3"
+	LocalPath   string
+	MaxFileSize int64 // Maximum attachment size in bytes
+	UserQuota   int64 // Maximum total attachment size per user in bytes
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+// TabularConfig holds CSV/TSV analysis configuration
+type TabularConfig struct {
+	MaxRows    int // Maximum number of rows loaded from a file
+	ResultRows int // Maximum number of result rows shown to the model
+	MaxQueries int // Maximum number of queries the model may run per message
+}
+
+// SearchConfig holds full-text search configuration
+type SearchConfig struct {
+	TextConfig       string  // Postgres text search configuration, e.g. "simple", "english", "russian"
+	SemanticMinScore float64 // Minimum cosine similarity for semantic search and related chats
+}
+
+// ImportConfig holds chat import configuration
+type ImportConfig struct {
+	MaxUploadSize int64 // Maximum import file size in bytes
+	MaxChats      int   // Maximum number of conversations per import
+}
+
+// GuestConfig holds limits of guest accounts; zero disables a limit
+type GuestConfig struct {
+	MaxChats          int           // Maximum number of chats of a guest
+	MaxMessagesPerDay int           // Maximum messages sent by a guest in 24 hours
+	MaxTokens         int           // Maximum tokens of a generated response
+	Models            []string      // Models available to guests; empty allows all models
+	MaxPerIP          int           // Maximum guest accounts created from one IP within IPWindow
+	IPWindow          time.Duration // Window of the guest creation limit
+	Lifetime          time.Duration // Time until a guest account expires
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Backend  string // "log" (application log), "file" (.eml files in FilePath) or "smtp"
+	From     string
+	FilePath string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// AccountConfig holds password recovery and email verification configuration
+type AccountConfig struct {
+	RequireEmailVerification bool          // Block sign-in until the email is verified
+	PasswordResetExpiry      time.Duration // Lifetime of a password reset link
+	EmailVerificationExpiry  time.Duration // Lifetime of an email verification link
+	AppURL                   string        // Frontend URL the links in emails point to
+}
+
+// OIDCConfig holds single sign-on configuration
+type OIDCConfig struct {
+	Providers   []OIDCProviderConfig
+	CallbackURL string        // Public base URL of the API the providers redirect back to
+	LoginExpiry time.Duration // Time allowed to complete a sign-in at the provider
+}
+
+// MFAConfig holds two-factor authentication configuration
+type MFAConfig struct {
+	Issuer            string        // Account issuer shown in authenticator apps
+	RequiredRoles     []string      // Roles that must use two-factor authentication
+	EncryptionKey     string        // Key TOTP secrets are encrypted with; defaults to the JWT secret
+	ChallengeExpiry   time.Duration // Time allowed to enter the code after the password
+	MaxFailedAttempts int           // Consecutive wrong codes before the factor is locked
+	LockoutDuration   time.Duration
+}
+
+// AdminConfig holds administration configuration
+type AdminConfig struct {
+	Emails []string // Users given the admin role at startup
+}
+
+// APIKeyConfig holds personal API key configuration
+type APIKeyConfig struct {
+	MaxPerUser int
+}
+
+// OIDCProviderConfig holds the settings of a single sign-on provider
+type OIDCProviderConfig struct {
+	ID           string // Used in URLs, e.g. "google"
+	Name         string // Shown on the login page
+	Type         string // "oidc" (OpenID Connect discovery) or "github"
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string // Empty uses the provider type's defaults
 }
 
 // Load loads configuration from environment variables
@@ -69,12 +204,13 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			Host:         getEnv("SERVER_HOST", "0.0.0.0"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
-			Environment:  getEnv("ENVIRONMENT", "development"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			Host:           getEnv("SERVER_HOST", "0.0.0.0"),
+			ReadTimeout:    getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:   getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:    getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Environment:    getEnv("ENVIRONMENT", "development"),
+			TrustedProxies: getListEnv("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -91,6 +227,7 @@ func Load() (*Config, error) {
 			SecretKey:          getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 			AccessTokenExpiry:  getDurationEnv("JWT_ACCESS_TOKEN_EXPIRY", 15*time.Minute),
 			RefreshTokenExpiry: getDurationEnv("JWT_REFRESH_TOKEN_EXPIRY", 7*24*time.Hour),
+			DenylistBackend:    getEnv("JWT_DENYLIST_BACKEND", defaultDenylistBackend(getEnv("ENVIRONMENT", "development"))),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -101,6 +238,88 @@ func Load() (*Config, error) {
 		LLM: LLMConfig{
 			BaseURL: getEnv("LLM_SERVICE_URL", "http://localhost:5000"),
 			Timeout: getDurationEnv("LLM_SERVICE_TIMEOUT", 5*time.Minute),
+			Models:  getListEnv("LLM_MODELS", []string{"qwen2.5-3b"}),
+		},
+		RAG: RAGConfig{
+			ChunkSize:     getIntEnv("RAG_CHUNK_SIZE", 1000),
+			ChunkOverlap:  getIntEnv("RAG_CHUNK_OVERLAP", 200),
+			TopK:          getIntEnv("RAG_TOP_K", 4),
+			MinScore:      getFloatEnv("RAG_MIN_SCORE", 0.2),
+			MaxUploadSize: int64(getIntEnv("RAG_MAX_UPLOAD_SIZE", 10*1024*1024)),
+
+			Embedder:        getEnv("RAG_EMBEDDER", "remote"),
+			LocalDimensions: getIntEnv("RAG_LOCAL_DIMENSIONS", 512),
+			VectorIndex:     getEnv("RAG_VECTOR_INDEX", "postgres"),
+			IndexPath:       getEnv("RAG_INDEX_PATH", ""),
+		},
+		Storage: StorageConfig{
+			Backend:     getEnv("STORAGE_BACKEND", "local"),
+			LocalPath:   getEnv("STORAGE_LOCAL_PATH", "./data/attachments"),
+			MaxFileSize: int64(getIntEnv("STORAGE_MAX_FILE_SIZE", 10*1024*1024)),
+			UserQuota:   int64(getIntEnv("STORAGE_USER_QUOTA", 100*1024*1024)),
+
+			S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+			S3Region:       getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:       getEnv("S3_BUCKET", ""),
+			S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+			S3UsePathStyle: getEnv("S3_USE_PATH_STYLE", "true") == "true",
+		},
+		Tabular: TabularConfig{
+			MaxRows:    getIntEnv("TABLE_MAX_ROWS", 100000),
+			ResultRows: getIntEnv("TABLE_RESULT_ROWS", 50),
+			MaxQueries: getIntEnv("TABLE_MAX_QUERIES", 3),
+		},
+		Search: SearchConfig{
+			TextConfig:       getEnv("SEARCH_TEXT_CONFIG", "simple"),
+			SemanticMinScore: getFloatEnv("SEARCH_SEMANTIC_MIN_SCORE", 0.2),
+		},
+		Import: ImportConfig{
+			MaxUploadSize: int64(getIntEnv("IMPORT_MAX_UPLOAD_SIZE", 100*1024*1024)),
+			MaxChats:      getIntEnv("IMPORT_MAX_CHATS", 5000),
+		},
+		Guest: GuestConfig{
+			MaxChats:          getIntEnv("GUEST_MAX_CHATS", 10),
+			MaxMessagesPerDay: getIntEnv("GUEST_MAX_MESSAGES_PER_DAY", 50),
+			MaxTokens:         getIntEnv("GUEST_MAX_TOKENS", 512),
+			Models:            getListEnv("GUEST_MODELS", nil),
+			MaxPerIP:          getIntEnv("GUEST_MAX_PER_IP", 5),
+			IPWindow:          getDurationEnv("GUEST_IP_WINDOW", time.Hour),
+			Lifetime:          getDurationEnv("GUEST_LIFETIME", 24*time.Hour),
+		},
+		Mail: MailConfig{
+			Backend:      getEnv("MAIL_BACKEND", defaultMailBackend(getEnv("ENVIRONMENT", "development"))),
+			From:         getEnv("MAIL_FROM", "LLM Chatbot <noreply@localhost>"),
+			FilePath:     getEnv("MAIL_FILE_PATH", "./data/mail"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		Account: AccountConfig{
+			RequireEmailVerification: getEnv("ACCOUNT_REQUIRE_EMAIL_VERIFICATION", "false") == "true",
+			PasswordResetExpiry:      getDurationEnv("ACCOUNT_PASSWORD_RESET_EXPIRY", time.Hour),
+			EmailVerificationExpiry:  getDurationEnv("ACCOUNT_EMAIL_VERIFICATION_EXPIRY", 48*time.Hour),
+			AppURL:                   strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/"),
+		},
+		OIDC: OIDCConfig{
+			Providers:   loadOIDCProviders(),
+			CallbackURL: strings.TrimRight(getEnv("OIDC_CALLBACK_URL", "http://localhost:3000"), "/"),
+			LoginExpiry: getDurationEnv("OIDC_LOGIN_EXPIRY", 10*time.Minute),
+		},
+		MFA: MFAConfig{
+			Issuer:            getEnv("MFA_ISSUER", "LLM Chatbot"),
+			RequiredRoles:     getListEnv("MFA_REQUIRED_ROLES", nil),
+			EncryptionKey:     getEnv("MFA_ENCRYPTION_KEY", ""),
+			ChallengeExpiry:   getDurationEnv("MFA_CHALLENGE_EXPIRY", 5*time.Minute),
+			MaxFailedAttempts: getIntEnv("MFA_MAX_FAILED_ATTEMPTS", 10),
+			LockoutDuration:   getDurationEnv("MFA_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		APIKey: APIKeyConfig{
+			MaxPerUser: getIntEnv("API_KEY_MAX_PER_USER", 20),
+		},
+		Admin: AdminConfig{
+			Emails: getListEnv("ADMIN_EMAILS", nil),
 		},
 	}
 
@@ -111,10 +330,39 @@ func Load() (*Config, error) {
 	if config.JWT.SecretKey == "your-secret-key-change-in-production" && config.Server.Environment == "production" {
 		return nil, fmt.Errorf("JWT_SECRET must be set in production")
 	}
+	if config.Mail.Backend == "log" && config.Server.Environment == "production" {
+		return nil, fmt.Errorf("MAIL_BACKEND=log writes password reset links to the log and cannot be used in production")
+	}
+	if config.MFA.EncryptionKey == "" {
+		// Rotating JWT_SECRET must not make the stored TOTP secrets unreadable
+		if config.Server.Environment == "production" {
+			return nil, fmt.Errorf("MFA_ENCRYPTION_KEY must be set in production")
+		}
+		config.MFA.EncryptionKey = config.JWT.SecretKey
+	}
 
 	return config, nil
 }
 
+// defaultDenylistBackend returns the revoked token store used when JWT_DENYLIST_BACKEND
+// is not set: memory in development and Redis in production, where several
+// instances must share revocations
+func defaultDenylistBackend(environment string) string {
+	if environment == "production" {
+		return "redis"
+	}
+	return "memory"
+}
+
+// defaultMailBackend returns the mail backend used when MAIL_BACKEND is not set:
+// emails are only logged in development and sent over SMTP in production
+func defaultMailBackend(environment string) string {
+	if environment == "production" {
+		return "smtp"
+	}
+	return "log"
+}
+
 // getEnv gets environment variable or returns default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -133,6 +381,32 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getFloatEnv gets float environment variable or returns default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv gets comma-separated list environment variable or returns default value
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getDurationEnv gets duration environment variable or returns default value
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -143,6 +417,32 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// loadOIDCProviders loads the providers listed in OIDC_PROVIDERS from
+// OIDC_<ID>_* environment variables
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, id := range getListEnv("OIDC_PROVIDERS", nil) {
+		id = strings.ToLower(id)
+		prefix := "OIDC_" + strings.ToUpper(id) + "_"
+
+		defaultType := "oidc"
+		if id == "github" {
+			defaultType = "github"
+		}
+
+		providers = append(providers, OIDCProviderConfig{
+			ID:           id,
+			Name:         getEnv(prefix+"NAME", id),
+			Type:         getEnv(prefix+"TYPE", defaultType),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getListEnv(prefix+"SCOPES", nil),
+		})
+	}
+	return providers
+}
+
 // GetDSN returns database connection string
 func (d *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -0,0 +1,106 @@
+package config
+
+import "testing"
+
+func TestMailBackendDefault(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+
+	t.Setenv("ENVIRONMENT", "development")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Mail.Backend != "log" {
+		t.Errorf("development mail backend = %q, want log", cfg.Mail.Backend)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Mail.Backend != "smtp" {
+		t.Errorf("production mail backend = %q, want smtp", cfg.Mail.Backend)
+	}
+}
+
+func TestMailBackendLogRefusedInProduction(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("MAIL_BACKEND", "log")
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted MAIL_BACKEND=log in production")
+	}
+
+	t.Setenv("MAIL_BACKEND", "file")
+	if _, err := Load(); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestDenylistBackendDefault(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+	t.Setenv("MAIL_BACKEND", "file")
+
+	t.Setenv("ENVIRONMENT", "development")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWT.DenylistBackend != "memory" {
+		t.Errorf("development denylist backend = %q, want memory", cfg.JWT.DenylistBackend)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWT.DenylistBackend != "redis" {
+		t.Errorf("production denylist backend = %q, want redis", cfg.JWT.DenylistBackend)
+	}
+
+	t.Setenv("JWT_DENYLIST_BACKEND", "memory")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWT.DenylistBackend != "memory" {
+		t.Errorf("explicit denylist backend = %q, want memory", cfg.JWT.DenylistBackend)
+	}
+}
+
+func TestMFAEncryptionKeyRequiredInProduction(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("MAIL_BACKEND", "file")
+	t.Setenv("MFA_ENCRYPTION_KEY", "")
+
+	t.Setenv("ENVIRONMENT", "development")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MFA.EncryptionKey != "test-secret" {
+		t.Errorf("development MFA encryption key = %q, want JWT_SECRET", cfg.MFA.EncryptionKey)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted a missing MFA_ENCRYPTION_KEY in production")
+	}
+
+	t.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MFA.EncryptionKey != "test-mfa-key" {
+		t.Errorf("production MFA encryption key = %q, want MFA_ENCRYPTION_KEY", cfg.MFA.EncryptionKey)
+	}
+}
@@ -141,6 +141,31 @@ func Migrate(models ...interface{}) error {
 	return nil
 }
 
+// DeleteOrphanedRows deletes rows of the given tables whose user no longer exists,
+// so foreign keys to users can be added to tables created without them
+func DeleteOrphanedRows(tables ...string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+	if !DB.Migrator().HasTable("users") {
+		return nil
+	}
+
+	for _, table := range tables {
+		if !DB.Migrator().HasTable(table) {
+			continue
+		}
+		result := DB.Exec(fmt.Sprintf("DELETE FROM %s WHERE user_id NOT IN (SELECT id FROM users)", table))
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete orphaned %s: %w", table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Deleted %d orphaned rows from %s", result.RowsAffected, table)
+		}
+	}
+	return nil
+}
+
 // SetUserContext sets the current user ID for RLS (Row Level Security)
 func SetUserContext(db *gorm.DB, userID string) error {
 	sqlDB, err := db.DB()
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+)
+
+// textSearchConfigPattern restricts text search configuration names used in DDL
+var textSearchConfigPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// MigrateSearch adds generated tsvector columns and GIN indexes for full-text search
+// over chat titles and message contents. The statements are idempotent.
+// Note: the text search configuration is fixed when the columns are created;
+// drop the search_vector columns to rebuild them with another configuration.
+func MigrateSearch(textConfig string) error {
+	if DB == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+	if !textSearchConfigPattern.MatchString(textConfig) {
+		return fmt.Errorf("invalid text search configuration: %q", textConfig)
+	}
+
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('%s', coalesce(content, ''))) STORED`, textConfig),
+		`CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector)`,
+		fmt.Sprintf(`ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('%s', coalesce(title, ''))) STORED`, textConfig),
+		`CREATE INDEX IF NOT EXISTS idx_chat_sessions_search_vector ON chat_sessions USING GIN (search_vector)`,
+	}
+
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to run search migrations: %w", err)
+		}
+	}
+
+	log.Println("Search migrations completed successfully")
+	return nil
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// AdminUserResponse represents a user as seen by administrators
+type AdminUserResponse struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	Role          string     `json:"role"`
+	IsActive      bool       `json:"is_active"`
+	IsGuest       bool       `json:"is_guest"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"` // Expiry of a guest account
+}
+
+// AdminUserListResponse represents a page of users
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+}
+
+// SetUserRoleRequest represents assigning a role to a user
+type SetUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// RoleResponse represents a role and its permissions
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	IsBuiltIn   bool     `json:"is_built_in"`
+}
+
+// CreateRoleRequest represents a request to create a custom role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents a request to update a custom role.
+// Permissions replace the current ones when set.
+type UpdateRoleRequest struct {
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
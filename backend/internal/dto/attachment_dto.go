@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// AttachmentResponse represents attachment response
+type AttachmentResponse struct {
+	ID            string    `json:"id"`
+	ChatSessionID string    `json:"chat_session_id"`
+	MessageID     string    `json:"message_id,omitempty"`
+	Filename      string    `json:"filename"`
+	MimeType      string    `json:"mime_type"`
+	Size          int64     `json:"size"`
+	Checksum      string    `json:"checksum"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StorageUsageResponse represents attachment storage usage of a user
+type StorageUsageResponse struct {
+	UsedBytes   int64 `json:"used_bytes"`
+	QuotaBytes  int64 `json:"quota_bytes"`
+	MaxFileSize int64 `json:"max_file_size"`
+}
@@ -13,12 +13,18 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// AuthResponse represents authentication response
+// AuthResponse represents authentication response. When sign-in needs a second
+// factor, only the MFA fields are set and the MFA token continues the sign-in.
 type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int    `json:"expires_in"`
+
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"` // The user must set up an authenticator first
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"` // Shown once after setting up an authenticator at sign-in
 }
 
 // RefreshTokenRequest represents refresh token request
@@ -26,6 +32,19 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// UpgradeGuestRequest represents the registration of the current guest account
+type UpgradeGuestRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"required,min=3,max=100"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// LogoutRequest represents logout request. Without a refresh token only the
+// client-side session ends.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // GuestSessionResponse represents guest session response
 type GuestSessionResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -34,3 +53,82 @@ type GuestSessionResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 	GuestID      string `json:"guest_id"`
 }
+
+// ChangePasswordRequest represents a password change of the current user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// ForgotPasswordRequest represents a request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents a password reset with an emailed token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// VerifyEmailRequest represents an email verification with an emailed token
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents a request for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// OIDCProviderResponse represents a single sign-on provider shown on the login page
+type OIDCProviderResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// OIDCExchangeRequest represents the exchange of a single sign-on login code for tokens
+type OIDCExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAVerifyRequest represents the second sign-in step with a TOTP or recovery code
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAEnrollRequest represents setting up a required authenticator during sign-in
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFAEnrollConfirmRequest represents confirming an authenticator set up during sign-in
+type MFAEnrollConfirmRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFACodeRequest represents an action confirmed with a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAStatusResponse represents the two-factor authentication status of the current user
+type MFAStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TOTPEnrollmentResponse represents a new authenticator secret. The provisioning
+// URI is shown as a QR code; the secret is for manual entry.
+type TOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -4,13 +4,34 @@ import "time"
 
 // ChatSessionResponse represents chat session response
 type ChatSessionResponse struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	ModelUsed    string    `json:"model_used"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	IsArchived   bool      `json:"is_archived"`
-	MessageCount int       `json:"message_count,omitempty"`
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	ModelUsed    string        `json:"model_used"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	IsArchived   bool          `json:"is_archived"`
+	IsPinned     bool          `json:"is_pinned"`
+	MessageCount int           `json:"message_count,omitempty"`
+	FolderID     *string       `json:"folder_id,omitempty"`
+	Tags         []TagResponse `json:"tags,omitempty"`
+
+	// Message statistics (chat lists and single chats)
+	TotalTokens        int        `json:"total_tokens,omitempty"`
+	LastMessagePreview string     `json:"last_message_preview,omitempty"`
+	LastMessageRole    string     `json:"last_message_role,omitempty"`
+	LastActivityAt     *time.Time `json:"last_activity_at,omitempty"` // Time of the last message, or the last update of the chat
+}
+
+// ChatListResponse represents a page of chat sessions
+type ChatListResponse struct {
+	Chats      []ChatSessionResponse `json:"chats"`
+	NextCursor string                `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// ChatMatchResponse represents a chat session found by semantic similarity
+type ChatMatchResponse struct {
+	ChatSessionResponse
+	Score float64 `json:"score"` // Cosine similarity
 }
 
 // CreateChatSessionRequest represents create chat session request
@@ -33,4 +54,4 @@ type ChatSessionWithMessagesResponse struct {
 // BulkOperationRequest represents bulk operation request
 type BulkOperationRequest struct {
 	IDs []string `json:"ids" binding:"required,min=1"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// DocumentResponse represents document response
+type DocumentResponse struct {
+	ID            string    `json:"id"`
+	ChatSessionID string    `json:"chat_session_id,omitempty"`
+	Filename      string    `json:"filename"`
+	MimeType      string    `json:"mime_type"`
+	Size          int64     `json:"size"`
+	ChunkCount    int       `json:"chunk_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DocumentChunkResponse represents document chunk response
+type DocumentChunkResponse struct {
+	ID         string `json:"id"`
+	DocumentID string `json:"document_id"`
+	ChunkIndex int    `json:"chunk_index"`
+	Content    string `json:"content"`
+}
+
+// CitationResponse represents a document chunk used as context for an assistant response
+type CitationResponse struct {
+	Index      int     `json:"index"` // Number used to cite the chunk in the prompt, e.g. [1]
+	ChunkID    string  `json:"chunk_id"`
+	DocumentID string  `json:"document_id"`
+	Filename   string  `json:"filename"`
+	ChunkIndex int     `json:"chunk_index"`
+	Score      float64 `json:"score"`
+}
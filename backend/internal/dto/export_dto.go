@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// ChatExportFormat identifies the JSON chat export schema
+const ChatExportFormat = "llmchatbot.chat.v1"
+
+// ChatExport represents an exported chat session (JSON export format)
+type ChatExport struct {
+	Format     string              `json:"format"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Title      string              `json:"title"`
+	ModelUsed  string              `json:"model_used"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	IsArchived bool                `json:"is_archived"`
+	Messages   []ChatExportMessage `json:"messages"`
+}
+
+// ChatExportMessage represents an exported message
+type ChatExportMessage struct {
+	SequenceNumber int                    `json:"sequence_number"`
+	Role           string                 `json:"role"`
+	Content        string                 `json:"content"`
+	Tokens         int                    `json:"tokens"`
+	IsIncomplete   bool                   `json:"is_incomplete"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Attachments    []ChatExportAttachment `json:"attachments,omitempty"`
+}
+
+// ChatExportAttachment represents attachment metadata in an export (content is not included)
+type ChatExportAttachment struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// ImportResultResponse represents the outcome of importing one conversation
+type ImportResultResponse struct {
+	Index        int    `json:"index"`  // Position of the conversation in the import file
+	Source       string `json:"source"` // "chatgpt" or "llmchatbot"
+	Title        string `json:"title"`
+	ChatID       string `json:"chat_id,omitempty"`
+	MessageCount int    `json:"message_count"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ImportResponse represents the result of a chat import
+type ImportResponse struct {
+	Imported int                    `json:"imported"`
+	Failed   int                    `json:"failed"`
+	Results  []ImportResultResponse `json:"results"`
+}
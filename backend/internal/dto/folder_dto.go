@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// FolderResponse represents folder response
+type FolderResponse struct {
+	ID        string    `json:"id"`
+	ParentID  *string   `json:"parent_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateFolderRequest represents create folder request
+type CreateFolderRequest struct {
+	Name     string  `json:"name" binding:"required,max=255"`
+	ParentID *string `json:"parent_id"` // Nil for a top-level folder
+}
+
+// UpdateFolderRequest represents update folder request (rename and move)
+type UpdateFolderRequest struct {
+	Name     string  `json:"name" binding:"required,max=255"`
+	ParentID *string `json:"parent_id"` // Nil moves the folder to the top level
+}
+
+// MoveChatsRequest represents a request to move multiple chat sessions
+type MoveChatsRequest struct {
+	BulkOperationRequest
+	FolderID *string `json:"folder_id"` // Nil moves the chats out of folders
+}
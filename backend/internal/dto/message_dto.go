@@ -9,8 +9,18 @@ type MessageResponse struct {
 	Content        string    `json:"content"`
 	Tokens         int       `json:"tokens"`
 	IsIncomplete   bool      `json:"is_incomplete,omitempty"`
+	IsPinned       bool      `json:"is_pinned,omitempty"`
+	IsExcluded     bool      `json:"is_excluded,omitempty"` // Not sent to the model
 	CreatedAt      time.Time `json:"created_at"`
 	SequenceNumber int       `json:"sequence_number"`
+
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
+}
+
+// MessageListResponse represents a page of messages
+type MessageListResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 // SendMessageRequest represents send message request
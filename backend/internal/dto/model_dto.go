@@ -0,0 +1,7 @@
+package dto
+
+// ModelResponse represents a model available for chats
+type ModelResponse struct {
+	Name   string `json:"name"`
+	Vision bool   `json:"vision"` // Model accepts image attachments
+}
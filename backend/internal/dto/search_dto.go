@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// SearchResultResponse represents a search match in a chat title or message
+type SearchResultResponse struct {
+	Type       string    `json:"type"` // "message" or "chat"
+	ChatID     string    `json:"chat_id"`
+	ChatTitle  string    `json:"chat_title"`
+	ModelUsed  string    `json:"model_used"`
+	IsArchived bool      `json:"is_archived"`
+	CreatedAt  time.Time `json:"created_at"`
+	Rank       float64   `json:"rank"`
+	Snippet    string    `json:"snippet"` // HTML-escaped; matched terms are wrapped in <mark></mark>
+
+	// Set for message matches; SequenceNumber locates the message in the chat
+	MessageID      string `json:"message_id,omitempty"`
+	Role           string `json:"role,omitempty"`
+	SequenceNumber *int   `json:"sequence_number,omitempty"`
+}
+
+// SearchResponse represents a page of search results
+type SearchResponse struct {
+	Query   string                 `json:"query"`
+	Results []SearchResultResponse `json:"results"`
+	Total   int64                  `json:"total"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+}
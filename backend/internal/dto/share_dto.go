@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// CreateChatShareRequest represents create share link request
+type CreateChatShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`                                // Optional expiry time
+	Password  string     `json:"password" binding:"omitempty,min=4,max=72"` // Optional password
+}
+
+// ChatShareResponse represents a share link owned by the current user
+type ChatShareResponse struct {
+	ID           string     `json:"id"`
+	ChatID       string     `json:"chat_id"`
+	Token        string     `json:"token"`
+	URL          string     `json:"url"` // Path of the public endpoint
+	Title        string     `json:"title"`
+	MessageCount int        `json:"message_count"`
+	HasPassword  bool       `json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ViewCount    int        `json:"view_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// SharedChatResponse represents the public snapshot of a shared chat
+type SharedChatResponse struct {
+	Title     string                  `json:"title"`
+	ModelUsed string                  `json:"model_used"`
+	SharedAt  time.Time               `json:"shared_at"`
+	ExpiresAt *time.Time              `json:"expires_at,omitempty"`
+	Messages  []SharedMessageResponse `json:"messages"`
+}
+
+// SharedMessageResponse represents a message of a shared chat
+type SharedMessageResponse struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// TableQueryResponse represents a query the model ran over a CSV/TSV attachment
+type TableQueryResponse struct {
+	Table  string `json:"table"`
+	Query  string `json:"query"`
+	Result string `json:"result,omitempty"` // Markdown table
+	Error  string `json:"error,omitempty"`
+}
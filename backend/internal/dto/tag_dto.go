@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// TagResponse represents tag response
+type TagResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTagRequest represents create tag request
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor,len=7"`
+}
+
+// UpdateTagRequest represents update tag request
+type UpdateTagRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"required,hexcolor,len=7"`
+}
+
+// BulkTagRequest represents a request to add or remove tags on multiple chat sessions
+type BulkTagRequest struct {
+	BulkOperationRequest
+	TagIDs []string `json:"tag_ids" binding:"required,min=1"`
+}
@@ -4,12 +4,56 @@ import "time"
 
 // UserResponse represents user response
 type UserResponse struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	IsActive      bool       `json:"is_active"`
+	EmailVerified bool       `json:"email_verified"`
+	Role          string     `json:"role"`
+}
+
+// SessionResponse represents a login session of the current user
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+// UserIdentityResponse represents a single sign-on identity linked to the current user
+type UserIdentityResponse struct {
 	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
 	Email       string     `json:"email"`
-	Username    string     `json:"username"`
 	CreatedAt   time.Time  `json:"created_at"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
-	IsActive    bool       `json:"is_active"`
+}
+
+// CreateAPIKeyRequest represents a request to create a personal API key.
+// Without an expiry the key is valid until revoked.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse represents a personal API key of the current user
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	IsExpired  bool       `json:"is_expired"`
+	Key        string     `json:"key,omitempty"` // Full key, returned only when the key is created
 }
 
 // UpdateUserRequest represents update user request
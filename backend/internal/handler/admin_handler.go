@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+// AdminHandler handles user and role administration endpoints
+type AdminHandler struct {
+	adminService *service.AdminService
+	roleService  *service.RoleService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *service.AdminService, roleService *service.RoleService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		roleService:  roleService,
+	}
+}
+
+// ListUsers lists users, optionally searching email and username
+// and filtering by role, active and guest status
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	filter := repository.UserFilter{
+		Query: c.Query("q"),
+		Role:  c.Query("role"),
+	}
+
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid active value"})
+			return
+		}
+		filter.Active = &active
+	}
+	if guestStr := c.Query("guest"); guestStr != "" {
+		guest, err := strconv.ParseBool(guestStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid guest value"})
+			return
+		}
+		filter.IsGuest = &guest
+	}
+
+	limit, err := parseLimit(c, 50, 200)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	filter.Limit = limit
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if filter.Offset, err = strconv.Atoi(offsetStr); err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+	}
+
+	users, err := h.adminService.ListUsers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser retrieves a user
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeactivateUser blocks a user from signing in and ends their sessions
+func (h *AdminHandler) DeactivateUser(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.DeactivateUser(adminID, userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ReactivateUser allows a deactivated user to sign in again
+func (h *AdminHandler) ReactivateUser(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ReactivateUser(adminID, userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ForceLogout ends all sessions of a user
+func (h *AdminHandler) ForceLogout(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForceLogout(adminID, userID); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User signed out of all sessions"})
+}
+
+// SetUserRole assigns a role to a user
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.adminService.SetUserRole(adminID, userID, req.Role)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetGuestExpiration gives a guest account a full lifetime again
+func (h *AdminHandler) ResetGuestExpiration(c *gin.Context) {
+	userID, ok := parseUserParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ResetGuestExpiration(userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetRoles lists the built-in and custom roles
+func (h *AdminHandler) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateRole creates a custom role
+func (h *AdminHandler) CreateRole(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(adminID, &req)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole changes a custom role
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(adminID, c.Param("name"), &req)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole deletes a custom role that is not assigned to any user
+func (h *AdminHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c.Param("name")); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// parseUserParam parses the user ID of the URL
+func parseUserParam(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// respondAdminError responds with the status of an administration error
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSelfAdministration), errors.Is(err, service.ErrInsufficientPrivileges):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUploadAttachmentRejectsLargeFiles(t *testing.T) {
+	// The service is never reached for rejected uploads
+	h := NewAttachmentHandler(nil, 1024)
+	path := "/chats/" + uuid.New().String() + "/attachments"
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"file over the limit", 2048},
+		{"body over the limit", multipartOverhead + 2048},
+	}
+	for _, tt := range tests {
+		body, contentType := multipartUpload(t, "file", "photo.png", bytes.Repeat([]byte{0x89}, tt.size))
+		recorder := serveUpload(h.UploadAttachment, "/chats/:id/attachments", path, body, contentType)
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, http.StatusRequestEntityTooLarge)
+		}
+	}
+}
@@ -1,28 +1,32 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/dto"
 	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/repository"
 	"github.com/llmchatbot/backend/internal/service"
 )
 
 // ChatHandler handles chat session endpoints
 type ChatHandler struct {
-	chatService *service.ChatService
+	chatService  *service.ChatService
+	guestService *service.GuestService
 }
 
 // NewChatHandler creates a new chat handler
-func NewChatHandler(chatService *service.ChatService) *ChatHandler {
+func NewChatHandler(chatService *service.ChatService, guestService *service.GuestService) *ChatHandler {
 	return &ChatHandler{
-		chatService: chatService,
+		chatService:  chatService,
+		guestService: guestService,
 	}
 }
 
-// GetChatSessions retrieves all chat sessions for current user
+// GetChatSessions retrieves chat sessions for current user, optionally filtered by folder and tags
 func (h *ChatHandler) GetChatSessions(c *gin.Context) {
 	userIDStr, exists := middleware.GetUserID(c)
 	if !exists {
@@ -36,9 +40,73 @@ func (h *ChatHandler) GetChatSessions(c *gin.Context) {
 		return
 	}
 
-	includeArchived := c.Query("include_archived") == "true"
+	filter := repository.ChatListFilter{
+		IncludeArchived:   c.Query("include_archived") == "true",
+		IncludeSubfolders: c.Query("include_subfolders") == "true",
+	}
+
+	// folder_id=none lists chats outside of folders
+	if folderIDStr := c.Query("folder_id"); folderIDStr == "none" {
+		filter.Unfiled = true
+	} else if folderIDStr != "" {
+		folderID, err := uuid.Parse(folderIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+			return
+		}
+		filter.FolderID = &folderID
+	}
+
+	for _, tagIDStr := range c.QueryArray("tag_id") {
+		tagID, err := uuid.Parse(tagIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID: " + tagIDStr})
+			return
+		}
+		filter.TagIDs = append(filter.TagIDs, tagID)
+	}
+
+	filter.Model = c.Query("model")
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := parseSearchTime(fromStr, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		filter.From = &from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := parseSearchTime(toStr, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		filter.To = &to
+	}
+
+	// Paginated response when limit or cursor is given; a plain list otherwise
+	if c.Query("limit") != "" || c.Query("cursor") != "" {
+		limit, err := parseLimit(c, 50, 200)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, err := h.chatService.ListChatSessions(userID, filter, c.Query("cursor"), limit)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrInvalidCursor) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+		return
+	}
 
-	sessions, err := h.chatService.GetChatSessions(userID, includeArchived)
+	sessions, err := h.chatService.GetChatSessions(userID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -67,6 +135,13 @@ func (h *ChatHandler) CreateChatSession(c *gin.Context) {
 		return
 	}
 
+	if middleware.IsGuest(c) {
+		if err := h.guestService.CheckCreateChat(userID, req.ModelUsed); err != nil {
+			respondGuestLimit(c, err)
+			return
+		}
+	}
+
 	session, err := h.chatService.CreateChatSession(userID, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -117,6 +192,213 @@ func (h *ChatHandler) GetChatSession(c *gin.Context) {
 	}
 }
 
+// GetMessages retrieves a page of messages of a chat session
+func (h *ChatHandler) GetMessages(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	limit, err := parseLimit(c, 50, 200)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order := c.DefaultQuery("order", "asc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+
+	page, err := h.chatService.GetMessages(sessionID, userID, c.Query("cursor"), limit, order == "desc")
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, service.ErrInvalidCursor) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetPinnedMessages retrieves all pinned messages of a chat session
+func (h *ChatHandler) GetPinnedMessages(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	messages, err := h.chatService.GetPinnedMessages(sessionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// PinChatSession pins a chat session to the top of the list
+func (h *ChatHandler) PinChatSession(c *gin.Context) {
+	h.setChatPinned(c, true)
+}
+
+// UnpinChatSession unpins a chat session
+func (h *ChatHandler) UnpinChatSession(c *gin.Context) {
+	h.setChatPinned(c, false)
+}
+
+// PinMessage pins a message so it is always included in the model context
+func (h *ChatHandler) PinMessage(c *gin.Context) {
+	h.setMessagePinned(c, true)
+}
+
+// UnpinMessage unpins a message
+func (h *ChatHandler) UnpinMessage(c *gin.Context) {
+	h.setMessagePinned(c, false)
+}
+
+// ExcludeMessage excludes a message from the model context
+func (h *ChatHandler) ExcludeMessage(c *gin.Context) {
+	h.setMessageExcluded(c, true)
+}
+
+// IncludeMessage includes an excluded message in the model context again
+func (h *ChatHandler) IncludeMessage(c *gin.Context) {
+	h.setMessageExcluded(c, false)
+}
+
+// DeleteMessage permanently deletes a message of a chat session
+func (h *ChatHandler) DeleteMessage(c *gin.Context) {
+	userID, sessionID, messageID, ok := parseMessagePath(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.DeleteMessage(sessionID, messageID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
+}
+
+// setChatPinned pins or unpins the chat session given in the path
+func (h *ChatHandler) setChatPinned(c *gin.Context, pinned bool) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.chatService.PinChatSession(sessionID, userID, pinned); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"is_pinned": pinned})
+}
+
+// setMessagePinned pins or unpins the message given in the path
+func (h *ChatHandler) setMessagePinned(c *gin.Context, pinned bool) {
+	userID, sessionID, messageID, ok := parseMessagePath(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.PinMessage(sessionID, messageID, userID, pinned); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"is_pinned": pinned})
+}
+
+// setMessageExcluded excludes or includes the message given in the path
+func (h *ChatHandler) setMessageExcluded(c *gin.Context, excluded bool) {
+	userID, sessionID, messageID, ok := parseMessagePath(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chatService.ExcludeMessage(sessionID, messageID, userID, excluded); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"is_excluded": excluded})
+}
+
+// parseMessagePath reads the current user and the chat session and message IDs of
+// a message route; on failure the error response is already written
+func parseMessagePath(c *gin.Context) (userID, sessionID, messageID uuid.UUID, ok bool) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	sessionID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	messageID, err = uuid.Parse(c.Param("message_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return
+	}
+
+	return userID, sessionID, messageID, true
+}
+
 // UpdateChatSession updates a chat session
 func (h *ChatHandler) UpdateChatSession(c *gin.Context) {
 	userIDStr, exists := middleware.GetUserID(c)
@@ -315,4 +597,13 @@ func (h *ChatHandler) DeleteChatSessions(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Chat sessions deleted successfully"})
-}
\ No newline at end of file
+}
+
+// respondGuestLimit writes the response for a failed guest limit check
+func respondGuestLimit(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrGuestLimitReached) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+func TestGetChatSessionsRejectsInvalidCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// The cursor is decoded before the service uses its repositories
+	h := NewChatHandler(&service.ChatService{}, nil)
+	router := gin.New()
+	router.GET("/chats", func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uuid.New().String())
+		h.GetChatSessions(c)
+	})
+
+	for _, query := range []string{"?cursor=not-a-cursor", "?limit=0"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/chats"+query, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("GET /chats%s status = %d, want %d", query, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
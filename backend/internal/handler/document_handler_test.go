@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/middleware"
+)
+
+// multipartUpload builds a multipart request body with a single file field
+func multipartUpload(t *testing.T, field, filename string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, writer.FormDataContentType()
+}
+
+// serveUpload runs an upload handler registered at route for an authenticated user
+func serveUpload(handler gin.HandlerFunc, route, path string, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST(route, func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uuid.New().String())
+		handler(c)
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, body)
+	request.Header.Set("Content-Type", contentType)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestUploadDocumentRejectsLargeFiles(t *testing.T) {
+	// The service is never reached for rejected uploads
+	h := NewDocumentHandler(nil, 1024)
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"file over the limit", 2048},
+		{"body over the limit", multipartOverhead + 2048},
+	}
+	for _, tt := range tests {
+		body, contentType := multipartUpload(t, "file", "notes.txt", bytes.Repeat([]byte("a"), tt.size))
+		recorder := serveUpload(h.UploadDocument, "/documents", "/documents", body, contentType)
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, http.StatusRequestEntityTooLarge)
+		}
+	}
+}
+
+func TestUploadDocumentRequiresFile(t *testing.T) {
+	h := NewDocumentHandler(nil, 1024)
+
+	body, contentType := multipartUpload(t, "other", "notes.txt", []byte("text"))
+	recorder := serveUpload(h.UploadDocument, "/documents", "/documents", body, contentType)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+// ExportHandler handles chat export endpoints
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportChat downloads a chat session as Markdown, JSON or HTML
+func (h *ExportHandler) ExportChat(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", service.ExportFormatMarkdown)
+	if err := service.ValidateExportFormat(format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.exportService.ExportChat(sessionID, userID, format)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", contentDisposition(file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// ExportChats downloads all chat sessions of the current user as a zip archive
+func (h *ExportHandler) ExportChats(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", service.ExportFormatMarkdown)
+	if err := service.ValidateExportFormat(format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	includeArchived := c.Query("include_archived") == "true"
+
+	filename := fmt.Sprintf("chats-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Disposition", contentDisposition(filename))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	// The archive is streamed, so errors after the first write cannot change the response status
+	if err := h.exportService.ExportAll(userID, format, includeArchived, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to export chats: %v", err)
+	}
+}
+
+// contentDisposition builds an attachment Content-Disposition header with a UTF-8 file name
+func contentDisposition(filename string) string {
+	return fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", asciiFilename(filename), url.PathEscape(filename))
+}
+
+// asciiFilename replaces non-ASCII and quoting characters for the legacy filename parameter
+func asciiFilename(filename string) string {
+	runes := []rune(filename)
+	for i, r := range runes {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			runes[i] = '_'
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+// ModelHandler handles model registry endpoints
+type ModelHandler struct {
+	modelRegistry *service.ModelRegistry
+}
+
+// NewModelHandler creates a new model handler
+func NewModelHandler(modelRegistry *service.ModelRegistry) *ModelHandler {
+	return &ModelHandler{
+		modelRegistry: modelRegistry,
+	}
+}
+
+// GetModels lists models available for chats and their capabilities
+func (h *ModelHandler) GetModels(c *gin.Context) {
+	c.JSON(http.StatusOK, h.modelRegistry.List())
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+// oidcStateCookie binds a sign-in to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// oidcCookiePath limits the state cookie to the sign-on endpoints
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+// OIDCHandler handles single sign-on endpoints
+type OIDCHandler struct {
+	oidcService   *service.OIDCService
+	appURL        string // Frontend URL the callback redirects to
+	secureCookies bool
+	cookieMaxAge  int
+}
+
+// NewOIDCHandler creates a new single sign-on handler
+func NewOIDCHandler(oidcService *service.OIDCService, appURL string, secureCookies bool, cookieMaxAge int) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:   oidcService,
+		appURL:        appURL,
+		secureCookies: secureCookies,
+		cookieMaxAge:  cookieMaxAge,
+	}
+}
+
+// GetProviders lists the configured sign-on providers
+func (h *OIDCHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.GetProviders())
+}
+
+// Login redirects the browser to the provider's consent page
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context(), c.Param("provider"))
+	if errors.Is(err, service.ErrUnknownProvider) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Lax lets the cookie through on the provider's top-level redirect back
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, h.cookieMaxAge, oidcCookiePath, "", h.secureCookies, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes a sign-in when the provider redirects back and sends the
+// browser to the frontend with a login code, or with an error
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", h.secureCookies, true)
+
+	if providerError := c.Query("error"); providerError != "" {
+		h.redirectError(c, "Sign-in was cancelled or denied by the provider")
+		return
+	}
+	if state == "" || state != cookieState {
+		h.redirectError(c, "Sign-in was started in another browser or has expired")
+		return
+	}
+
+	code, err := h.oidcService.CompleteLogin(c.Request.Context(), c.Param("provider"), state, c.Query("code"), clientInfo(c))
+	if err != nil {
+		h.redirectError(c, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusFound, h.appURL+"/oidc/callback?code="+url.QueryEscape(code))
+}
+
+// Exchange exchanges a login code from the callback for tokens
+func (h *OIDCHandler) Exchange(c *gin.Context) {
+	var req dto.OIDCExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.oidcService.ExchangeLoginCode(req.Code, clientInfo(c))
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "email_verification_required": true})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetIdentities lists the sign-on identities linked to the current user
+func (h *OIDCHandler) GetIdentities(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	identities, err := h.oidcService.GetIdentities(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// UnlinkIdentity removes a sign-on identity from the current user
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	identityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid identity ID"})
+		return
+	}
+
+	if err := h.oidcService.UnlinkIdentity(userID, identityID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identity unlinked successfully"})
+}
+
+// redirectError sends the browser to the frontend login page with an error message
+func (h *OIDCHandler) redirectError(c *gin.Context, message string) {
+	c.Redirect(http.StatusFound, h.appURL+"/login?sso_error="+url.QueryEscape(message))
+}
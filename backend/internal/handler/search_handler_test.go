@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSearchTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"2024-03-10", false, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		// A date as the exclusive upper bound includes the whole day
+		{"2024-03-10", true, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-10T15:04:05Z", true, time.Date(2024, 3, 10, 15, 4, 5, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSearchTime(tt.value, tt.endOfDay)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSearchTime(%q, %v) = %v, %v; want %v", tt.value, tt.endOfDay, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseSearchTime("10.03.2024", false); err == nil {
+		t.Error("parseSearchTime() accepted an unsupported format")
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/middleware"
+	"github.com/llmchatbot/backend/internal/service"
+)
+
+// SessionHandler handles login session management endpoints
+type SessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(authService *service.AuthService) *SessionHandler {
+	return &SessionHandler{
+		authService: authService,
+	}
+}
+
+// GetSessions lists the active login sessions of the current user
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	userID, currentSessionID, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs the current user out of a login session
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions signs the current user out of all login sessions except the current one
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, currentSessionID, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(userID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked successfully", "revoked": revoked})
+}
+
+// currentSession extracts the user and login session IDs of the request.
+// Tokens issued before login sessions existed have no session ID (uuid.Nil).
+// It writes the error response and returns false if the user is not authenticated.
+func currentSession(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDStr, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	var sessionID uuid.UUID
+	if sessionIDStr, exists := middleware.GetSessionID(c); exists {
+		sessionID, _ = uuid.Parse(sessionIDStr)
+	}
+	return userID, sessionID, true
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,9 +16,14 @@ import (
 
 // StreamingHandler handles streaming endpoints
 type StreamingHandler struct {
-	streamingService *service.StreamingService
-	messageService   *service.MessageService
-	chatService      *service.ChatService
+	streamingService     *service.StreamingService
+	messageService       *service.MessageService
+	chatService          *service.ChatService
+	documentService      *service.DocumentService
+	attachmentService    *service.AttachmentService
+	tableService         *service.TableService
+	chatEmbeddingService *service.ChatEmbeddingService
+	guestService         *service.GuestService
 }
 
 // NewStreamingHandler creates a new streaming handler
@@ -25,11 +31,21 @@ func NewStreamingHandler(
 	streamingService *service.StreamingService,
 	messageService *service.MessageService,
 	chatService *service.ChatService,
+	documentService *service.DocumentService,
+	attachmentService *service.AttachmentService,
+	tableService *service.TableService,
+	chatEmbeddingService *service.ChatEmbeddingService,
+	guestService *service.GuestService,
 ) *StreamingHandler {
 	return &StreamingHandler{
-		streamingService: streamingService,
-		messageService:   messageService,
-		chatService:      chatService,
+		streamingService:     streamingService,
+		messageService:       messageService,
+		chatService:          chatService,
+		documentService:      documentService,
+		attachmentService:    attachmentService,
+		tableService:         tableService,
+		chatEmbeddingService: chatEmbeddingService,
+		guestService:         guestService,
 	}
 }
 
@@ -61,6 +77,19 @@ func (h *StreamingHandler) StreamChat(c *gin.Context) {
 		return
 	}
 
+	// Get uploaded attachments to send with the message (comma-separated IDs)
+	var attachmentIDs []uuid.UUID
+	if idsStr := c.Query("attachment_ids"); idsStr != "" {
+		for _, idStr := range strings.Split(idsStr, ",") {
+			attachmentID, err := uuid.Parse(strings.TrimSpace(idStr))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID: " + idStr})
+				return
+			}
+			attachmentIDs = append(attachmentIDs, attachmentID)
+		}
+	}
+
 	// Verify chat session belongs to user
 	session, err := h.chatService.GetChatSession(sessionID, userID)
 	if err != nil {
@@ -68,8 +97,23 @@ func (h *StreamingHandler) StreamChat(c *gin.Context) {
 		return
 	}
 
+	if err := h.attachmentService.ValidatePending(userID, sessionID, session.ModelUsed, attachmentIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Apply guest account limits
+	maxTokens := 0
+	if middleware.IsGuest(c) {
+		if err := h.guestService.CheckSendMessage(userID, session.ModelUsed); err != nil {
+			respondGuestLimit(c, err)
+			return
+		}
+		maxTokens = h.guestService.MaxTokens()
+	}
+
 	// Save user message
-	_, err = h.messageService.CreateUserMessage(sessionID, userID, message)
+	_, err = h.messageService.CreateUserMessage(sessionID, userID, message, attachmentIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save message"})
 		return
@@ -81,6 +125,30 @@ func (h *StreamingHandler) StreamChat(c *gin.Context) {
 		history = []*dto.MessageResponse{} // Use empty history if error
 	}
 
+	// Include image attachments for vision-capable models
+	llmHistory, err := h.streamingService.BuildHistory(userID, history, session.ModelUsed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Add relevant document excerpts to the prompt (RAG)
+	prompt, citations, err := h.documentService.AugmentPrompt(userID, sessionID, message)
+	if err != nil {
+		// Log error but answer without document context
+		fmt.Printf("Warning: Could not retrieve document context: %v\n", err)
+		prompt = message
+	}
+
+	// Let the model query CSV/TSV attachments of the chat
+	tablePrompt, tableQueries, err := h.tableService.AnswerWithTables(userID, sessionID, message, prompt, llmHistory, session.ModelUsed)
+	if err != nil {
+		// Log error but answer without table results
+		fmt.Printf("Warning: Could not query attached tables: %v\n", err)
+	} else {
+		prompt = tablePrompt
+	}
+
 	// Set up SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -105,9 +173,10 @@ func (h *StreamingHandler) StreamChat(c *gin.Context) {
 	// Start streaming from LLM service
 	tokenChan, errChan := h.streamingService.StreamGeneration(
 		sessionID,
-		message,
-		history,
+		prompt,
+		llmHistory,
 		session.ModelUsed,
+		maxTokens,
 	)
 
 	var fullResponse string
@@ -162,15 +231,24 @@ func (h *StreamingHandler) StreamChat(c *gin.Context) {
 						fmt.Printf("Failed to save complete message: %v\n", err)
 					} else {
 						messageSaved = true
+						// Update the chat embedding for semantic search and related chats
+						h.chatEmbeddingService.IndexChatAsync(sessionID)
 					}
 				}
 
 				// Send completion event
-				c.SSEvent("message", map[string]interface{}{
+				event := map[string]interface{}{
 					"type":    "complete",
 					"content": finalContent,
 					"tokens":  finalTokens,
-				})
+				}
+				if len(citations) > 0 {
+					event["citations"] = citations
+				}
+				if len(tableQueries) > 0 {
+					event["table_queries"] = tableQueries
+				}
+				c.SSEvent("message", event)
 				return false
 			}
 
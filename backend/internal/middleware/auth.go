@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/llmchatbot/backend/internal/database"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/pkg/denylist"
 	"github.com/llmchatbot/backend/pkg/jwt"
 )
 
@@ -13,10 +17,24 @@ const (
 	UserIDKey = "user_id"
 	// UserEmailKey is the key for user email in context
 	UserEmailKey = "user_email"
+	// SessionIDKey is the key for the login session ID in context
+	SessionIDKey = "session_id"
+	// IsGuestKey is the key for the guest account flag in context
+	IsGuestKey = "is_guest"
+	// APIKeyScopesKey is the key for the scopes of the API key a request was authenticated with
+	APIKeyScopesKey = "api_key_scopes"
 )
 
-// JWTAuthMiddleware validates JWT token and sets user context
-func JWTAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
+// APIKeyAuthenticator resolves a personal API key to the key with its user,
+// returning an error for keys that must be rejected
+type APIKeyAuthenticator interface {
+	Authenticate(key string) (*model.APIKey, error)
+}
+
+// JWTAuthMiddleware validates JWT token, rejects revoked tokens and sets user context.
+// With apiKeys set, personal API keys are accepted in the Bearer header as well;
+// their requests are limited to the routes the scopes of the key allow.
+func JWTAuthMiddleware(jwtMgr *jwt.Manager, deny denylist.Store, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from header
 		authHeader := c.GetHeader("Authorization")
@@ -33,6 +51,11 @@ func JWTAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
 			return
 		}
 
+		if apiKeys != nil && strings.HasPrefix(tokenString, model.APIKeyPrefix) {
+			authenticateAPIKey(c, apiKeys, tokenString)
+			return
+		}
+
 		// Validate token
 		claims, err := jwtMgr.ValidateToken(tokenString)
 		if err != nil {
@@ -48,9 +71,24 @@ func JWTAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
 			return
 		}
 
+		// Check that the token was not revoked by logout or a password change
+		revoked, err := isRevoked(deny, claims)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not verify token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		// Set user context
 		c.Set(UserIDKey, claims.UserID.String())
 		c.Set(UserEmailKey, claims.Email)
+		c.Set(SessionIDKey, claims.SessionID.String())
+		c.Set(IsGuestKey, claims.IsGuest)
 
 		// Set RLS context for PostgreSQL
 		if database.DB != nil {
@@ -62,7 +100,7 @@ func JWTAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware validates JWT token if present but doesn't require it
-func OptionalAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
+func OptionalAuthMiddleware(jwtMgr *jwt.Manager, deny denylist.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -78,6 +116,10 @@ func OptionalAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
 
 		claims, err := jwtMgr.ValidateToken(tokenString)
 		if err == nil && claims.TokenType == "access" {
+			if revoked, err := isRevoked(deny, claims); err != nil || revoked {
+				c.Next()
+				return
+			}
 			c.Set(UserIDKey, claims.UserID.String())
 			c.Set(UserEmailKey, claims.Email)
 		}
@@ -86,6 +128,37 @@ func OptionalAuthMiddleware(jwtMgr *jwt.Manager) gin.HandlerFunc {
 	}
 }
 
+// authenticateAPIKey sets the user context of a request made with a personal API key
+func authenticateAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator, key string) {
+	apiKey, err := apiKeys.Authenticate(key)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+		c.Abort()
+		return
+	}
+
+	c.Set(UserIDKey, apiKey.UserID.String())
+	c.Set(UserEmailKey, apiKey.User.Email)
+	c.Set(IsGuestKey, apiKey.User.IsGuest)
+	c.Set(APIKeyScopesKey, apiKey.ScopeList())
+
+	// Set RLS context for PostgreSQL
+	if database.DB != nil {
+		_ = database.SetUserContext(database.DB, apiKey.UserID.String())
+	}
+
+	c.Next()
+}
+
+// isRevoked checks a validated access token against the denylist
+func isRevoked(deny denylist.Store, claims *jwt.Claims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return deny.IsRevoked(claims.ID, claims.SessionID.String(), claims.UserID.String(), issuedAt)
+}
+
 // GetUserID extracts user ID from context
 func GetUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get(UserIDKey)
@@ -103,3 +176,89 @@ func GetUserEmail(c *gin.Context) (string, bool) {
 	}
 	return email.(string), true
 }
+
+// GetSessionID extracts the login session ID from context
+func GetSessionID(c *gin.Context) (string, bool) {
+	sessionID, exists := c.Get(SessionIDKey)
+	if !exists {
+		return "", false
+	}
+	return sessionID.(string), true
+}
+
+// IsGuest reports whether the authenticated user is a guest account
+func IsGuest(c *gin.Context) bool {
+	return c.GetBool(IsGuestKey)
+}
+
+// GetAPIKeyScopes returns the scopes of the API key a request was authenticated with.
+// It reports false for requests authenticated with an access token.
+func GetAPIKeyScopes(c *gin.Context) ([]string, bool) {
+	scopes, exists := c.Get(APIKeyScopesKey)
+	if !exists {
+		return nil, false
+	}
+	return scopes.([]string), true
+}
+
+// RequireScope rejects API key requests without the given scope.
+// Access tokens of a login session are not limited by scopes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scopes, isAPIKey := GetAPIKeyScopes(c); isAPIKey && !hasScope(scopes, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireChatScope requires the chats:read scope of API keys for reading
+// requests and the chats:write scope for all others
+func RequireChatScope() gin.HandlerFunc {
+	read := RequireScope(model.APIKeyScopeChatsRead)
+	write := RequireScope(model.APIKeyScopeChatsWrite)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			read(c)
+		default:
+			write(c)
+		}
+	}
+}
+
+// DenyAPIKeys rejects requests authenticated with an API key, e.g. for account settings
+func DenyAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIKey := GetAPIKeyScopes(c); isAPIKey {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not available with an API key"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasScope reports whether scopes contain a scope
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyGuests rejects requests of guest accounts
+func DenyGuests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsGuest(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not available for guest accounts"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/pkg/denylist"
+	"github.com/llmchatbot/backend/pkg/jwt"
+)
+
+// fakeAPIKeys accepts the keys it holds
+type fakeAPIKeys struct {
+	keys map[string]*model.APIKey
+}
+
+func (f *fakeAPIKeys) Authenticate(key string) (*model.APIKey, error) {
+	if apiKey, ok := f.keys[key]; ok {
+		return apiKey, nil
+	}
+	return nil, errors.New("invalid API key")
+}
+
+// newScopedRouter mounts routes guarded like the chat, stream and account routes of the API
+func newScopedRouter(jwtMgr *jwt.Manager, apiKeys APIKeyAuthenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	router := gin.New()
+	protected := router.Group("/", JWTAuthMiddleware(jwtMgr, denylist.NewMemoryStore(), apiKeys))
+	chats := protected.Group("/chats", RequireChatScope())
+	chats.GET("", ok)
+	chats.HEAD("", ok)
+	chats.POST("", ok)
+	chats.DELETE("/:id", ok)
+	protected.POST("/stream", RequireScope(model.APIKeyScopeStream), ok)
+	protected.GET("/users/me", DenyAPIKeys(), ok)
+	return router
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	jwtMgr := jwt.NewManager("test-secret", time.Minute, time.Hour)
+	apiKeys := &fakeAPIKeys{keys: map[string]*model.APIKey{}}
+	newKey := func(scopes string) string {
+		key := model.APIKeyPrefix + uuid.NewString()
+		apiKeys.keys[key] = &model.APIKey{UserID: uuid.New(), Scopes: scopes}
+		return key
+	}
+	router := newScopedRouter(jwtMgr, apiKeys)
+
+	accessToken, err := jwtMgr.GenerateAccessToken(uuid.New(), uuid.New(), "alice@example.com", false)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	readKey := newKey(model.APIKeyScopeChatsRead)
+	writeKey := newKey(model.APIKeyScopeChatsWrite)
+	streamKey := newKey(model.APIKeyScopeStream)
+	allKey := newKey(model.APIKeyScopeChatsRead + "," + model.APIKeyScopeChatsWrite + "," + model.APIKeyScopeStream)
+	noScopeKey := newKey("")
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		want   int
+	}{
+		// Access tokens of a login session are not limited by scopes
+		{"session lists chats", accessToken, http.MethodGet, "/chats", http.StatusOK},
+		{"session creates chat", accessToken, http.MethodPost, "/chats", http.StatusOK},
+		{"session streams", accessToken, http.MethodPost, "/stream", http.StatusOK},
+		{"session reads account", accessToken, http.MethodGet, "/users/me", http.StatusOK},
+
+		{"read key lists chats", readKey, http.MethodGet, "/chats", http.StatusOK},
+		{"read key heads chats", readKey, http.MethodHead, "/chats", http.StatusOK},
+		{"read key creates chat", readKey, http.MethodPost, "/chats", http.StatusForbidden},
+		{"read key deletes chat", readKey, http.MethodDelete, "/chats/1", http.StatusForbidden},
+		{"read key streams", readKey, http.MethodPost, "/stream", http.StatusForbidden},
+
+		// chats:write does not imply chats:read
+		{"write key lists chats", writeKey, http.MethodGet, "/chats", http.StatusForbidden},
+		{"write key creates chat", writeKey, http.MethodPost, "/chats", http.StatusOK},
+		{"write key deletes chat", writeKey, http.MethodDelete, "/chats/1", http.StatusOK},
+
+		{"stream key streams", streamKey, http.MethodPost, "/stream", http.StatusOK},
+		{"stream key lists chats", streamKey, http.MethodGet, "/chats", http.StatusForbidden},
+
+		{"full key lists chats", allKey, http.MethodGet, "/chats", http.StatusOK},
+		{"full key creates chat", allKey, http.MethodPost, "/chats", http.StatusOK},
+		{"full key streams", allKey, http.MethodPost, "/stream", http.StatusOK},
+		// Account settings are never available with an API key, whatever its scopes
+		{"full key reads account", allKey, http.MethodGet, "/users/me", http.StatusForbidden},
+
+		{"key without scopes lists chats", noScopeKey, http.MethodGet, "/chats", http.StatusForbidden},
+		{"unknown key", model.APIKeyPrefix + "unknown", http.MethodGet, "/chats", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != tt.want {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeysRejectedWithoutAuthenticator(t *testing.T) {
+	jwtMgr := jwt.NewManager("test-secret", time.Minute, time.Hour)
+	router := newScopedRouter(jwtMgr, nil)
+
+	// Without an authenticator a key is validated as an access token and rejected
+	req := httptest.NewRequest(http.MethodGet, "/chats", nil)
+	req.Header.Set("Authorization", "Bearer "+model.APIKeyPrefix+"key")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAPIKeySetsUserContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	apiKeys := &fakeAPIKeys{keys: map[string]*model.APIKey{
+		"llmc_key": {UserID: userID, Scopes: model.APIKeyScopeChatsRead, User: model.User{Email: "alice@example.com", IsGuest: true}},
+	}}
+
+	router := gin.New()
+	router.GET("/me", JWTAuthMiddleware(jwt.NewManager("test-secret", time.Minute, time.Hour), denylist.NewMemoryStore(), apiKeys), func(c *gin.Context) {
+		id, _ := GetUserID(c)
+		email, _ := GetUserEmail(c)
+		scopes, isAPIKey := GetAPIKeyScopes(c)
+		if id != userID.String() || email != "alice@example.com" || !IsGuest(c) {
+			t.Errorf("user context = %s, %s, guest %v", id, email, IsGuest(c))
+		}
+		if !isAPIKey || len(scopes) != 1 || scopes[0] != model.APIKeyScopeChatsRead {
+			t.Errorf("GetAPIKeyScopes() = %v, %v", scopes, isAPIKey)
+		}
+		// API keys have no login session
+		if _, ok := GetSessionID(c); ok {
+			t.Error("session ID set for an API key")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer llmc_key")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PermissionChecker reports whether the role of a user grants a permission
+type PermissionChecker interface {
+	HasPermission(userID uuid.UUID, permission string) (bool, error)
+}
+
+// RequirePermission rejects requests of users whose role lacks a permission.
+// The role is looked up on every request, so role changes apply at once.
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDStr, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(userID, permission)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check permissions"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every personal API key, telling keys apart from JWTs
+const APIKeyPrefix = "llmc_"
+
+// API key scopes
+const (
+	APIKeyScopeChatsRead  = "chats:read"
+	APIKeyScopeChatsWrite = "chats:write"
+	APIKeyScopeStream     = "stream"
+)
+
+// APIKey is a personal API key for programmatic access. Only the SHA-256 hash
+// of the key is stored; the prefix identifies the key in listings.
+type APIKey struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	Name       string    `gorm:"size:100;not null"`
+	Prefix     string    `gorm:"size:20;not null"` // First characters of the key, e.g. "llmc_1a2b3c4d"
+	KeyHash    string    `gorm:"size:64;uniqueIndex;not null"`
+	Scopes     string    `gorm:"size:255;not null"` // Comma-separated scopes
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for APIKey
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the scopes of the key
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsExpired reports whether the key is past its expiry
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && k.ExpiresAt.Before(time.Now())
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment represents a file uploaded to a chat session and attached to a message.
+// The file content is kept in the blob store under StorageKey.
+//
+// Attachments are not deleted by cascade: when their chat session is deleted, ChatSessionID
+// is set to NULL and the attachment is removed together with its blob by the cleanup job.
+type Attachment struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ChatSessionID *uuid.UUID `gorm:"type:uuid;index"`
+	MessageID     *uuid.UUID `gorm:"type:uuid;index"` // nil until the attachment is sent with a message
+	Filename      string     `gorm:"not null;size:255"`
+	MimeType      string     `gorm:"not null;size:100"`
+	Size          int64      `gorm:"not null"`
+	Checksum      string     `gorm:"not null;size:64"` // SHA-256, hex-encoded
+	StorageKey    string     `gorm:"not null;size:255"`
+	CreatedAt     time.Time
+
+	// Relationships
+	ChatSession *ChatSession `gorm:"foreignKey:ChatSessionID;constraint:OnDelete:SET NULL"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Attachment
+func (Attachment) TableName() string {
+	return "attachments"
+}
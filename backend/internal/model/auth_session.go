@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthSession is a login of a user on a device. Its ID is the family ID of
+// the refresh tokens rotated from that login.
+type AuthSession struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	UserAgent  string    `gorm:"size:512"`
+	IPAddress  string    `gorm:"size:45"`
+	CreatedAt  time.Time
+	LastUsedAt time.Time // Time of the last token refresh
+	ExpiresAt  time.Time `gorm:"index;not null"` // Expiry of the latest refresh token
+	RevokedAt  *time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (s *AuthSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AuthSession
+func (AuthSession) TableName() string {
+	return "auth_sessions"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatEmbedding is the embedding of a chat session used for semantic search and related chats
+type ChatEmbedding struct {
+	ChatSessionID  uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID `gorm:"type:uuid;index;not null"`
+	EmbeddingModel string    `gorm:"size:100;not null"` // Embedder used for the vector
+	Embedding      Vector    `gorm:"type:bytea;not null"`
+	MessageCount   int       `gorm:"default:0"` // Number of messages covered by the embedding
+	UpdatedAt      time.Time
+
+	// Relationships
+	ChatSession ChatSession `gorm:"foreignKey:ChatSessionID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for ChatEmbedding
+func (ChatEmbedding) TableName() string {
+	return "chat_embeddings"
+}
@@ -9,17 +9,21 @@ import (
 
 // ChatSession represents a chat session
 type ChatSession struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid();index:idx_chat_sessions_user_updated,priority:3"`
+	UserID     uuid.UUID `gorm:"type:uuid;index;index:idx_chat_sessions_user_updated,priority:1;not null"`
 	Title      string    `gorm:"default:'New Chat';size:255"`
 	ModelUsed  string    `gorm:"default:'qwen2.5-3b';size:100"`
 	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	IsArchived bool `gorm:"default:false"`
+	UpdatedAt  time.Time  `gorm:"index:idx_chat_sessions_user_updated,priority:2"` // Keyset pagination order
+	IsArchived bool       `gorm:"default:false"`
+	FolderID   *uuid.UUID `gorm:"type:uuid;index"` // Nil for chats outside of folders
+	IsPinned   bool       `gorm:"default:false"`   // Pinned chats are listed first
 
 	// Relationships
 	User     User      `gorm:"foreignKey:UserID"`
 	Messages []Message `gorm:"foreignKey:ChatSessionID;constraint:OnDelete:CASCADE"`
+	Folder   *Folder   `gorm:"foreignKey:FolderID;constraint:OnDelete:SET NULL"`
+	Tags     []Tag     `gorm:"many2many:chat_session_tags;constraint:OnDelete:CASCADE"`
 }
 
 // BeforeCreate hook to generate UUID if not set
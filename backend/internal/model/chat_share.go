@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChatShare is a public read-only link to a snapshot of a chat session
+type ChatShare struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ChatSessionID uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Token         string     `gorm:"size:64;uniqueIndex;not null"`
+	Title         string     `gorm:"size:255"`
+	Snapshot      string     `gorm:"type:text;not null"` // JSON of the chat frozen at share time
+	MessageCount  int        `gorm:"default:0"`
+	PasswordHash  string     `gorm:"size:255"` // Empty if the link has no password
+	ExpiresAt     *time.Time // Nil if the link never expires
+	RevokedAt     *time.Time
+	ViewCount     int `gorm:"default:0"`
+	CreatedAt     time.Time
+
+	// Relationships
+	ChatSession ChatSession `gorm:"foreignKey:ChatSessionID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (s *ChatShare) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the link can be viewed at the given time
+func (s *ChatShare) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// TableName specifies the table name for ChatShare
+func (ChatShare) TableName() string {
+	return "chat_shares"
+}
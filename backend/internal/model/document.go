@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Document represents an uploaded document used for retrieval-augmented generation
+type Document struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID         uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ChatSessionID  *uuid.UUID `gorm:"type:uuid;index"` // nil for documents in the personal library
+	Filename       string     `gorm:"not null;size:255"`
+	MimeType       string     `gorm:"size:100"`
+	Size           int64      `gorm:"default:0"`
+	ChunkCount     int        `gorm:"default:0"`
+	EmbeddingModel string     `gorm:"size:100"` // Embedder used for the chunks of this document
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Relationships
+	User        User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	ChatSession *ChatSession    `gorm:"foreignKey:ChatSessionID;constraint:OnDelete:CASCADE"`
+	Chunks      []DocumentChunk `gorm:"foreignKey:DocumentID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (d *Document) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Document
+func (Document) TableName() string {
+	return "documents"
+}
+
+// DocumentChunk represents a piece of a document together with its embedding
+type DocumentChunk struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	DocumentID uuid.UUID `gorm:"type:uuid;index;not null"`
+	ChunkIndex int       `gorm:"not null"` // Order number in document
+	Content    string    `gorm:"type:text;not null"`
+	Embedding  Vector    `gorm:"type:bytea"`
+	CreatedAt  time.Time
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (c *DocumentChunk) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for DocumentChunk
+func (DocumentChunk) TableName() string {
+	return "document_chunks"
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Folder groups chat sessions; folders can be nested
+type Folder struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index"` // Nil for top-level folders
+	Name      string     `gorm:"size:255;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Relationships
+	User   User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Parent *Folder `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (f *Folder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Folder
+func (Folder) TableName() string {
+	return "folders"
+}
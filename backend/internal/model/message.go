@@ -10,16 +10,19 @@ import (
 // Message represents a message in a chat session
 type Message struct {
 	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	ChatSessionID  uuid.UUID `gorm:"type:uuid;index;not null"`
+	ChatSessionID  uuid.UUID `gorm:"type:uuid;index;index:idx_messages_session_sequence,priority:1;not null"`
 	Role           string    `gorm:"not null;size:50;check:role IN ('user', 'assistant', 'system')"`
 	Content        string    `gorm:"type:text;not null"`
 	Tokens         int       `gorm:"default:0"`
 	IsIncomplete   bool      `gorm:"default:false"` // Flag for incomplete/truncated messages
+	IsPinned       bool      `gorm:"default:false"` // Pinned messages are always part of the LLM context
+	IsExcluded     bool      `gorm:"default:false"` // Excluded messages are shown but never sent to the LLM
 	CreatedAt      time.Time
-	SequenceNumber int `gorm:"not null"` // Order number in chat
+	SequenceNumber int `gorm:"not null;index:idx_messages_session_sequence,priority:2"` // Order number in chat
 
 	// Relationships
-	ChatSession ChatSession `gorm:"foreignKey:ChatSessionID"`
+	ChatSession ChatSession  `gorm:"foreignKey:ChatSessionID"`
+	Attachments []Attachment `gorm:"foreignKey:MessageID;constraint:OnDelete:SET NULL"`
 }
 
 // BeforeCreate hook to generate UUID if not set
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OIDCLogin is a single sign-on attempt waiting for the provider to redirect back
+type OIDCLogin struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	StateHash    string    `gorm:"size:64;uniqueIndex;not null"` // SHA-256 of the OAuth2 state
+	Provider     string    `gorm:"size:50;not null"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:128;not null"` // PKCE code verifier
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (l *OIDCLogin) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for OIDCLogin
+func (OIDCLogin) TableName() string {
+	return "oidc_logins"
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is an issued refresh token. Tokens rotated from the same login
+// share a family, which is revoked as a whole on logout or token reuse.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;index;not null"`     // ID of the AuthSession
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null"` // SHA-256 of the token
+	ExpiresAt time.Time  `gorm:"index;not null"`
+	UsedAt    *time.Time // Set when the token is rotated
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for RefreshToken
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Built-in roles. They are defined in code and cannot be changed;
+// custom roles are stored in the roles table.
+const (
+	RoleUser  = "user"  // Default role, no administrative permissions
+	RoleAdmin = "admin" // Every permission
+)
+
+// Permissions granted by roles
+const (
+	PermissionUsersRead    = "users:read"    // List, search and view users
+	PermissionUsersManage  = "users:manage"  // Deactivate, reactivate and sign out users
+	PermissionGuestsManage = "guests:manage" // Reset guest account expirations
+	PermissionRolesManage  = "roles:manage"  // Manage custom roles and assign roles to users
+)
+
+// Permissions lists every permission
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionGuestsManage,
+	PermissionRolesManage,
+}
+
+// Role is a custom role: a named set of permissions assigned to users
+type Role struct {
+	Name        string `gorm:"primary_key;size:50"`
+	Description string `gorm:"size:255"`
+	Permissions string `gorm:"size:1000;not null"` // Comma-separated permissions
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName specifies the table name for Role
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionList returns the permissions of the role
+func (r *Role) PermissionList() []string {
+	if r.Permissions == "" {
+		return []string{}
+	}
+	return strings.Split(r.Permissions, ",")
+}
+
+// HasPermission reports whether the role grants a permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.PermissionList() {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
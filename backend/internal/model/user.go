@@ -18,7 +18,16 @@ type User struct {
 	LastLoginAt  *time.Time
 	IsActive     bool       `gorm:"default:true"`
 	IsGuest      bool       `gorm:"default:false"`
+	Role         string     `gorm:"size:50;not null;default:'user'"`
 	ExpiresAt    *time.Time `gorm:"index"`
+	SignupIP     string     `gorm:"size:45;index"` // IP address the account was created from
+	MessagesSent int        `gorm:"default:0"`     // Messages sent as a guest in the current window, for guest limits
+
+	// Start of the daily window MessagesSent counts in
+	MessagesWindowStart *time.Time
+
+	// Set when the user confirms their email address
+	EmailVerifiedAt *time.Time
 
 	// Relationships
 	ChatSessions []ChatSession `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an account at a single sign-on provider
+type UserIdentity struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	Provider    string    `gorm:"size:50;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string    `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"` // User ID at the provider
+	Email       string    `gorm:"size:255"`                                                           // Email reported by the provider
+	CreatedAt   time.Time
+	LastLoginAt *time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for UserIdentity
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// User token purposes
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	UserTokenOIDCLogin         = "oidc_login"     // Exchanged by the frontend for tokens after single sign-on
+	UserTokenMFAChallenge      = "mfa_challenge"  // Completes a sign-in with a second factor
+	UserTokenMFAEnrollment     = "mfa_enrollment" // Sets up a required second factor during sign-in
+)
+
+// UserToken is a single-use token sent to a user by email, e.g. a password
+// reset or email verification link
+type UserToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Purpose   string     `gorm:"size:32;not null"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null"` // SHA-256 of the token
+	ExpiresAt time.Time  `gorm:"index;not null"`
+	UsedAt    *time.Time // Set when the token is used or superseded
+	CreatedAt time.Time
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (t *UserToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for UserToken
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Vector is an embedding vector stored as little-endian float32 values in a bytea column
+type Vector []float32
+
+// Value implements driver.Valuer
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	buf := make([]byte, len(v)*4)
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+	return buf, nil
+}
+
+// Scan implements sql.Scanner
+func (v *Vector) Scan(value interface{}) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	buf, ok := value.([]byte)
+	if !ok {
+		return errors.New("vector: unsupported column type")
+	}
+	if len(buf)%4 != 0 {
+		return errors.New("vector: invalid byte length")
+	}
+
+	vec := make(Vector, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	*v = vec
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAttachmentNotFound is returned when an attachment does not exist or
+// does not belong to the user
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// ErrQuotaExceeded is returned when an attachment does not fit into the storage quota of its user
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// AttachmentRepository handles attachment data operations
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// CreateWithinQuota creates a new attachment if the attachments of its user,
+// including the new one, do not exceed quota bytes. Uploads of the same user
+// are serialized by locking the user row, so concurrent uploads cannot
+// overshoot the quota together.
+func (r *AttachmentRepository) CreateWithinQuota(attachment *model.Attachment, quota int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", attachment.UserID).
+			First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		var used int64
+		err = tx.Model(&model.Attachment{}).
+			Where("user_id = ?", attachment.UserID).
+			Select("COALESCE(SUM(size), 0)").
+			Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if used+attachment.Size > quota {
+			return ErrQuotaExceeded
+		}
+
+		return tx.Create(attachment).Error
+	})
+}
+
+// GetByIDAndUserID retrieves an attachment by ID and UserID (for security)
+func (r *AttachmentRepository) GetByIDAndUserID(id, userID uuid.UUID) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.Where("id = ? AND user_id = ? AND chat_session_id IS NOT NULL", id, userID).First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetByChatSessionID retrieves all attachments of a chat session
+func (r *AttachmentRepository) GetByChatSessionID(chatSessionID uuid.UUID) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Where("chat_session_id = ?", chatSessionID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// GetByChatSessionIDs retrieves all attachments of a user's chat sessions
+func (r *AttachmentRepository) GetByChatSessionIDs(chatSessionIDs []uuid.UUID, userID uuid.UUID) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	if len(chatSessionIDs) == 0 {
+		return attachments, nil
+	}
+
+	err := r.db.Where("chat_session_id IN ? AND user_id = ?", chatSessionIDs, userID).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// GetByMessageID retrieves the attachments sent with a message
+func (r *AttachmentRepository) GetByMessageID(messageID uuid.UUID) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Where("message_id = ?", messageID).Find(&attachments).Error
+	return attachments, err
+}
+
+// GetPending retrieves attachments of a chat session that are not yet attached to a message
+func (r *AttachmentRepository) GetPending(ids []uuid.UUID, chatSessionID, userID uuid.UUID) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Where("id IN ? AND chat_session_id = ? AND user_id = ? AND message_id IS NULL", ids, chatSessionID, userID).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// AttachToMessage links pending attachments of a user's chat session to a message.
+// It fails without changes unless all of the attachments could be linked.
+func (r *AttachmentRepository) AttachToMessage(ids []uuid.UUID, messageID, chatSessionID, userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Attachment{}).
+			Where("id IN ? AND chat_session_id = ? AND user_id = ? AND message_id IS NULL", ids, chatSessionID, userID).
+			Update("message_id", messageID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return ErrAttachmentNotFound
+		}
+		return nil
+	})
+}
+
+// TotalSizeByUserID sums the size of all attachments of a user
+func (r *AttachmentRepository) TotalSizeByUserID(userID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.Attachment{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetOrphaned retrieves attachments whose chat session was deleted
+func (r *AttachmentRepository) GetOrphaned(limit int) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Where("chat_session_id IS NULL").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// Delete permanently deletes an attachment record
+func (r *AttachmentRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.Attachment{}, "id = ?", id).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// AuthSessionRepository handles login session data operations
+type AuthSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthSessionRepository creates a new login session repository
+func NewAuthSessionRepository(db *gorm.DB) *AuthSessionRepository {
+	return &AuthSessionRepository{db: db}
+}
+
+// Create creates a new login session
+func (r *AuthSessionRepository) Create(session *model.AuthSession) error {
+	return r.db.Omit("User").Create(session).Error
+}
+
+// GetActiveByIDAndUserID retrieves a login session of a user that is neither revoked nor expired
+func (r *AuthSessionRepository) GetActiveByIDAndUserID(id, userID uuid.UUID) (*model.AuthSession, error) {
+	var session model.AuthSession
+	err := r.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", id, userID, time.Now()).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveByUserID retrieves the login sessions of a user that are neither revoked nor expired,
+// most recently used first
+func (r *AuthSessionRepository) GetActiveByUserID(userID uuid.UUID) ([]model.AuthSession, error) {
+	var sessions []model.AuthSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch records a token refresh of a login session from the given client
+func (r *AuthSessionRepository) Touch(id uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) error {
+	return r.db.Model(&model.AuthSession{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_used_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+// Revoke revokes login sessions by ID
+func (r *AuthSessionRepository) Revoke(ids ...uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.AuthSession{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUserID revokes all login sessions of a user
+func (r *AuthSessionRepository) RevokeByUserID(userID uuid.UUID) error {
+	return r.db.Model(&model.AuthSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired deletes login sessions past their expiry
+func (r *AuthSessionRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&model.AuthSession{}).Error
+}
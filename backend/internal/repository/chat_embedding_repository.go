@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChatEmbeddingRepository handles chat embedding data operations
+type ChatEmbeddingRepository struct {
+	db *gorm.DB
+}
+
+// NewChatEmbeddingRepository creates a new chat embedding repository
+func NewChatEmbeddingRepository(db *gorm.DB) *ChatEmbeddingRepository {
+	return &ChatEmbeddingRepository{db: db}
+}
+
+// Upsert creates or replaces the embedding of a chat session
+func (r *ChatEmbeddingRepository) Upsert(embedding *model.ChatEmbedding) error {
+	return r.db.Omit("ChatSession").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_session_id"}},
+		UpdateAll: true,
+	}).Create(embedding).Error
+}
+
+// GetByChatSessionID retrieves the embedding of a chat session
+func (r *ChatEmbeddingRepository) GetByChatSessionID(chatSessionID uuid.UUID) (*model.ChatEmbedding, error) {
+	var embedding model.ChatEmbedding
+	err := r.db.Where("chat_session_id = ?", chatSessionID).First(&embedding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("chat embedding not found")
+		}
+		return nil, err
+	}
+	return &embedding, nil
+}
+
+// GetByUserID retrieves all chat embeddings of a user made with an embedding model,
+// with their chat sessions
+func (r *ChatEmbeddingRepository) GetByUserID(userID uuid.UUID, embeddingModel string) ([]model.ChatEmbedding, error) {
+	var embeddings []model.ChatEmbedding
+	err := r.db.Preload("ChatSession").
+		Where("user_id = ? AND embedding_model = ?", userID, embeddingModel).
+		Find(&embeddings).Error
+	return embeddings, err
+}
+
+// GetUnindexedChatSessionIDs retrieves chat sessions with messages but without an
+// embedding made with the embedding model
+func (r *ChatEmbeddingRepository) GetUnindexedChatSessionIDs(embeddingModel string, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Table("chat_sessions").
+		Select("chat_sessions.id").
+		Joins("LEFT JOIN chat_embeddings ON chat_embeddings.chat_session_id = chat_sessions.id AND chat_embeddings.embedding_model = ?", embeddingModel).
+		Where("chat_embeddings.chat_session_id IS NULL").
+		Where("EXISTS (SELECT 1 FROM messages WHERE messages.chat_session_id = chat_sessions.id)").
+		Limit(limit).
+		Pluck("chat_sessions.id", &ids).Error
+	return ids, err
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ChatRepository handles chat session data operations
@@ -23,6 +25,27 @@ func (r *ChatRepository) Create(session *model.ChatSession) error {
 	return r.db.Create(session).Error
 }
 
+// CreateWithMessages creates a chat session together with its messages in a single transaction
+func (r *ChatRepository) CreateWithMessages(session *model.ChatSession, messages []model.Message) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		for i := range messages {
+			messages[i].ChatSessionID = session.ID
+		}
+
+		if len(messages) > 0 {
+			if err := tx.Omit("ChatSession").CreateInBatches(messages, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // GetByID retrieves a chat session by ID
 func (r *ChatRepository) GetByID(id uuid.UUID) (*model.ChatSession, error) {
 	var session model.ChatSession
@@ -62,9 +85,155 @@ func (r *ChatRepository) GetByUserID(userID uuid.UUID, includeArchived bool) ([]
 	return sessions, err
 }
 
-// Update updates a chat session
+// ChatListFilter holds chat session list parameters
+type ChatListFilter struct {
+	IncludeArchived   bool
+	FolderID          *uuid.UUID  // Only chats in this folder
+	IncludeSubfolders bool        // With FolderID, also chats in nested folders
+	Unfiled           bool        // Only chats outside of folders
+	TagIDs            []uuid.UUID // Only chats having all of these tags
+	Model             string      // Only chats using this model
+	From              *time.Time  // Inclusive lower bound of updated_at
+	To                *time.Time  // Exclusive upper bound of updated_at
+	Cursor            *ChatCursor // Only chats after this position
+	Limit             int         // Maximum number of chats (0 for all)
+}
+
+// ChatCursor is a position in the chat list, which lists pinned chats first,
+// then orders by updated_at and id descending
+type ChatCursor struct {
+	IsPinned  bool
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+// List retrieves the chat sessions of a user matching a filter, with their tags
+func (r *ChatRepository) List(userID uuid.UUID, filter ChatListFilter) ([]model.ChatSession, error) {
+	var sessions []model.ChatSession
+	query := r.db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where("user_id = ?", userID)
+
+	if !filter.IncludeArchived {
+		query = query.Where("is_archived = ?", false)
+	}
+
+	switch {
+	case filter.Unfiled:
+		query = query.Where("folder_id IS NULL")
+	case filter.FolderID != nil && filter.IncludeSubfolders:
+		query = query.Where("folder_id IN (?)", r.db.Raw(`
+			WITH RECURSIVE subfolders AS (
+				SELECT id FROM folders WHERE id = ?
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subfolders s ON f.parent_id = s.id
+			)
+			SELECT id FROM subfolders`, *filter.FolderID))
+	case filter.FolderID != nil:
+		query = query.Where("folder_id = ?", *filter.FolderID)
+	}
+
+	if filter.Model != "" {
+		query = query.Where("model_used = ?", filter.Model)
+	}
+	if filter.From != nil {
+		query = query.Where("updated_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("updated_at < ?", *filter.To)
+	}
+	if filter.Cursor != nil {
+		if filter.Cursor.IsPinned {
+			query = query.Where("(is_pinned = false OR (updated_at, id) < (?, ?))", filter.Cursor.UpdatedAt, filter.Cursor.ID)
+		} else {
+			query = query.Where("is_pinned = false AND (updated_at, id) < (?, ?)", filter.Cursor.UpdatedAt, filter.Cursor.ID)
+		}
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("id IN (?)", r.db.Table("chat_session_tags").
+			Select("chat_session_id").
+			Where("tag_id IN ?", filter.TagIDs).
+			Group("chat_session_id").
+			Having("COUNT(DISTINCT tag_id) = ?", len(filter.TagIDs)))
+	}
+
+	err := query.Order("is_pinned DESC, updated_at DESC, id DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// CountByUserID counts the chat sessions of a user, including archived ones
+func (r *ChatRepository) CountByUserID(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ChatSession{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// GetWithTags retrieves a chat session of a user with its tags
+func (r *ChatRepository) GetWithTags(id, userID uuid.UUID) (*model.ChatSession, error) {
+	var session model.ChatSession
+	err := r.db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where("id = ? AND user_id = ?", id, userID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("chat session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Update updates a chat session (associations are not saved)
 func (r *ChatRepository) Update(session *model.ChatSession) error {
-	return r.db.Save(session).Error
+	return r.db.Omit(clause.Associations).Save(session).Error
+}
+
+// SetPinned pins or unpins a chat session; the update time is kept so the chat keeps its position by activity
+func (r *ChatRepository) SetPinned(id, userID uuid.UUID, pinned bool) error {
+	result := r.db.Model(&model.ChatSession{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		UpdateColumn("is_pinned", pinned)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("chat session not found")
+	}
+
+	return nil
+}
+
+// MoveToFolder moves multiple chat sessions into a folder, or out of folders if folderID is nil
+func (r *ChatRepository) MoveToFolder(ids []uuid.UUID, userID uuid.UUID, folderID *uuid.UUID) error {
+	return r.db.Model(&model.ChatSession{}).
+		Where("id IN ? AND user_id = ?", ids, userID).
+		Update("folder_id", folderID).Error
+}
+
+// AddTags adds tags to multiple chat sessions; only sessions and tags of the user are affected
+func (r *ChatRepository) AddTags(ids []uuid.UUID, userID uuid.UUID, tagIDs []uuid.UUID) error {
+	return r.db.Exec(`
+		INSERT INTO chat_session_tags (chat_session_id, tag_id)
+		SELECT c.id, t.id
+		FROM chat_sessions c
+		CROSS JOIN tags t
+		WHERE c.id IN ? AND c.user_id = ? AND t.id IN ? AND t.user_id = ?
+		ON CONFLICT DO NOTHING`, ids, userID, tagIDs, userID).Error
+}
+
+// RemoveTags removes tags from multiple chat sessions of the user
+func (r *ChatRepository) RemoveTags(ids []uuid.UUID, userID uuid.UUID, tagIDs []uuid.UUID) error {
+	return r.db.Exec(`
+		DELETE FROM chat_session_tags
+		WHERE tag_id IN ? AND chat_session_id IN (
+			SELECT id FROM chat_sessions WHERE id IN ? AND user_id = ?
+		)`, tagIDs, ids, userID).Error
 }
 
 // Archive archives a chat session
@@ -143,6 +312,8 @@ func (r *ChatRepository) GetWithMessages(id, userID uuid.UUID) (*model.ChatSessi
 	var session model.ChatSession
 	err := r.db.Preload("Messages", func(db *gorm.DB) *gorm.DB {
 		return db.Order("sequence_number ASC")
+	}).Preload("Messages.Attachments").Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
 	}).Where("id = ? AND user_id = ?", id, userID).First(&session).Error
 
 	if err != nil {
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestListKeysetCursor(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewChatRepository(db)
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	// After a pinned chat come older pinned chats and then all unpinned chats
+	r.List(uuid.New(), ChatListFilter{Cursor: &ChatCursor{IsPinned: true, UpdatedAt: updatedAt, ID: id}, Limit: 21})
+	assertSQL(t, recorder.last(t),
+		"(is_pinned = false OR (updated_at, id) < ('2024-05-01 12:00:00', '00000000-0000-0000-0000-000000000001'))",
+		"ORDER BY is_pinned DESC, updated_at DESC, id DESC LIMIT 21")
+
+	// After an unpinned chat come only older unpinned chats
+	r.List(uuid.New(), ChatListFilter{Cursor: &ChatCursor{UpdatedAt: updatedAt, ID: id}})
+	statement := recorder.last(t)
+	assertSQL(t, statement, "is_pinned = false AND (updated_at, id) < ('2024-05-01 12:00:00', '00000000-0000-0000-0000-000000000001')")
+	assertNotSQL(t, statement, "LIMIT")
+}
+
+func TestListFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewChatRepository(db)
+	userID := uuid.MustParse("00000000-0000-0000-0000-0000000000aa")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tagA := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	tagB := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+
+	r.List(userID, ChatListFilter{Model: "gpt-4o", From: &from, To: &to, TagIDs: []uuid.UUID{tagA, tagB}})
+	statement := recorder.last(t)
+	assertSQL(t, statement,
+		"user_id = '00000000-0000-0000-0000-0000000000aa' AND is_archived = false",
+		"model_used = 'gpt-4o'",
+		"updated_at >= '2024-01-01 00:00:00'",
+		"updated_at < '2024-02-01 00:00:00'",
+		// Chats must carry all of the tags
+		"tag_id IN ('00000000-0000-0000-0000-00000000000a','00000000-0000-0000-0000-00000000000b')",
+		"HAVING COUNT(DISTINCT tag_id) = 2")
+
+	r.List(userID, ChatListFilter{IncludeArchived: true, Unfiled: true})
+	statement = recorder.last(t)
+	assertSQL(t, statement, "folder_id IS NULL")
+	assertNotSQL(t, statement, "is_archived")
+}
+
+func TestSetPinnedKeepsUpdateTime(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewChatRepository(db)
+
+	// Pinning is not activity: the chat keeps its place among the pinned chats
+	r.SetPinned(uuid.New(), uuid.New(), true)
+	statement := recorder.last(t)
+	assertSQL(t, statement, `SET "is_pinned"=true`)
+	assertNotSQL(t, statement, "updated_at")
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ChatShareRepository handles chat share link data operations
+type ChatShareRepository struct {
+	db *gorm.DB
+}
+
+// NewChatShareRepository creates a new chat share repository
+func NewChatShareRepository(db *gorm.DB) *ChatShareRepository {
+	return &ChatShareRepository{db: db}
+}
+
+// Create creates a new share link
+func (r *ChatShareRepository) Create(share *model.ChatShare) error {
+	return r.db.Omit("ChatSession").Create(share).Error
+}
+
+// GetByToken retrieves a share link by its token
+func (r *ChatShareRepository) GetByToken(token string) (*model.ChatShare, error) {
+	var share model.ChatShare
+	err := r.db.Where("token = ?", token).First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("share link not found")
+		}
+		return nil, err
+	}
+	return &share, nil
+}
+
+// GetActiveByUserID retrieves the share links of a user that are neither revoked nor expired.
+// If chatSessionID is not nil, only links of that chat are returned.
+func (r *ChatShareRepository) GetActiveByUserID(userID, chatSessionID uuid.UUID) ([]model.ChatShare, error) {
+	var shares []model.ChatShare
+	query := r.db.Omit("snapshot").
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if chatSessionID != uuid.Nil {
+		query = query.Where("chat_session_id = ?", chatSessionID)
+	}
+	err := query.Order("created_at DESC").Find(&shares).Error
+	return shares, err
+}
+
+// Revoke revokes a share link of a user
+func (r *ChatShareRepository) Revoke(id, userID uuid.UUID) error {
+	result := r.db.Model(&model.ChatShare{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("share link not found")
+	}
+	return nil
+}
+
+// IncrementViewCount counts a view of a share link
+func (r *ChatShareRepository) IncrementViewCount(id uuid.UUID) error {
+	return r.db.Model(&model.ChatShare{}).
+		Where("id = ?", id).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// DocumentRepository handles document and document chunk data operations
+type DocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db *gorm.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+// CreateWithChunks creates a document together with its chunks in a single transaction
+func (r *DocumentRepository) CreateWithChunks(document *model.Document, chunks []model.DocumentChunk) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(document).Error; err != nil {
+			return err
+		}
+
+		for i := range chunks {
+			chunks[i].DocumentID = document.ID
+		}
+
+		if len(chunks) > 0 {
+			if err := tx.CreateInBatches(chunks, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByIDAndUserID retrieves a document by ID and UserID (for security)
+func (r *DocumentRepository) GetByIDAndUserID(id, userID uuid.UUID) (*model.Document, error) {
+	var document model.Document
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&document).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("document not found")
+		}
+		return nil, err
+	}
+	return &document, nil
+}
+
+// GetByUserID retrieves all documents of a user, optionally limited to a chat session
+func (r *DocumentRepository) GetByUserID(userID uuid.UUID, chatSessionID *uuid.UUID) ([]model.Document, error) {
+	var documents []model.Document
+	query := r.db.Where("user_id = ?", userID)
+
+	if chatSessionID != nil {
+		query = query.Where("chat_session_id = ?", *chatSessionID)
+	}
+
+	err := query.Order("created_at DESC").Find(&documents).Error
+	return documents, err
+}
+
+// GetForRetrieval retrieves documents available as context in a chat session:
+// documents attached to the session and documents from the user's personal library
+func (r *DocumentRepository) GetForRetrieval(userID, chatSessionID uuid.UUID, embeddingModel string) ([]model.Document, error) {
+	var documents []model.Document
+	err := r.db.Where("user_id = ? AND (chat_session_id = ? OR chat_session_id IS NULL) AND embedding_model = ?",
+		userID, chatSessionID, embeddingModel).
+		Find(&documents).Error
+	return documents, err
+}
+
+// GetIDsByChatSessionIDs retrieves the IDs of documents attached to a user's chat sessions
+func (r *DocumentRepository) GetIDsByChatSessionIDs(chatSessionIDs []uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(chatSessionIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Model(&model.Document{}).
+		Where("chat_session_id IN ? AND user_id = ?", chatSessionIDs, userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetChunksByDocumentIDs retrieves all chunks of the given documents
+func (r *DocumentRepository) GetChunksByDocumentIDs(documentIDs []uuid.UUID) ([]model.DocumentChunk, error) {
+	var chunks []model.DocumentChunk
+	if len(documentIDs) == 0 {
+		return chunks, nil
+	}
+
+	err := r.db.Where("document_id IN ?", documentIDs).
+		Order("chunk_index ASC").
+		Find(&chunks).Error
+	return chunks, err
+}
+
+// GetChunksByIDs retrieves chunks by their IDs
+func (r *DocumentRepository) GetChunksByIDs(ids []uuid.UUID) ([]model.DocumentChunk, error) {
+	var chunks []model.DocumentChunk
+	if len(ids) == 0 {
+		return chunks, nil
+	}
+
+	err := r.db.Where("id IN ?", ids).Find(&chunks).Error
+	return chunks, err
+}
+
+// GetChunkEmbeddings retrieves chunk embeddings (without content) of the given documents
+func (r *DocumentRepository) GetChunkEmbeddings(documentIDs []uuid.UUID) ([]model.DocumentChunk, error) {
+	var chunks []model.DocumentChunk
+	if len(documentIDs) == 0 {
+		return chunks, nil
+	}
+
+	err := r.db.Select("id", "document_id", "embedding").
+		Where("document_id IN ?", documentIDs).
+		Find(&chunks).Error
+	return chunks, err
+}
+
+// EachChunkEmbedding iterates over the embeddings (without content) of all chunks in batches
+func (r *DocumentRepository) EachChunkEmbedding(batchSize int, fn func(chunks []model.DocumentChunk) error) error {
+	var chunks []model.DocumentChunk
+	return r.db.Select("id", "document_id", "embedding").
+		FindInBatches(&chunks, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(chunks)
+		}).Error
+}
+
+// CountChunks counts all document chunks
+func (r *DocumentRepository) CountChunks() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.DocumentChunk{}).Count(&count).Error
+	return count, err
+}
+
+// GetChunk retrieves a chunk of a document
+func (r *DocumentRepository) GetChunk(documentID, chunkID uuid.UUID) (*model.DocumentChunk, error) {
+	var chunk model.DocumentChunk
+	err := r.db.Where("id = ? AND document_id = ?", chunkID, documentID).First(&chunk).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("document chunk not found")
+		}
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// GetExistingIDs returns the IDs among the given ones that still belong to a document
+func (r *DocumentRepository) GetExistingIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	var existing []uuid.UUID
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&model.Document{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+// Delete permanently deletes a document and its chunks
+func (r *DocumentRepository) Delete(id, userID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Document{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("document not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// offlineConnector is a database/sql connector that never connects
+type offlineConnector struct{}
+
+func (offlineConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("no database in tests")
+}
+
+func (offlineConnector) Driver() driver.Driver { return offlineDriver{} }
+
+type offlineDriver struct{}
+
+func (offlineDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("no database in tests")
+}
+
+// sqlRecorder is a GORM logger recording the SQL of every statement
+type sqlRecorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, sql)
+}
+
+// last returns the most recent statement, failing the test if there is none
+func (r *sqlRecorder) last(t *testing.T) string {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.statements) == 0 {
+		t.Fatal("no SQL statement was recorded")
+	}
+	return r.statements[len(r.statements)-1]
+}
+
+// newDryRunDB opens a Postgres GORM database that builds SQL without running it
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(offlineConnector{})}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db, recorder
+}
+
+// assertNotSQL checks that a statement contains none of the fragments
+func assertNotSQL(t *testing.T, statement string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if strings.Contains(statement, fragment) {
+			t.Errorf("SQL contains %q:\n%s", fragment, statement)
+		}
+	}
+}
+
+// assertSQL checks that a statement contains all fragments, ignoring whitespace differences
+func assertSQL(t *testing.T, statement string, fragments ...string) {
+	t.Helper()
+	normalized := strings.Join(strings.Fields(statement), " ")
+	for _, fragment := range fragments {
+		if !strings.Contains(normalized, fragment) {
+			t.Errorf("SQL does not contain %q:\n%s", fragment, normalized)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// FolderRepository handles folder data operations
+type FolderRepository struct {
+	db *gorm.DB
+}
+
+// NewFolderRepository creates a new folder repository
+func NewFolderRepository(db *gorm.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+// Create creates a new folder
+func (r *FolderRepository) Create(folder *model.Folder) error {
+	return r.db.Omit("User", "Parent").Create(folder).Error
+}
+
+// GetByIDAndUserID retrieves a folder by ID and UserID
+func (r *FolderRepository) GetByIDAndUserID(id, userID uuid.UUID) (*model.Folder, error) {
+	var folder model.Folder
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("folder not found")
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// GetByUserID retrieves all folders of a user
+func (r *FolderRepository) GetByUserID(userID uuid.UUID) ([]model.Folder, error) {
+	var folders []model.Folder
+	err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&folders).Error
+	return folders, err
+}
+
+// Update updates a folder
+func (r *FolderRepository) Update(folder *model.Folder) error {
+	return r.db.Omit("User", "Parent").Save(folder).Error
+}
+
+// Delete deletes a folder with its subfolders; their chats are moved out of folders
+func (r *FolderRepository) Delete(id, userID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Folder{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("folder not found")
+	}
+
+	return nil
+}
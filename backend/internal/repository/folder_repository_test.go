@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestListFolderFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewChatRepository(db)
+	folderID := uuid.MustParse("00000000-0000-0000-0000-0000000000f1")
+
+	// Nested folders are collected recursively from the folder
+	r.List(uuid.New(), ChatListFilter{FolderID: &folderID, IncludeSubfolders: true})
+	assertSQL(t, recorder.last(t),
+		"folder_id IN ( WITH RECURSIVE subfolders AS ( SELECT id FROM folders WHERE id = '00000000-0000-0000-0000-0000000000f1'",
+		"SELECT f.id FROM folders f JOIN subfolders s ON f.parent_id = s.id",
+		"SELECT id FROM subfolders)")
+
+	r.List(uuid.New(), ChatListFilter{FolderID: &folderID})
+	statement := recorder.last(t)
+	assertSQL(t, statement, "folder_id = '00000000-0000-0000-0000-0000000000f1'")
+	assertNotSQL(t, statement, "RECURSIVE")
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/model"
@@ -45,10 +46,140 @@ func (r *MessageRepository) GetByChatSessionID(chatSessionID uuid.UUID) ([]model
 	return messages, err
 }
 
-// GetLastN retrieves last N messages for a chat session
+// ChatStats holds aggregated message statistics of a chat session
+type ChatStats struct {
+	ChatSessionID   uuid.UUID
+	MessageCount    int
+	TotalTokens     int
+	LastMessageAt   time.Time
+	LastMessageRole string
+	LastMessageText string // First previewChars characters of the last message
+}
+
+// GetChatStats computes message statistics for multiple chat sessions in a single query.
+// Sessions without messages are not returned.
+func (r *MessageRepository) GetChatStats(chatSessionIDs []uuid.UUID, previewChars int) ([]ChatStats, error) {
+	var stats []ChatStats
+	if len(chatSessionIDs) == 0 {
+		return stats, nil
+	}
+
+	err := r.db.Raw(`
+		SELECT s.chat_session_id, s.message_count, s.total_tokens, s.last_message_at,
+			l.role AS last_message_role, LEFT(l.content, ?) AS last_message_text
+		FROM (
+			SELECT chat_session_id, COUNT(*) AS message_count,
+				COALESCE(SUM(tokens), 0) AS total_tokens, MAX(created_at) AS last_message_at
+			FROM messages
+			WHERE chat_session_id IN ?
+			GROUP BY chat_session_id
+		) s
+		CROSS JOIN LATERAL (
+			SELECT role, content
+			FROM messages m
+			WHERE m.chat_session_id = s.chat_session_id
+			ORDER BY m.sequence_number DESC
+			LIMIT 1
+		) l`, previewChars, chatSessionIDs).Scan(&stats).Error
+	return stats, err
+}
+
+// GetPage retrieves up to limit messages of a chat session after a sequence number
+// (before it when descending); afterSeq 0 starts at the first (or last) message
+func (r *MessageRepository) GetPage(chatSessionID uuid.UUID, afterSeq, limit int, descending bool) ([]model.Message, error) {
+	var messages []model.Message
+	query := r.db.Preload("Attachments").Where("chat_session_id = ?", chatSessionID)
+
+	if descending {
+		if afterSeq > 0 {
+			query = query.Where("sequence_number < ?", afterSeq)
+		}
+		query = query.Order("sequence_number DESC")
+	} else {
+		query = query.Where("sequence_number > ?", afterSeq).Order("sequence_number ASC")
+	}
+
+	err := query.Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// GetPinned retrieves the pinned messages of a chat session before a sequence number
+// (all pinned messages if beforeSeq is 0)
+func (r *MessageRepository) GetPinned(chatSessionID uuid.UUID, beforeSeq int) ([]model.Message, error) {
+	var messages []model.Message
+	query := r.db.Preload("Attachments").
+		Where("chat_session_id = ? AND is_pinned = ?", chatSessionID, true)
+	if beforeSeq > 0 {
+		query = query.Where("sequence_number < ?", beforeSeq)
+	}
+	err := query.Order("sequence_number ASC").Find(&messages).Error
+	return messages, err
+}
+
+// SetPinned pins or unpins a message of a chat session
+func (r *MessageRepository) SetPinned(id, chatSessionID uuid.UUID, pinned bool) error {
+	result := r.db.Model(&model.Message{}).
+		Where("id = ? AND chat_session_id = ?", id, chatSessionID).
+		Update("is_pinned", pinned)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("message not found")
+	}
+
+	return nil
+}
+
+// SetExcluded excludes a message of a chat session from the model context or includes it again
+func (r *MessageRepository) SetExcluded(id, chatSessionID uuid.UUID, excluded bool) error {
+	result := r.db.Model(&model.Message{}).
+		Where("id = ? AND chat_session_id = ?", id, chatSessionID).
+		Update("is_excluded", excluded)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("message not found")
+	}
+
+	return nil
+}
+
+// DeleteFromChat deletes a message of a chat session. Sequence numbers of the
+// other messages are kept, so cursors and search results pointing at them stay
+// valid; the chat is left with a gap. Attachments of the message are detached
+// from the chat for the orphaned attachment cleanup.
+func (r *MessageRepository) DeleteFromChat(id, chatSessionID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var message model.Message
+		err := tx.Where("id = ? AND chat_session_id = ?", id, chatSessionID).First(&message).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("message not found")
+			}
+			return err
+		}
+
+		if err := tx.Model(&model.Attachment{}).
+			Where("message_id = ?", message.ID).
+			Updates(map[string]interface{}{"message_id": nil, "chat_session_id": nil}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.Message{}, "id = ?", message.ID).Error
+	})
+}
+
+// GetLastN retrieves last N messages for a chat session, skipping messages excluded from context
 func (r *MessageRepository) GetLastN(chatSessionID uuid.UUID, n int) ([]model.Message, error) {
 	var messages []model.Message
-	err := r.db.Where("chat_session_id = ?", chatSessionID).
+	err := r.db.Preload("Attachments").
+		Where("chat_session_id = ? AND is_excluded = ?", chatSessionID, false).
 		Order("sequence_number DESC").
 		Limit(n).
 		Find(&messages).Error
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetPageKeyset(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewMessageRepository(db)
+	sessionID := uuid.New()
+
+	r.GetPage(sessionID, 0, 51, false)
+	assertSQL(t, recorder.last(t), "sequence_number > 0", "ORDER BY sequence_number ASC LIMIT 51")
+
+	r.GetPage(sessionID, 30, 51, false)
+	assertSQL(t, recorder.last(t), "sequence_number > 30", "ORDER BY sequence_number ASC")
+
+	// Newest first: the first page starts at the last message
+	r.GetPage(sessionID, 0, 51, true)
+	statement := recorder.last(t)
+	assertSQL(t, statement, "ORDER BY sequence_number DESC LIMIT 51")
+	assertNotSQL(t, statement, "sequence_number <")
+
+	r.GetPage(sessionID, 30, 51, true)
+	assertSQL(t, recorder.last(t), "sequence_number < 30", "ORDER BY sequence_number DESC")
+}
+
+func TestGetPinned(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewMessageRepository(db)
+	sessionID := uuid.New()
+
+	// Pinned messages older than the history window
+	r.GetPinned(sessionID, 40)
+	assertSQL(t, recorder.last(t), "is_pinned = true", "sequence_number < 40", "ORDER BY sequence_number ASC")
+
+	r.GetPinned(sessionID, 0)
+	statement := recorder.last(t)
+	assertSQL(t, statement, "is_pinned = true")
+	assertNotSQL(t, statement, "sequence_number <")
+}
+
+func TestGetLastNSkipsExcluded(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewMessageRepository(db)
+
+	r.GetLastN(uuid.New(), 20)
+	assertSQL(t, recorder.last(t), "is_excluded = false", "ORDER BY sequence_number DESC LIMIT 20")
+}
+
+func TestSetExcluded(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewMessageRepository(db)
+	id := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	sessionID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	// Only a message of the given chat is changed
+	r.SetExcluded(id, sessionID, true)
+	assertSQL(t, recorder.last(t), `SET "is_excluded"=true`,
+		"id = '00000000-0000-0000-0000-000000000001' AND chat_session_id = '00000000-0000-0000-0000-000000000002'")
+}
+
+func TestGetChatStatsSingleQuery(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewMessageRepository(db)
+	a := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	b := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+
+	// All sessions of a page are aggregated in one statement
+	r.GetChatStats([]uuid.UUID{a, b}, 240)
+	if len(recorder.statements) != 1 {
+		t.Fatalf("%d statements, want 1", len(recorder.statements))
+	}
+	assertSQL(t, recorder.last(t),
+		"LEFT(l.content, 240)",
+		"WHERE chat_session_id IN ('00000000-0000-0000-0000-00000000000a','00000000-0000-0000-0000-00000000000b')",
+		"GROUP BY chat_session_id",
+		"ORDER BY m.sequence_number DESC LIMIT 1")
+
+	// No query without sessions
+	stats, err := r.GetChatStats(nil, 240)
+	if err != nil || len(stats) != 0 || len(recorder.statements) != 1 {
+		t.Errorf("GetChatStats(nil) = %v, %v with %d statements", stats, err, len(recorder.statements))
+	}
+}
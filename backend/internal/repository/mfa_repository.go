@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrMFANotSetUp is returned when a user has no TOTP factor
+var ErrMFANotSetUp = errors.New("two-factor authentication is not set up")
+
+// MFARepository handles two-factor authentication data operations
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository creates a new two-factor authentication repository
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetFactor retrieves the TOTP factor of a user
+func (r *MFARepository) GetFactor(userID uuid.UUID) (*model.TOTPFactor, error) {
+	var factor model.TOTPFactor
+	err := r.db.Where("user_id = ?", userID).First(&factor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFANotSetUp
+		}
+		return nil, err
+	}
+	return &factor, nil
+}
+
+// SaveFactor creates or replaces the TOTP factor of a user
+func (r *MFARepository) SaveFactor(factor *model.TOTPFactor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", factor.UserID).Delete(&model.TOTPFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(factor).Error
+	})
+}
+
+// AcceptCode records a code accepted for a time step, unless a code of the same
+// or a later step was already accepted. It reports whether the code was recorded.
+func (r *MFARepository) AcceptCode(factorID uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&model.TOTPFactor{}).
+		Where("id = ? AND last_used_step < ?", factorID, step).
+		Updates(map[string]interface{}{"last_used_step": step, "failed_attempts": 0, "locked_until": nil})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Confirm marks a factor as confirmed
+func (r *MFARepository) Confirm(factorID uuid.UUID) error {
+	return r.db.Model(&model.TOTPFactor{}).Where("id = ?", factorID).Update("confirmed_at", time.Now()).Error
+}
+
+// RecordFailure counts a failed code and locks the factor until lockUntil once
+// maxAttempts consecutive codes have failed
+func (r *MFARepository) RecordFailure(factorID uuid.UUID, maxAttempts int, lockUntil time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.TOTPFactor{}).Where("id = ?", factorID).
+			Update("failed_attempts", gorm.Expr("failed_attempts + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.TOTPFactor{}).
+			Where("id = ? AND failed_attempts >= ?", factorID, maxAttempts).
+			Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": lockUntil}).Error
+	})
+}
+
+// ResetFailures clears the failed code count of a factor
+func (r *MFARepository) ResetFailures(factorID uuid.UUID) error {
+	return r.db.Model(&model.TOTPFactor{}).Where("id = ?", factorID).
+		Updates(map[string]interface{}{"failed_attempts": 0, "locked_until": nil}).Error
+}
+
+// Delete removes the TOTP factor and the recovery codes of a user
+func (r *MFARepository) Delete(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.TOTPFactor{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user
+func (r *MFARepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []model.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used. It reports
+// whether the code was valid.
+func (r *MFARepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *MFARepository) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
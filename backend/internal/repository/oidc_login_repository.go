@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrOIDCLoginInvalid is returned for unknown, expired or already completed sign-ins
+var ErrOIDCLoginInvalid = errors.New("invalid or expired sign-in attempt")
+
+// OIDCLoginRepository handles pending single sign-on data operations
+type OIDCLoginRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCLoginRepository creates a new pending sign-on repository
+func NewOIDCLoginRepository(db *gorm.DB) *OIDCLoginRepository {
+	return &OIDCLoginRepository{db: db}
+}
+
+// Create stores a new pending sign-in
+func (r *OIDCLoginRepository) Create(login *model.OIDCLogin) error {
+	return r.db.Create(login).Error
+}
+
+// Consume deletes an unexpired pending sign-in by the hash of its state and returns it.
+// Deleting it first makes the state single use even with concurrent callbacks.
+func (r *OIDCLoginRepository) Consume(stateHash string) (*model.OIDCLogin, error) {
+	var login model.OIDCLogin
+	err := r.db.Where("state_hash = ?", stateHash).First(&login).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOIDCLoginInvalid
+		}
+		return nil, err
+	}
+
+	result := r.db.Where("id = ?", login.ID).Delete(&model.OIDCLogin{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || login.ExpiresAt.Before(time.Now()) {
+		return nil, ErrOIDCLoginInvalid
+	}
+	return &login, nil
+}
+
+// DeleteExpired deletes pending sign-ins past their expiry
+func (r *OIDCLoginRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&model.OIDCLogin{}).Error
+}
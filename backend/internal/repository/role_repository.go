@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrRoleNotFound is returned when no custom role has the given name
+var ErrRoleNotFound = errors.New("role not found")
+
+// RoleRepository handles custom role data operations
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create creates a new custom role
+func (r *RoleRepository) Create(role *model.Role) error {
+	return r.db.Create(role).Error
+}
+
+// GetByName retrieves a custom role by name
+func (r *RoleRepository) GetByName(name string) (*model.Role, error) {
+	var role model.Role
+	err := r.db.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetAll retrieves all custom roles ordered by name
+func (r *RoleRepository) GetAll() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+// Update updates a custom role
+func (r *RoleRepository) Update(role *model.Role) error {
+	return r.db.Save(role).Error
+}
+
+// Delete deletes a custom role by name
+func (r *RoleRepository) Delete(name string) error {
+	result := r.db.Where("name = ?", name).Delete(&model.Role{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SearchFilter holds full-text search parameters
+type SearchFilter struct {
+	UserID   uuid.UUID
+	Query    string
+	From     *time.Time // Inclusive lower bound of message/chat time
+	To       *time.Time // Exclusive upper bound of message/chat time
+	Role     string     // Only messages with this role (excludes title matches)
+	Model    string     // Only chats using this model
+	Archived *bool      // Only archived or only active chats
+	Limit    int
+	Offset   int
+}
+
+// SearchResult is a ranked search match: a message or a chat title
+type SearchResult struct {
+	Type           string // "message" or "chat"
+	ChatID         uuid.UUID
+	ChatTitle      string
+	ModelUsed      string
+	IsArchived     bool
+	MessageID      *uuid.UUID
+	Role           *string
+	SequenceNumber *int
+	CreatedAt      time.Time
+	Rank           float64
+	Snippet        string // HTML-escaped text with matched terms wrapped in <mark></mark>
+}
+
+// Matched terms are delimited with private use characters in ts_headline output,
+// which are replaced with <mark> tags after the snippet text is HTML-escaped
+const (
+	snippetStartSel = "\ue000"
+	snippetStopSel  = "\ue001"
+)
+
+// searchHeadlineOptions marks matched terms in snippets
+const searchHeadlineOptions = "StartSel=\"" + snippetStartSel + "\", StopSel=\"" + snippetStopSel + "\", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// snippetReplacer turns headline markers into <mark> tags
+var snippetReplacer = strings.NewReplacer(snippetStartSel, "<mark>", snippetStopSel, "</mark>")
+
+// SearchRepository handles full-text search queries
+type SearchRepository struct {
+	db         *gorm.DB
+	textConfig string
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *gorm.DB, textConfig string) *SearchRepository {
+	return &SearchRepository{db: db, textConfig: textConfig}
+}
+
+// Search finds messages and chat titles matching a web-search style query,
+// ranked by relevance, with highlighted snippets. It also returns the total
+// number of matches.
+func (r *SearchRepository) Search(filter SearchFilter) ([]SearchResult, int64, error) {
+	args := map[string]interface{}{
+		"config":  r.textConfig,
+		"query":   filter.Query,
+		"user_id": filter.UserID,
+		"options": searchHeadlineOptions,
+		"markers": snippetStartSel + snippetStopSel,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	}
+
+	messageConditions := []string{"c.user_id = @user_id", "m.search_vector @@ q.query"}
+	chatConditions := []string{"c.user_id = @user_id", "c.search_vector @@ q.query"}
+
+	if filter.From != nil {
+		args["from"] = *filter.From
+		messageConditions = append(messageConditions, "m.created_at >= @from")
+		chatConditions = append(chatConditions, "c.updated_at >= @from")
+	}
+	if filter.To != nil {
+		args["to"] = *filter.To
+		messageConditions = append(messageConditions, "m.created_at < @to")
+		chatConditions = append(chatConditions, "c.updated_at < @to")
+	}
+	if filter.Model != "" {
+		args["model"] = filter.Model
+		messageConditions = append(messageConditions, "c.model_used = @model")
+		chatConditions = append(chatConditions, "c.model_used = @model")
+	}
+	if filter.Archived != nil {
+		args["archived"] = *filter.Archived
+		messageConditions = append(messageConditions, "c.is_archived = @archived")
+		chatConditions = append(chatConditions, "c.is_archived = @archived")
+	}
+
+	matches := `
+		SELECT 'message' AS type, c.id AS chat_id, c.title AS chat_title, c.model_used, c.is_archived,
+			m.id AS message_id, m.role, m.sequence_number, m.created_at,
+			ts_rank_cd(m.search_vector, q.query) AS rank, m.content AS text
+		FROM messages m
+		JOIN chat_sessions c ON c.id = m.chat_session_id
+		CROSS JOIN q
+		WHERE ` + strings.Join(messageConditions, " AND ")
+
+	if filter.Role != "" {
+		args["role"] = filter.Role
+		matches += " AND m.role = @role"
+	} else {
+		// Title matches rank above message matches of similar relevance
+		matches += `
+		UNION ALL
+		SELECT 'chat', c.id, c.title, c.model_used, c.is_archived,
+			NULL, NULL, NULL, c.updated_at,
+			ts_rank_cd(c.search_vector, q.query) * 2, c.title
+		FROM chat_sessions c
+		CROSS JOIN q
+		WHERE ` + strings.Join(chatConditions, " AND ")
+	}
+
+	query := `WITH q AS (SELECT websearch_to_tsquery(CAST(@config AS regconfig), @query) AS query)`
+
+	// Counted separately so the total is also known for pages past the last match
+	var total int64
+	countSQL := query + `
+		SELECT COUNT(*) FROM (` + matches + `) matches`
+	if err := r.db.Raw(countSQL, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Snippets are only computed for the returned page
+	sql := query + `
+		SELECT page.type, page.chat_id, page.chat_title, page.model_used, page.is_archived,
+			page.message_id, page.role, page.sequence_number, page.created_at, page.rank,
+			ts_headline(CAST(@config AS regconfig), translate(page.text, @markers, ''), q.query, @options) AS snippet
+		FROM (
+			SELECT matches.*
+			FROM (` + matches + `) matches
+			ORDER BY rank DESC, created_at DESC
+			LIMIT @limit OFFSET @offset
+		) page
+		CROSS JOIN q
+		ORDER BY page.rank DESC, page.created_at DESC`
+
+	var results []SearchResult
+	if err := r.db.Raw(sql, args).Scan(&results).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].Snippet = highlightSnippet(results[i].Snippet)
+	}
+	return results, total, nil
+}
+
+// highlightSnippet HTML-escapes a headline and replaces its markers with <mark> tags
+func highlightSnippet(headline string) string {
+	return snippetReplacer.Replace(html.EscapeString(headline))
+}
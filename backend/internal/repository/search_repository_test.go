@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	tests := []struct {
+		headline string
+		want     string
+	}{
+		{"plain " + snippetStartSel + "match" + snippetStopSel + " text", "plain <mark>match</mark> text"},
+		{"<img src=x onerror=alert(1)> " + snippetStartSel + "alert" + snippetStopSel, "&lt;img src=x onerror=alert(1)&gt; <mark>alert</mark>"},
+		{"a & b \"quoted\" 'single'", "a &amp; b &#34;quoted&#34; &#39;single&#39;"},
+		{"</mark><script>", "&lt;/mark&gt;&lt;script&gt;"},
+	}
+	for _, tt := range tests {
+		if got := highlightSnippet(tt.headline); got != tt.want {
+			t.Errorf("highlightSnippet(%q) = %q, want %q", tt.headline, got, tt.want)
+		}
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewSearchRepository(db, "russian")
+	userID := uuid.MustParse("00000000-0000-0000-0000-0000000000aa")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	archived := false
+
+	// The dry run stops after the first raw query, which counts all matches
+	r.Search(SearchFilter{UserID: userID, Query: "go", From: &from, Model: "gpt-4o", Archived: &archived, Limit: 20, Offset: 40})
+	statement := recorder.last(t)
+	assertSQL(t, statement,
+		"websearch_to_tsquery(CAST('russian' AS regconfig), 'go')",
+		"SELECT COUNT(*) FROM",
+		"m.search_vector @@ q.query",
+		"m.created_at >= '2024-01-01 00:00:00'",
+		"UNION ALL",
+		"c.search_vector @@ q.query",
+		"c.updated_at >= '2024-01-01 00:00:00'")
+	assertNotSQL(t, statement, "LIMIT", "ts_headline")
+
+	// Both message and title matches are scoped to the user and filtered
+	if n := strings.Count(statement, "c.user_id = '00000000-0000-0000-0000-0000000000aa'"); n != 2 {
+		t.Errorf("user condition appears %d times, want 2 in:\n%s", n, statement)
+	}
+	if n := strings.Count(statement, "c.model_used = 'gpt-4o'") + strings.Count(statement, "c.is_archived = false"); n != 4 {
+		t.Errorf("model and archived conditions appear %d times, want 4 in:\n%s", n, statement)
+	}
+}
+
+func TestSearchQueryByRole(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	r := NewSearchRepository(db, "simple")
+
+	// Titles have no role, so only messages are searched
+	r.Search(SearchFilter{UserID: uuid.New(), Query: "go", Role: "assistant", Limit: 20})
+	statement := recorder.last(t)
+	assertSQL(t, statement, "m.role = 'assistant'")
+	assertNotSQL(t, statement, "UNION ALL", "c.search_vector")
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// TagRepository handles tag data operations
+type TagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Create creates a new tag
+func (r *TagRepository) Create(tag *model.Tag) error {
+	return r.db.Omit("User").Create(tag).Error
+}
+
+// GetByIDAndUserID retrieves a tag by ID and UserID
+func (r *TagRepository) GetByIDAndUserID(id, userID uuid.UUID) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName retrieves a tag of a user by name (case-insensitive)
+func (r *TagRepository) GetByName(userID uuid.UUID, name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByUserID retrieves all tags of a user
+func (r *TagRepository) GetByUserID(userID uuid.UUID) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// CountByIDs counts the tags among ids that belong to a user
+func (r *TagRepository) CountByIDs(ids []uuid.UUID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Tag{}).Where("id IN ? AND user_id = ?", ids, userID).Count(&count).Error
+	return count, err
+}
+
+// Update updates a tag
+func (r *TagRepository) Update(tag *model.Tag) error {
+	return r.db.Omit("User").Save(tag).Error
+}
+
+// Delete deletes a tag; it is removed from all chat sessions
+func (r *TagRepository) Delete(id, userID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Tag{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("tag not found")
+	}
+
+	return nil
+}
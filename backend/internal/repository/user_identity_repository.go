@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository handles single sign-on identity data operations
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new user identity repository
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+// Create links a new identity to a user
+func (r *UserIdentityRepository) Create(identity *model.UserIdentity) error {
+	return r.db.Omit("User").Create(identity).Error
+}
+
+// GetByProviderSubject retrieves the identity of a provider user
+func (r *UserIdentityRepository) GetByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("identity not found")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUserID retrieves the identities linked to a user
+func (r *UserIdentityRepository) GetByUserID(userID uuid.UUID) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+// Touch records a sign-in with an identity and the email the provider reported
+func (r *UserIdentityRepository) Touch(id uuid.UUID, email string) error {
+	return r.db.Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": time.Now(), "email": email}).Error
+}
+
+// DeleteByUserID unlinks all identities of a user
+func (r *UserIdentityRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.UserIdentity{}).Error
+}
+
+// DeleteByIDAndUserID unlinks an identity of a user
+func (r *UserIdentityRepository) DeleteByIDAndUserID(id, userID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("identity not found")
+	}
+	return nil
+}
@@ -2,12 +2,33 @@ package repository
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/model"
 	"gorm.io/gorm"
 )
 
+// ErrUserNotFound is returned when no user matches
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrGuestLimitExceeded is returned when too many guest accounts were created from an IP address
+var ErrGuestLimitExceeded = errors.New("guest account limit exceeded")
+
+// UserFilter holds user listing parameters for administration
+type UserFilter struct {
+	Query   string // Matches email or username, case-insensitive
+	Role    string
+	Active  *bool
+	IsGuest *bool
+	Limit   int
+	Offset  int
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // UserRepository handles user data operations
 type UserRepository struct {
 	db *gorm.DB
@@ -29,7 +50,7 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*model.User, error) {
 	err := r.db.Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -42,7 +63,7 @@ func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -55,7 +76,7 @@ func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 	err := r.db.Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -67,6 +88,70 @@ func (r *UserRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
+// List retrieves users matching a filter, newest first, and the total number of matches
+func (r *UserRepository) List(filter UserFilter) ([]model.User, int64, error) {
+	query := r.db.Model(&model.User{})
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		query = query.Where("email ILIKE ? OR username ILIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Active != nil {
+		query = query.Where("is_active = ?", *filter.Active)
+	}
+	if filter.IsGuest != nil {
+		query = query.Where("is_guest = ?", *filter.IsGuest)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	return users, total, err
+}
+
+// CountByRole counts the users with a role
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// SetActive activates or deactivates a user
+func (r *UserRepository) SetActive(id uuid.UUID, active bool) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// SetRole assigns a role to a user
+func (r *UserRepository) SetRole(id uuid.UUID, role string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// SetRoleByEmails assigns a role to the users with the given emails
+// and returns the number of users changed
+func (r *UserRepository) SetRoleByEmails(emails []string, role string) (int64, error) {
+	result := r.db.Model(&model.User{}).
+		Where("email IN ? AND role <> ?", emails, role).
+		Update("role", role)
+	return result.RowsAffected, result.Error
+}
+
+// ResetGuest sets when a guest account expires and clears its message count
+func (r *UserRepository) ResetGuest(id uuid.UUID, expiresAt time.Time) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND is_guest = ?", id, true).
+		Updates(map[string]interface{}{
+			"expires_at":            expiresAt,
+			"messages_sent":         0,
+			"messages_window_start": nil,
+		}).Error
+}
+
 // UpdateLastLogin updates user's last login time
 func (r *UserRepository) UpdateLastLogin(userID uuid.UUID) error {
 	return r.db.Model(&model.User{}).
@@ -88,6 +173,52 @@ func (r *UserRepository) UsernameExists(username string) (bool, error) {
 	return count > 0, err
 }
 
+// CreateGuestWithinLimit creates a guest account unless max guest accounts were
+// already created from its signup IP since the given time. Creations from the
+// same IP are serialized with an advisory lock, so concurrent requests cannot
+// exceed the limit.
+func (r *UserRepository) CreateGuestWithinLimit(user *model.User, since time.Time, max int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "guest_ip:"+user.SignupIP).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&model.User{}).
+			Where("is_guest = ? AND signup_ip = ? AND created_at > ?", true, user.SignupIP, since).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count >= int64(max) {
+			return ErrGuestLimitExceeded
+		}
+
+		return tx.Create(user).Error
+	})
+}
+
+// IncrementGuestMessages counts a message sent by a guest account unless it
+// already sent max messages within the window. A window longer ago than
+// window is replaced by a new one starting now. It reports whether the
+// message was counted.
+func (r *UserRepository) IncrementGuestMessages(id uuid.UUID, max int, window time.Duration) (bool, error) {
+	now := time.Now()
+	windowEnded := now.Add(-window)
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND is_guest = ?", id, true).
+		Where("messages_window_start IS NULL OR messages_window_start <= ? OR messages_sent < ?", windowEnded, max).
+		UpdateColumns(map[string]interface{}{
+			"messages_sent": gorm.Expr(
+				"CASE WHEN messages_window_start IS NULL OR messages_window_start <= ? THEN 1 ELSE messages_sent + 1 END",
+				windowEnded),
+			"messages_window_start": gorm.Expr(
+				"CASE WHEN messages_window_start IS NULL OR messages_window_start <= ? THEN ? ELSE messages_window_start END",
+				windowEnded, now),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // DeleteExpiredGuests deletes expired guest accounts
 func (r *UserRepository) DeleteExpiredGuests() error {
 	return r.db.Where("is_guest = ? AND expires_at < NOW()", true).Delete(&model.User{}).Error
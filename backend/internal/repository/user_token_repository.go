@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrUserTokenInvalid is returned for unknown, expired or already used tokens
+var ErrUserTokenInvalid = errors.New("invalid or expired token")
+
+// UserTokenRepository handles emailed user token data operations
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create stores a new token
+func (r *UserTokenRepository) Create(token *model.UserToken) error {
+	return r.db.Omit("User").Create(token).Error
+}
+
+// GetValid retrieves an unused, unexpired token of a purpose without using it
+func (r *UserTokenRepository) GetValid(tokenHash, purpose string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, time.Now()).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Use marks an unused, unexpired token of a purpose as used and returns it.
+// The update is conditional, so a token can be used only once even by concurrent requests.
+func (r *UserTokenRepository) Use(tokenHash, purpose string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := r.db.Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserTokenInvalid
+	}
+
+	token.UsedAt = &now
+	return &token, nil
+}
+
+// InvalidateByUserID marks the unused tokens of a user with a purpose as used
+func (r *UserTokenRepository) InvalidateByUserID(userID uuid.UUID, purpose string) error {
+	return r.db.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+// DeleteExpired deletes tokens past their expiry
+func (r *UserTokenRepository) DeleteExpired() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&model.UserToken{}).Error
+}
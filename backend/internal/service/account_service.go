@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/mailer"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+// Frontend pages the emailed links open
+const (
+	resetPasswordPath = "/reset-password"
+	verifyEmailPath   = "/verify-email"
+)
+
+// accountTokenStore stores the hashes of emailed tokens
+type accountTokenStore interface {
+	Create(token *model.UserToken) error
+	Use(tokenHash, purpose string) (*model.UserToken, error)
+	InvalidateByUserID(userID uuid.UUID, purpose string) error
+	DeleteExpired() error
+}
+
+// AccountService handles the links emailed to users: email verification and password reset
+type AccountService struct {
+	userRepo  *repository.UserRepository
+	tokenRepo accountTokenStore
+	mailer    mailer.Mailer
+	cfg       config.AccountConfig
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.UserTokenRepository,
+	mail mailer.Mailer,
+	cfg *config.Config,
+) *AccountService {
+	return &AccountService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mail,
+		cfg:       cfg.Account,
+	}
+}
+
+// NewMailer creates the mailer selected in configuration
+func NewMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Backend {
+	case "log":
+		return mailer.NewLogMailer(cfg.Mail.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.Mail.FilePath, cfg.Mail.From)
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	default:
+		return nil, fmt.Errorf("unknown mail backend: %s", cfg.Mail.Backend)
+	}
+}
+
+// ForgotPassword emails a password reset link to the user with the email.
+// Unknown emails are ignored so the response does not reveal which emails are registered.
+func (s *AccountService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user.IsGuest || !user.IsActive {
+		return nil
+	}
+
+	token, err := s.createToken(user.ID, model.UserTokenPasswordReset, s.cfg.PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"Someone requested a password reset for your account. Open the link below to choose a new password:\n\n"+
+		"%s\n\n"+
+		"The link expires in %s. If you did not request a reset, ignore this email.\n",
+		user.Username, s.link(resetPasswordPath, token), s.cfg.PasswordResetExpiry)
+	s.send(user.Email, "Reset your password", body)
+	return nil
+}
+
+// UsePasswordResetToken consumes a password reset token and returns the ID of its user
+func (s *AccountService) UsePasswordResetToken(rawToken string) (uuid.UUID, error) {
+	token, err := s.tokenRepo.Use(utils.HashToken(rawToken), model.UserTokenPasswordReset)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return token.UserID, nil
+}
+
+// InvalidatePasswordResets invalidates the pending password reset links of a user
+func (s *AccountService) InvalidatePasswordResets(userID uuid.UUID) error {
+	return s.tokenRepo.InvalidateByUserID(userID, model.UserTokenPasswordReset)
+}
+
+// SendVerification emails an email verification link to a user, replacing earlier links
+func (s *AccountService) SendVerification(user *model.User) error {
+	if err := s.tokenRepo.InvalidateByUserID(user.ID, model.UserTokenEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := s.createToken(user.ID, model.UserTokenEmailVerification, s.cfg.EmailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"Please confirm your email address by opening the link below:\n\n"+
+		"%s\n\n"+
+		"The link expires in %s.\n",
+		user.Username, s.link(verifyEmailPath, token), s.cfg.EmailVerificationExpiry)
+	s.send(user.Email, "Confirm your email address", body)
+	return nil
+}
+
+// ResendVerification emails a new verification link to the user with the email.
+// Unknown and already verified emails are ignored so the response does not reveal them.
+func (s *AccountService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user.IsGuest || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.SendVerification(user)
+}
+
+// VerifyEmail marks the email of a user as verified using a verification token
+func (s *AccountService) VerifyEmail(rawToken string) error {
+	token, err := s.tokenRepo.Use(utils.HashToken(rawToken), model.UserTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return repository.ErrUserTokenInvalid
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// RequiresVerification reports whether a user may not sign in until their email is verified
+func (s *AccountService) RequiresVerification(user *model.User) bool {
+	return s.cfg.RequireEmailVerification && !user.IsGuest && user.EmailVerifiedAt == nil
+}
+
+// CleanupExpiredTokens removes password reset and verification tokens past their expiry
+func (s *AccountService) CleanupExpiredTokens() error {
+	return s.tokenRepo.DeleteExpired()
+}
+
+// createToken generates a token and stores its hash
+func (s *AccountService) createToken(userID uuid.UUID, purpose string, expiry time.Duration) (string, error) {
+	token := utils.GenerateRandomString(64)
+	if token == "" {
+		return "", errors.New("failed to generate token")
+	}
+
+	err := s.tokenRepo.Create(&model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(expiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// link builds a frontend URL carrying a token
+func (s *AccountService) link(path, token string) string {
+	return s.cfg.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// send sends an email. Failures are logged rather than returned: the token is
+// already stored and the user can request another email.
+func (s *AccountService) send(to, subject, body string) {
+	if err := s.mailer.Send(mailer.Message{To: to, Subject: subject, Body: body}); err != nil {
+		log.Printf("Warning: Could not send email to %s: %v", to, err)
+	}
+}
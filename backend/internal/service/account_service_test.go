@@ -0,0 +1,63 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/llmchatbot/backend/internal/config"
+)
+
+func TestAccountServiceLink(t *testing.T) {
+	s := &AccountService{cfg: config.AccountConfig{AppURL: "https://chat.example.com"}}
+
+	got := s.link(resetPasswordPath, "a+b/c=")
+	if want := "https://chat.example.com/reset-password?token=a%2Bb%2Fc%3D"; got != want {
+		t.Errorf("link() = %q, want %q", got, want)
+	}
+}
+
+func TestAccountServiceSendsLinkByFile(t *testing.T) {
+	dir := t.TempDir()
+	mail, err := NewMailer(&config.Config{Mail: config.MailConfig{Backend: "file", FilePath: dir, From: "noreply@example.com"}})
+	if err != nil {
+		t.Fatalf("NewMailer() error = %v", err)
+	}
+	s := &AccountService{mailer: mail, cfg: config.AccountConfig{AppURL: "https://chat.example.com"}}
+
+	link := s.link(verifyEmailPath, "token123")
+	s.send("user@example.com", "Confirm your email address", "Open "+link)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("found %d emails (%v), want 1", len(files), err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "https://chat.example.com/verify-email?token=token123") {
+		t.Errorf("email does not contain the link:\n%s", data)
+	}
+}
+
+func TestNewMailerUnknownBackend(t *testing.T) {
+	if _, err := NewMailer(&config.Config{Mail: config.MailConfig{Backend: "carrier-pigeon"}}); err == nil {
+		t.Error("NewMailer() accepted an unknown backend")
+	}
+}
+
+// The emailed links must open pages the frontend serves
+func TestAccountLinksHaveFrontendRoutes(t *testing.T) {
+	app, err := os.ReadFile(filepath.Join("..", "..", "..", "frontend", "src", "App.tsx"))
+	if err != nil {
+		t.Skipf("frontend sources not available: %v", err)
+	}
+
+	for _, path := range []string{resetPasswordPath, verifyEmailPath} {
+		if !strings.Contains(string(app), `path="`+path+`"`) {
+			t.Errorf("frontend has no route for %s", path)
+		}
+	}
+}
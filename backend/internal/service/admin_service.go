@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// ErrSelfAdministration is returned when administrators try to deactivate
+// themselves or change their own role, which could lock them out
+var ErrSelfAdministration = errors.New("cannot change your own account")
+
+// AdminService handles user administration
+type AdminService struct {
+	userRepo    *repository.UserRepository
+	roleService *RoleService
+	authService *AuthService
+	cfg         *config.Config
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(
+	userRepo *repository.UserRepository,
+	roleService *RoleService,
+	authService *AuthService,
+	cfg *config.Config,
+) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		roleService: roleService,
+		authService: authService,
+		cfg:         cfg,
+	}
+}
+
+// ListUsers lists and searches users
+func (s *AdminService) ListUsers(filter repository.UserFilter) (*dto.AdminUserListResponse, error) {
+	users, total, err := s.userRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AdminUserListResponse{
+		Users: make([]dto.AdminUserResponse, len(users)),
+		Total: total,
+	}
+	for i := range users {
+		response.Users[i] = toAdminUserResponse(&users[i])
+	}
+	return response, nil
+}
+
+// GetUser retrieves a user
+func (s *AdminService) GetUser(userID uuid.UUID) (*dto.AdminUserResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAdminUserResponse(user)
+	return &response, nil
+}
+
+// DeactivateUser blocks a user from signing in and ends all of their sessions
+func (s *AdminService) DeactivateUser(adminID, userID uuid.UUID) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, ErrSelfAdministration
+	}
+	if _, err := s.managedUser(adminID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetActive(userID, false); err != nil {
+		return nil, err
+	}
+	if err := s.authService.RevokeAllSessions(userID); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// ReactivateUser allows a deactivated user to sign in again
+func (s *AdminService) ReactivateUser(adminID, userID uuid.UUID) (*dto.AdminUserResponse, error) {
+	if _, err := s.managedUser(adminID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetActive(userID, true); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// ForceLogout ends all sessions of a user; the user can sign in again
+func (s *AdminService) ForceLogout(adminID, userID uuid.UUID) error {
+	if _, err := s.managedUser(adminID, userID); err != nil {
+		return err
+	}
+	return s.authService.RevokeAllSessions(userID)
+}
+
+// SetUserRole assigns a built-in or custom role to a registered user
+func (s *AdminService) SetUserRole(adminID, userID uuid.UUID, role string) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, ErrSelfAdministration
+	}
+
+	admin, err := s.userRepo.GetByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.roleService.CheckCanManage(admin, user); err != nil {
+		return nil, err
+	}
+	if user.IsGuest && role != model.RoleUser {
+		return nil, errors.New("roles cannot be assigned to guest accounts")
+	}
+
+	exists, err := s.roleService.RoleExists(role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("role %q does not exist", role)
+	}
+	if err := s.roleService.CheckCanAssign(admin, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetRole(userID, role); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// ResetGuestExpiration gives a guest account a full lifetime again, counted from now,
+// and clears its daily message count
+func (s *AdminService) ResetGuestExpiration(userID uuid.UUID) (*dto.AdminUserResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsGuest {
+		return nil, errors.New("user is not a guest account")
+	}
+
+	if err := s.userRepo.ResetGuest(userID, time.Now().Add(s.cfg.Guest.Lifetime)); err != nil {
+		return nil, err
+	}
+	return s.GetUser(userID)
+}
+
+// managedUser retrieves a user the acting user may manage
+func (s *AdminService) managedUser(adminID, userID uuid.UUID) (*model.User, error) {
+	admin, err := s.userRepo.GetByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.roleService.CheckCanManage(admin, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// toAdminUserResponse converts a user to its administration response
+func toAdminUserResponse(user *model.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		Username:      user.Username,
+		Role:          user.Role,
+		IsActive:      user.IsActive,
+		IsGuest:       user.IsGuest,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
+		ExpiresAt:     user.ExpiresAt,
+	}
+}
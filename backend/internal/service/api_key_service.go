@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+// ErrInvalidAPIKey is returned for unknown, expired or unusable API keys
+var ErrInvalidAPIKey = errors.New("invalid or expired API key")
+
+const (
+	// apiKeySecretLength is the number of random hex characters after the key prefix
+	apiKeySecretLength = 48
+	// apiKeyDisplayLength is the number of random characters kept in the displayed prefix
+	apiKeyDisplayLength = 8
+	// apiKeyTouchInterval limits how often the last use of a key is written
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyScopes are the scopes a key can be created with
+var apiKeyScopes = []string{model.APIKeyScopeChatsRead, model.APIKeyScopeChatsWrite, model.APIKeyScopeStream}
+
+// APIKeyService handles personal API keys
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	cfg        config.APIKeyConfig
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, cfg *config.Config) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		cfg:        cfg.APIKey,
+	}
+}
+
+// CreateAPIKey creates an API key for a user. The response carries the full
+// key, which is not stored and cannot be shown again.
+func (s *APIKeyService) CreateAPIKey(userID uuid.UUID, req *dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	count, err := s.apiKeyRepo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.MaxPerUser > 0 && count >= int64(s.cfg.MaxPerUser) {
+		return nil, fmt.Errorf("API key limit of %d reached", s.cfg.MaxPerUser)
+	}
+
+	secret := utils.GenerateRandomString(apiKeySecretLength)
+	key := model.APIKeyPrefix + secret
+	apiKey := &model.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    model.APIKeyPrefix + secret[:apiKeyDisplayLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	response := toAPIKeyResponse(apiKey)
+	response.Key = key
+	return &response, nil
+}
+
+// GetAPIKeys lists the API keys of a user
+func (s *APIKeyService) GetAPIKeys(userID uuid.UUID) ([]dto.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i := range keys {
+		responses[i] = toAPIKeyResponse(&keys[i])
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey deletes an API key of a user; requests with it are rejected at once
+func (s *APIKeyService) RevokeAPIKey(userID, keyID uuid.UUID) error {
+	return s.apiKeyRepo.DeleteByIDAndUserID(keyID, userID)
+}
+
+// Authenticate resolves an API key to the key and its user. Keys of
+// deactivated and guest accounts are rejected.
+func (s *APIKeyService) Authenticate(key string) (*model.APIKey, error) {
+	if !strings.HasPrefix(key, model.APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(utils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if apiKey.IsExpired() || !apiKey.User.IsActive || apiKey.User.IsGuest {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.Touch(apiKey.ID); err != nil {
+			// Log error but don't fail the request
+			log.Printf("Warning: Could not update API key %s: %v", apiKey.ID, err)
+		}
+	}
+
+	return apiKey, nil
+}
+
+// normalizeAPIKeyScopes checks requested scopes and removes duplicates
+func normalizeAPIKeyScopes(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !isAPIKeyScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q: expected one of %s", scope, strings.Join(apiKeyScopes, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// isAPIKeyScope reports whether a key can be created with a scope
+func isAPIKeyScope(scope string) bool {
+	for _, known := range apiKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// toAPIKeyResponse converts an API key to its response without the full key
+func toAPIKeyResponse(key *model.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+		IsExpired:  key.IsExpired(),
+	}
+}
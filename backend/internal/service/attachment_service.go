@@ -0,0 +1,342 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/blobstore"
+)
+
+// AttachmentService handles message attachment business logic
+type AttachmentService struct {
+	attachmentRepo *repository.AttachmentRepository
+	chatRepo       *repository.ChatRepository
+	store          blobstore.Store
+	modelRegistry  *ModelRegistry
+	images         *imageCache
+	cfg            config.StorageConfig
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(
+	attachmentRepo *repository.AttachmentRepository,
+	chatRepo *repository.ChatRepository,
+	store blobstore.Store,
+	modelRegistry *ModelRegistry,
+	cfg *config.Config,
+) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		chatRepo:       chatRepo,
+		store:          store,
+		modelRegistry:  modelRegistry,
+		images:         newImageCache(imageCacheSize),
+		cfg:            cfg.Storage,
+	}
+}
+
+// NewBlobStore creates the blob store selected in configuration
+func NewBlobStore(cfg *config.Config) (blobstore.Store, error) {
+	switch cfg.Storage.Backend {
+	case "local":
+		return blobstore.NewLocalStore(cfg.Storage.LocalPath)
+	case "s3":
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:     cfg.Storage.S3Endpoint,
+			Region:       cfg.Storage.S3Region,
+			Bucket:       cfg.Storage.S3Bucket,
+			AccessKey:    cfg.Storage.S3AccessKey,
+			SecretKey:    cfg.Storage.S3SecretKey,
+			UsePathStyle: cfg.Storage.S3UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Storage.Backend)
+	}
+}
+
+// UploadAttachment stores a file uploaded to a chat session.
+// The attachment is linked to the next user message that references it.
+func (s *AttachmentService) UploadAttachment(userID, sessionID uuid.UUID, filename string, data []byte) (*dto.AttachmentResponse, error) {
+	// Verify chat session exists and belongs to user
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return nil, errors.New("chat session not found")
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if int64(len(data)) > s.cfg.MaxFileSize {
+		return nil, fmt.Errorf("file exceeds maximum size of %d bytes", s.cfg.MaxFileSize)
+	}
+
+	// Reject uploads that cannot fit early, before storing the file;
+	// CreateWithinQuota enforces the quota atomically
+	used, err := s.attachmentRepo.TotalSizeByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if used+int64(len(data)) > s.cfg.UserQuota {
+		return nil, repository.ErrQuotaExceeded
+	}
+
+	filename = filepath.Base(filename)
+	if len(filename) > 255 {
+		filename = filename[len(filename)-255:]
+	}
+
+	checksum := sha256.Sum256(data)
+	attachment := &model.Attachment{
+		ID:            uuid.New(),
+		UserID:        userID,
+		ChatSessionID: &sessionID,
+		Filename:      filename,
+		MimeType:      detectMimeType(filename, data),
+		Size:          int64(len(data)),
+		Checksum:      hex.EncodeToString(checksum[:]),
+	}
+	attachment.StorageKey = fmt.Sprintf("attachments/%s/%s", userID, attachment.ID)
+
+	if err := s.store.Put(attachment.StorageKey, data, attachment.MimeType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	if err := s.attachmentRepo.CreateWithinQuota(attachment, s.cfg.UserQuota); err != nil {
+		_ = s.store.Delete(attachment.StorageKey)
+		return nil, err
+	}
+
+	return toAttachmentResponse(attachment), nil
+}
+
+// GetAttachments retrieves all attachments of a chat session
+func (s *AttachmentService) GetAttachments(sessionID, userID uuid.UUID) ([]dto.AttachmentResponse, error) {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return nil, errors.New("chat session not found")
+	}
+
+	attachments, err := s.attachmentRepo.GetByChatSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAttachmentResponses(attachments), nil
+}
+
+// GetAttachment retrieves attachment metadata by ID
+func (s *AttachmentService) GetAttachment(attachmentID, userID uuid.UUID) (*dto.AttachmentResponse, error) {
+	attachment, err := s.attachmentRepo.GetByIDAndUserID(attachmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAttachmentResponse(attachment), nil
+}
+
+// OpenAttachment retrieves attachment metadata and opens its content
+func (s *AttachmentService) OpenAttachment(attachmentID, userID uuid.UUID) (*dto.AttachmentResponse, io.ReadCloser, error) {
+	attachment, err := s.attachmentRepo.GetByIDAndUserID(attachmentID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Get(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, errors.New("attachment content not found")
+		}
+		return nil, nil, err
+	}
+
+	return toAttachmentResponse(attachment), content, nil
+}
+
+// ValidatePending checks that attachments belong to the chat session and were not sent yet.
+// Image attachments are only accepted for vision-capable models.
+func (s *AttachmentService) ValidatePending(userID, sessionID uuid.UUID, modelName string, attachmentIDs []uuid.UUID) error {
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+
+	attachments, err := s.attachmentRepo.GetPending(attachmentIDs, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if len(attachments) != len(attachmentIDs) {
+		return errors.New("attachment not found or already sent")
+	}
+
+	for _, attachment := range attachments {
+		if isImageMimeType(attachment.MimeType) && !s.modelRegistry.SupportsImages(modelName) {
+			return fmt.Errorf("model %s does not support image inputs: %s", modelName, attachment.Filename)
+		}
+	}
+
+	return nil
+}
+
+// LoadImage reads an image attachment and encodes it for the LLM service.
+// Recently loaded images are served from memory.
+func (s *AttachmentService) LoadImage(attachmentID, userID uuid.UUID) (*ImageInput, error) {
+	if image, ok := s.images.get(attachmentID, userID); ok {
+		return image, nil
+	}
+
+	attachment, content, err := s.OpenAttachment(attachmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	if !isImageMimeType(attachment.MimeType) {
+		return nil, errors.New("attachment is not an image")
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	image := &ImageInput{
+		MimeType: attachment.MimeType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+	}
+	s.images.put(attachmentID, userID, image)
+	return image, nil
+}
+
+// DeleteAttachment permanently deletes an attachment and its content
+func (s *AttachmentService) DeleteAttachment(attachmentID, userID uuid.UUID) error {
+	attachment, err := s.attachmentRepo.GetByIDAndUserID(attachmentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Delete(attachment.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	s.images.remove(attachment.ID)
+
+	return s.attachmentRepo.Delete(attachment.ID)
+}
+
+// GetStorageUsage returns attachment storage usage and quota of a user
+func (s *AttachmentService) GetStorageUsage(userID uuid.UUID) (*dto.StorageUsageResponse, error) {
+	used, err := s.attachmentRepo.TotalSizeByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.StorageUsageResponse{
+		UsedBytes:   used,
+		QuotaBytes:  s.cfg.UserQuota,
+		MaxFileSize: s.cfg.MaxFileSize,
+	}, nil
+}
+
+// CleanupOrphaned removes attachments (records and content) whose chat session was deleted
+func (s *AttachmentService) CleanupOrphaned() error {
+	for {
+		attachments, err := s.attachmentRepo.GetOrphaned(100)
+		if err != nil {
+			return err
+		}
+		if len(attachments) == 0 {
+			return nil
+		}
+
+		if err := s.removeAttachments(attachments); err != nil {
+			return err
+		}
+	}
+}
+
+// sessionAttachments retrieves the attachments of a user's chat sessions,
+// so they can be removed once the sessions are deleted
+func (s *AttachmentService) sessionAttachments(sessionIDs []uuid.UUID, userID uuid.UUID) ([]model.Attachment, error) {
+	return s.attachmentRepo.GetByChatSessionIDs(sessionIDs, userID)
+}
+
+// messageAttachments retrieves the attachments sent with a message,
+// so they can be removed once the message is deleted
+func (s *AttachmentService) messageAttachments(messageID uuid.UUID) ([]model.Attachment, error) {
+	return s.attachmentRepo.GetByMessageID(messageID)
+}
+
+// removeAttachments deletes attachments (records and content)
+func (s *AttachmentService) removeAttachments(attachments []model.Attachment) error {
+	for _, attachment := range attachments {
+		if err := s.store.Delete(attachment.StorageKey); err != nil {
+			// Keep the record so the blob is retried on the next cleanup run
+			return fmt.Errorf("failed to delete attachment %s: %w", attachment.ID, err)
+		}
+		s.images.remove(attachment.ID)
+		if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectMimeType determines the MIME type of an attachment from its name and content
+func detectMimeType(filename string, data []byte) string {
+	mimeType := mime.TypeByExtension(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+// isImageMimeType reports whether a MIME type is an image format accepted by vision models
+func isImageMimeType(mimeType string) bool {
+	switch strings.ToLower(mimeType) {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// toAttachmentResponse converts an Attachment model to response DTO
+func toAttachmentResponse(attachment *model.Attachment) *dto.AttachmentResponse {
+	response := &dto.AttachmentResponse{
+		ID:        attachment.ID.String(),
+		Filename:  attachment.Filename,
+		MimeType:  attachment.MimeType,
+		Size:      attachment.Size,
+		Checksum:  attachment.Checksum,
+		CreatedAt: attachment.CreatedAt,
+	}
+	if attachment.ChatSessionID != nil {
+		response.ChatSessionID = attachment.ChatSessionID.String()
+	}
+	if attachment.MessageID != nil {
+		response.MessageID = attachment.MessageID.String()
+	}
+	return response
+}
+
+// toAttachmentResponses converts Attachment models to response DTOs
+func toAttachmentResponses(attachments []model.Attachment) []dto.AttachmentResponse {
+	responses := make([]dto.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = *toAttachmentResponse(&attachments[i])
+	}
+	return responses
+}
@@ -2,25 +2,90 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/config"
 	"github.com/llmchatbot/backend/internal/dto"
 	"github.com/llmchatbot/backend/internal/model"
 	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/denylist"
 	"github.com/llmchatbot/backend/pkg/jwt"
 	"github.com/llmchatbot/backend/pkg/utils"
 )
 
+// maxUserAgentLength is the stored length of a session user agent
+const maxUserAgentLength = 512
+
+// ErrEmailNotVerified is returned on sign-in when email verification is required
+// and the user has not verified their email yet
+var ErrEmailNotVerified = errors.New("email address is not verified")
+
+// ClientInfo describes the client a login session is used from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// authUserStore stores the users signing in
+type authUserStore interface {
+	Create(user *model.User) error
+	CreateGuestWithinLimit(user *model.User, since time.Time, max int) error
+	GetByID(id uuid.UUID) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	Update(user *model.User) error
+	UpdateLastLogin(userID uuid.UUID) error
+	EmailExists(email string) (bool, error)
+	UsernameExists(username string) (bool, error)
+	DeleteExpiredGuests() error
+}
+
+// refreshTokenStore stores the refresh tokens of login sessions
+type refreshTokenStore interface {
+	Create(token *model.RefreshToken) error
+	GetByHash(tokenHash string) (*model.RefreshToken, error)
+	Rotate(id uuid.UUID, next *model.RefreshToken) error
+	RevokeFamily(familyID uuid.UUID) error
+	RevokeByUserID(userID uuid.UUID) error
+	DeleteExpired() error
+}
+
+// authSessionStore stores login sessions
+type authSessionStore interface {
+	Create(session *model.AuthSession) error
+	GetActiveByIDAndUserID(id, userID uuid.UUID) (*model.AuthSession, error)
+	GetActiveByUserID(userID uuid.UUID) ([]model.AuthSession, error)
+	Touch(id uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) error
+	Revoke(ids ...uuid.UUID) error
+	RevokeByUserID(userID uuid.UUID) error
+	DeleteExpired() error
+}
+
 // AuthService handles authentication business logic
 type AuthService struct {
-	userRepo *repository.UserRepository
-	jwtMgr   *jwt.Manager
-	cfg      *config.Config
+	userRepo         authUserStore
+	refreshTokenRepo refreshTokenStore
+	sessionRepo      authSessionStore
+	accountService   *AccountService
+	mfaService       *MFAService
+	denylist         denylist.Store
+	jwtMgr           *jwt.Manager
+	cfg              *config.Config
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo *repository.UserRepository, cfg *config.Config) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	refreshTokenRepo *repository.RefreshTokenRepository,
+	sessionRepo *repository.AuthSessionRepository,
+	accountService *AccountService,
+	mfaService *MFAService,
+	deny denylist.Store,
+	cfg *config.Config,
+) *AuthService {
 	jwtMgr := jwt.NewManager(
 		cfg.JWT.SecretKey,
 		cfg.JWT.AccessTokenExpiry,
@@ -28,31 +93,40 @@ func NewAuthService(userRepo *repository.UserRepository, cfg *config.Config) *Au
 	)
 
 	return &AuthService{
-		userRepo: userRepo,
-		jwtMgr:   jwtMgr,
-		cfg:      cfg,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		accountService:   accountService,
+		mfaService:       mfaService,
+		denylist:         deny,
+		jwtMgr:           jwtMgr,
+		cfg:              cfg,
 	}
 }
 
-// Register registers a new user
-func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
-	// Check if email already exists
-	emailExists, err := s.userRepo.EmailExists(req.Email)
-	if err != nil {
-		return nil, err
-	}
-	if emailExists {
-		return nil, errors.New("email already exists")
+// NewDenylist creates the revoked access token store selected in configuration
+func NewDenylist(cfg *config.Config) (denylist.Store, error) {
+	switch cfg.JWT.DenylistBackend {
+	case "memory":
+		return denylist.NewMemoryStore(), nil
+	case "redis":
+		return denylist.NewRedisStore(denylist.RedisConfig{
+			Addr:     net.JoinHostPort(cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+	default:
+		return nil, fmt.Errorf("unknown token denylist backend: %s", cfg.JWT.DenylistBackend)
 	}
+}
 
-	// Check if username already exists
-	usernameExists, err := s.userRepo.UsernameExists(req.Username)
-	if err != nil {
+// Register registers a new user and emails them a verification link.
+// When email verification is required no tokens are issued and the response is nil.
+func (s *AuthService) Register(req *dto.RegisterRequest, client ClientInfo) (*dto.AuthResponse, error) {
+	// Check if email or username already exists
+	if err := s.checkCredentialsAvailable(req.Email, req.Username); err != nil {
 		return nil, err
 	}
-	if usernameExists {
-		return nil, errors.New("username already exists")
-	}
 
 	// Hash password
 	passwordHash, err := utils.HashPassword(req.Password)
@@ -66,33 +140,26 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 		Username:     req.Username,
 		PasswordHash: passwordHash,
 		IsActive:     true,
+		SignupIP:     client.IPAddress,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
+	if err := s.accountService.SendVerification(user); err != nil {
+		// Log error but don't fail: the user can request another link
+		log.Printf("Warning: Could not send verification email to user %s: %v", user.ID, err)
 	}
-
-	refreshToken, err := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
-	if err != nil {
-		return nil, err
+	if s.accountService.RequiresVerification(user) {
+		return nil, nil
 	}
 
-	return &dto.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
-	}, nil
+	return s.SignIn(user, client)
 }
 
 // Login authenticates a user and returns tokens
-func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
+func (s *AuthService) Login(req *dto.LoginRequest, client ClientInfo) (*dto.AuthResponse, error) {
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
@@ -109,35 +176,126 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 		return nil, errors.New("invalid credentials")
 	}
 
-	// Update last login
-	now := time.Now()
-	user.LastLoginAt = &now
-	if err := s.userRepo.Update(user); err != nil {
-		// Log error but don't fail login
-		_ = s.userRepo.UpdateLastLogin(user.ID)
+	return s.SignIn(user, client)
+}
+
+// SignIn starts a login session for a user whose credentials were checked,
+// by password or by single sign-on. Users with two-factor authentication, or
+// whose role requires it, get an MFA challenge instead of tokens.
+func (s *AuthService) SignIn(user *model.User, client ClientInfo) (*dto.AuthResponse, error) {
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+	if s.accountService.RequiresVerification(user) {
+		return nil, ErrEmailNotVerified
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, user.Email)
+	mfaEnabled, err := s.mfaService.Enabled(user.ID)
 	if err != nil {
 		return nil, err
 	}
+	if mfaEnabled || s.mfaService.Required(user) {
+		purpose := model.UserTokenMFAChallenge
+		if !mfaEnabled {
+			purpose = model.UserTokenMFAEnrollment
+		}
+		mfaToken, err := s.mfaService.NewChallenge(user.ID, purpose)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.AuthResponse{
+			ExpiresIn:             int(s.mfaService.ChallengeExpiry().Seconds()),
+			MFARequired:           true,
+			MFAEnrollmentRequired: !mfaEnabled,
+			MFAToken:              mfaToken,
+		}, nil
+	}
 
-	refreshToken, err := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
+	return s.completeSignIn(user, client)
+}
+
+// VerifyMFA completes a sign-in with a TOTP or recovery code
+func (s *AuthService) VerifyMFA(req *dto.MFAVerifyRequest, client ClientInfo) (*dto.AuthResponse, error) {
+	userID, err := s.mfaService.CheckChallenge(req.MFAToken, model.UserTokenMFAChallenge)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.mfaService.VerifyCode(userID, req.Code); err != nil {
+		return nil, err
+	}
+	if err := s.mfaService.CompleteChallenge(req.MFAToken, model.UserTokenMFAChallenge); err != nil {
+		return nil, err
+	}
 
-	return &dto.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
-	}, nil
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+	return s.completeSignIn(user, client)
+}
+
+// BeginMFAEnrollment sets up an authenticator for a user whose role requires
+// two-factor authentication, during sign-in
+func (s *AuthService) BeginMFAEnrollment(req *dto.MFAEnrollRequest) (*dto.TOTPEnrollmentResponse, error) {
+	userID, err := s.mfaService.CheckChallenge(req.MFAToken, model.UserTokenMFAEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfaService.BeginEnrollment(userID)
+}
+
+// ConfirmMFAEnrollment confirms the authenticator set up during sign-in and
+// completes the sign-in. The response carries the new recovery codes.
+func (s *AuthService) ConfirmMFAEnrollment(req *dto.MFAEnrollConfirmRequest, client ClientInfo) (*dto.AuthResponse, error) {
+	userID, err := s.mfaService.CheckChallenge(req.MFAToken, model.UserTokenMFAEnrollment)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := s.mfaService.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaService.CompleteChallenge(req.MFAToken, model.UserTokenMFAEnrollment); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	response, err := s.completeSignIn(user, client)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// completeSignIn records the login and starts a login session
+func (s *AuthService) completeSignIn(user *model.User, client ClientInfo) (*dto.AuthResponse, error) {
+	// Update last login
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		// Log error but don't fail login
+		_ = s.userRepo.UpdateLastLogin(user.ID)
+	}
+
+	// Generate tokens for a new login session
+	return s.startSession(user, client)
 }
 
-// RefreshToken refreshes an access token using a refresh token
-func (s *AuthService) RefreshToken(refreshToken string) (*dto.AuthResponse, error) {
+// RefreshToken exchanges a refresh token for new tokens. The refresh token is
+// rotated: it can be used once, and presenting it again revokes its whole family,
+// which is the login session of the token.
+func (s *AuthService) RefreshToken(refreshToken string, client ClientInfo) (*dto.AuthResponse, error) {
 	// Validate refresh token
 	claims, err := s.jwtMgr.ValidateToken(refreshToken)
 	if err != nil {
@@ -149,6 +307,15 @@ func (s *AuthService) RefreshToken(refreshToken string) (*dto.AuthResponse, erro
 		return nil, errors.New("invalid token type")
 	}
 
+	// Refresh tokens are only valid while stored and not revoked
+	stored, err := s.refreshTokenRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil || stored.UserID != claims.UserID || stored.RevokedAt != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedSession(stored.FamilyID)
+	}
+
 	// Get user to verify they still exist and are active
 	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
@@ -159,17 +326,30 @@ func (s *AuthService) RefreshToken(refreshToken string) (*dto.AuthResponse, erro
 		return nil, errors.New("account is deactivated")
 	}
 
-	// Generate new tokens
-	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, user.Email)
+	// Generate new tokens in the same family
+	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, stored.FamilyID, user.Email, user.IsGuest)
 	if err != nil {
 		return nil, err
 	}
 
-	newRefreshToken, err := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
+	newRefreshToken, next, err := s.newRefreshToken(user, stored.FamilyID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.refreshTokenRepo.Rotate(stored.ID, next); err != nil {
+		// Lost a race with another refresh of the same token
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			return nil, s.revokeReusedSession(stored.FamilyID)
+		}
+		return nil, err
+	}
+
+	if err := s.sessionRepo.Touch(stored.FamilyID, truncateUserAgent(client.UserAgent), client.IPAddress, next.ExpiresAt); err != nil {
+		// Log error but don't fail the refresh
+		log.Printf("Warning: Could not update login session %s: %v", stored.FamilyID, err)
+	}
+
 	return &dto.AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
@@ -178,6 +358,104 @@ func (s *AuthService) RefreshToken(refreshToken string) (*dto.AuthResponse, erro
 	}, nil
 }
 
+// Logout ends the login session of the access token or the refresh token and
+// revokes the access token. Invalid or unknown tokens are ignored since there
+// is nothing to revoke.
+func (s *AuthService) Logout(accessToken, refreshToken string) error {
+	if accessToken != "" {
+		if claims, err := s.ValidateToken(accessToken); err == nil {
+			if err := s.denylist.Revoke(claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+				return err
+			}
+			if claims.SessionID != uuid.Nil {
+				if err := s.revokeSession(claims.SessionID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	claims, err := s.jwtMgr.ValidateToken(refreshToken)
+	if err != nil || claims.TokenType != "refresh" {
+		return nil
+	}
+
+	stored, err := s.refreshTokenRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil || stored.UserID != claims.UserID {
+		return nil
+	}
+
+	return s.revokeSession(stored.FamilyID)
+}
+
+// RevokeAllSessions signs a user out everywhere: refresh tokens are revoked and
+// access tokens issued so far are denied. Used when the password changes or
+// the account is deactivated.
+func (s *AuthService) RevokeAllSessions(userID uuid.UUID) error {
+	if err := s.refreshTokenRepo.RevokeByUserID(userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeByUserID(userID); err != nil {
+		return err
+	}
+	return s.denylist.RevokeUser(userID.String(), time.Now(), s.cfg.JWT.AccessTokenExpiry)
+}
+
+// GetSessions lists the active login sessions of a user, marking the current one
+func (s *AuthService) GetSessions(userID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = dto.SessionResponse{
+			ID:         session.ID.String(),
+			Device:     describeDevice(session.UserAgent),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			IsCurrent:  session.ID == currentSessionID,
+		}
+	}
+	return responses, nil
+}
+
+// RevokeSession signs a user out of one login session
+func (s *AuthService) RevokeSession(userID, sessionID uuid.UUID) error {
+	if _, err := s.sessionRepo.GetActiveByIDAndUserID(sessionID, userID); err != nil {
+		return err
+	}
+	return s.revokeSession(sessionID)
+}
+
+// RevokeOtherSessions signs a user out of all login sessions except the current one
+// and returns the number of revoked sessions
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := s.revokeSession(session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
 // ValidateToken validates a JWT token and returns claims
 func (s *AuthService) ValidateToken(tokenString string) (*jwt.Claims, error) {
 	claims, err := s.jwtMgr.ValidateToken(tokenString)
@@ -198,8 +476,13 @@ func (s *AuthService) GetJWTManager() *jwt.Manager {
 	return s.jwtMgr
 }
 
+// GetDenylist returns the revoked access token store (for middleware)
+func (s *AuthService) GetDenylist() denylist.Store {
+	return s.denylist
+}
+
 // CreateGuestSession creates a temporary guest account
-func (s *AuthService) CreateGuestSession() (*dto.GuestSessionResponse, error) {
+func (s *AuthService) CreateGuestSession(client ClientInfo) (*dto.GuestSessionResponse, error) {
 	// Generate unique guest credentials
 	guestID := "guest_" + time.Now().Format("20060102150405") + "_" + utils.GenerateRandomString(8)
 	guestEmail := guestID + "@guest.local"
@@ -210,8 +493,7 @@ func (s *AuthService) CreateGuestSession() (*dto.GuestSessionResponse, error) {
 		return nil, err
 	}
 
-	// Set expiration to 24 hours from now
-	expiresAt := time.Now().Add(24 * time.Hour)
+	expiresAt := time.Now().Add(s.cfg.Guest.Lifetime)
 
 	// Create guest user
 	user := &model.User{
@@ -221,33 +503,257 @@ func (s *AuthService) CreateGuestSession() (*dto.GuestSessionResponse, error) {
 		IsActive:     true,
 		IsGuest:      true,
 		ExpiresAt:    &expiresAt,
+		SignupIP:     client.IPAddress,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	// Limit guest accounts created from one IP address
+	if s.cfg.Guest.MaxPerIP > 0 {
+		err = s.userRepo.CreateGuestWithinLimit(user, time.Now().Add(-s.cfg.Guest.IPWindow), s.cfg.Guest.MaxPerIP)
+	} else {
+		err = s.userRepo.Create(user)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrGuestLimitExceeded) {
+			return nil, ErrGuestLimitReached
+		}
 		return nil, err
 	}
 
 	// Generate tokens
-	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, user.Email)
+	tokens, err := s.startSession(user, client)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
+	return &dto.GuestSessionResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    tokens.TokenType,
+		ExpiresIn:    tokens.ExpiresIn,
+		GuestID:      user.ID.String(),
+	}, nil
+}
+
+// UpgradeGuest converts a guest account into a registered account, keeping its chats.
+// The guest's login sessions are ended and tokens for a new session are returned,
+// unless email verification is required, in which case the response is nil.
+func (s *AuthService) UpgradeGuest(userID uuid.UUID, req *dto.UpgradeGuestRequest, client ClientInfo) (*dto.AuthResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		return nil, err
 	}
+	if !user.IsGuest {
+		return nil, errors.New("account is not a guest account")
+	}
 
-	return &dto.GuestSessionResponse{
+	// Check if email or username already exists
+	if err := s.checkCredentialsAvailable(req.Email, req.Username); err != nil {
+		return nil, err
+	}
+
+	// Hash password
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.Email = req.Email
+	user.Username = req.Username
+	user.PasswordHash = passwordHash
+	user.IsGuest = false
+	user.ExpiresAt = nil
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// Guest tokens carry the old email, so replace them with a new session
+	if err := s.RevokeAllSessions(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.accountService.SendVerification(user); err != nil {
+		// Log error but don't fail: the user can request another link
+		log.Printf("Warning: Could not send verification email to user %s: %v", user.ID, err)
+	}
+	if s.accountService.RequiresVerification(user) {
+		return nil, nil
+	}
+	return s.SignIn(user, client)
+}
+
+// ChangePassword changes the password of a user after checking the current one.
+// All login sessions are ended and tokens for a new session are returned.
+func (s *AuthService) ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest, client ClientInfo) (*dto.AuthResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsGuest {
+		return nil, errors.New("guest accounts have no password")
+	}
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.PasswordHash) {
+		return nil, errors.New("current password is incorrect")
+	}
+
+	if err := s.setPassword(user, req.NewPassword); err != nil {
+		return nil, err
+	}
+	return s.startSession(user, client)
+}
+
+// ResetPassword sets a new password using an emailed password reset token and
+// ends all login sessions of the user
+func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
+	userID, err := s.accountService.UsePasswordResetToken(req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return repository.ErrUserTokenInvalid
+	}
+
+	// The reset link proves access to the mailbox
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return s.setPassword(user, req.NewPassword)
+}
+
+// CleanupExpiredGuests removes expired guest accounts
+func (s *AuthService) CleanupExpiredGuests() error {
+	return s.userRepo.DeleteExpiredGuests()
+}
+
+// CleanupExpiredSessions removes login sessions and refresh tokens past their expiry
+func (s *AuthService) CleanupExpiredSessions() error {
+	if err := s.refreshTokenRepo.DeleteExpired(); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteExpired()
+}
+
+// setPassword stores a new password of a user, invalidates pending password
+// reset links and signs the user out everywhere
+func (s *AuthService) setPassword(user *model.User, password string) error {
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	if err := s.accountService.InvalidatePasswordResets(user.ID); err != nil {
+		return err
+	}
+	return s.RevokeAllSessions(user.ID)
+}
+
+// checkCredentialsAvailable checks that no user has the email or the username
+func (s *AuthService) checkCredentialsAvailable(email, username string) error {
+	emailExists, err := s.userRepo.EmailExists(email)
+	if err != nil {
+		return err
+	}
+	if emailExists {
+		return errors.New("email already exists")
+	}
+
+	usernameExists, err := s.userRepo.UsernameExists(username)
+	if err != nil {
+		return err
+	}
+	if usernameExists {
+		return errors.New("username already exists")
+	}
+	return nil
+}
+
+// startSession creates a login session and generates its first tokens
+func (s *AuthService) startSession(user *model.User, client ClientInfo) (*dto.AuthResponse, error) {
+	now := time.Now()
+	session := &model.AuthSession{
+		UserID:     user.ID,
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.cfg.JWT.RefreshTokenExpiry),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID)
+}
+
+// issueTokens generates an access token and a refresh token of the given family
+func (s *AuthService) issueTokens(user *model.User, familyID uuid.UUID) (*dto.AuthResponse, error) {
+	accessToken, err := s.jwtMgr.GenerateAccessToken(user.ID, familyID, user.Email, user.IsGuest)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, stored, err := s.newRefreshToken(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshTokenRepo.Create(stored); err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
-		GuestID:      user.ID.String(),
 	}, nil
 }
 
-// CleanupExpiredGuests removes expired guest accounts
-func (s *AuthService) CleanupExpiredGuests() error {
-	return s.userRepo.DeleteExpiredGuests()
+// newRefreshToken generates a refresh token and its record to store
+func (s *AuthService) newRefreshToken(user *model.User, familyID uuid.UUID) (string, *model.RefreshToken, error) {
+	refreshToken, err := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refreshToken, &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.cfg.JWT.RefreshTokenExpiry),
+	}, nil
+}
+
+// revokeSession revokes the refresh tokens and the access tokens of a login session
+func (s *AuthService) revokeSession(sessionID uuid.UUID) error {
+	if err := s.refreshTokenRepo.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	return s.denylist.RevokeSession(sessionID.String(), s.cfg.JWT.AccessTokenExpiry)
+}
+
+// revokeReusedSession revokes a login session after one of its rotated refresh
+// tokens was presented again, which means the token may have been stolen
+func (s *AuthService) revokeReusedSession(sessionID uuid.UUID) error {
+	if err := s.revokeSession(sessionID); err != nil {
+		return err
+	}
+	return errors.New("refresh token reuse detected")
+}
+
+// truncateUserAgent limits a user agent to the stored length
+func truncateUserAgent(userAgent string) string {
+	if runes := []rune(userAgent); len(runes) > maxUserAgentLength {
+		return string(runes[:maxUserAgentLength])
+	}
+	return userAgent
 }
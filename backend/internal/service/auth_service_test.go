@@ -0,0 +1,555 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/denylist"
+	"github.com/llmchatbot/backend/pkg/jwt"
+	"github.com/llmchatbot/backend/pkg/mailer"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+// fakeAuthUsers keeps users in memory
+type fakeAuthUsers map[uuid.UUID]*model.User
+
+func (f fakeAuthUsers) Create(user *model.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	f[user.ID] = user
+	return nil
+}
+
+func (f fakeAuthUsers) CreateGuestWithinLimit(user *model.User, since time.Time, max int) error {
+	return f.Create(user)
+}
+
+func (f fakeAuthUsers) GetByID(id uuid.UUID) (*model.User, error) {
+	if user, ok := f[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f fakeAuthUsers) GetByEmail(email string) (*model.User, error) {
+	for _, user := range f {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f fakeAuthUsers) Update(user *model.User) error {
+	f[user.ID] = user
+	return nil
+}
+
+func (f fakeAuthUsers) UpdateLastLogin(userID uuid.UUID) error { return nil }
+
+func (f fakeAuthUsers) EmailExists(email string) (bool, error) {
+	_, err := f.GetByEmail(email)
+	return err == nil, nil
+}
+
+func (f fakeAuthUsers) UsernameExists(username string) (bool, error) {
+	for _, user := range f {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f fakeAuthUsers) DeleteExpiredGuests() error { return nil }
+
+// fakeRefreshTokens keeps refresh tokens in memory
+type fakeRefreshTokens struct {
+	tokens []*model.RefreshToken
+}
+
+func (f *fakeRefreshTokens) Create(token *model.RefreshToken) error {
+	token.ID = uuid.New()
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+func (f *fakeRefreshTokens) GetByHash(tokenHash string) (*model.RefreshToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("refresh token not found")
+}
+
+func (f *fakeRefreshTokens) Rotate(id uuid.UUID, next *model.RefreshToken) error {
+	for _, token := range f.tokens {
+		if token.ID == id {
+			if token.UsedAt != nil || token.RevokedAt != nil {
+				return repository.ErrRefreshTokenUsed
+			}
+			now := time.Now()
+			token.UsedAt = &now
+			return f.Create(next)
+		}
+	}
+	return repository.ErrRefreshTokenUsed
+}
+
+func (f *fakeRefreshTokens) revoke(match func(token *model.RefreshToken) bool) {
+	now := time.Now()
+	for _, token := range f.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(familyID uuid.UUID) error {
+	f.revoke(func(token *model.RefreshToken) bool { return token.FamilyID == familyID })
+	return nil
+}
+
+func (f *fakeRefreshTokens) RevokeByUserID(userID uuid.UUID) error {
+	f.revoke(func(token *model.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (f *fakeRefreshTokens) DeleteExpired() error { return nil }
+
+// fakeAuthSessions keeps login sessions in memory
+type fakeAuthSessions struct {
+	sessions []*model.AuthSession
+}
+
+func (f *fakeAuthSessions) Create(session *model.AuthSession) error {
+	session.ID = uuid.New()
+	session.CreatedAt = time.Now()
+	f.sessions = append(f.sessions, session)
+	return nil
+}
+
+func (f *fakeAuthSessions) GetActiveByIDAndUserID(id, userID uuid.UUID) (*model.AuthSession, error) {
+	for _, session := range f.sessions {
+		if session.ID == id && session.UserID == userID && session.RevokedAt == nil {
+			return session, nil
+		}
+	}
+	return nil, errors.New("session not found")
+}
+
+func (f *fakeAuthSessions) GetActiveByUserID(userID uuid.UUID) ([]model.AuthSession, error) {
+	var sessions []model.AuthSession
+	for _, session := range f.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeAuthSessions) Touch(id uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) error {
+	for _, session := range f.sessions {
+		if session.ID == id {
+			session.UserAgent = userAgent
+			session.IPAddress = ipAddress
+			session.LastUsedAt = time.Now()
+			session.ExpiresAt = expiresAt
+		}
+	}
+	return nil
+}
+
+func (f *fakeAuthSessions) revoke(match func(session *model.AuthSession) bool) {
+	now := time.Now()
+	for _, session := range f.sessions {
+		if match(session) && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+}
+
+func (f *fakeAuthSessions) Revoke(ids ...uuid.UUID) error {
+	for _, id := range ids {
+		id := id
+		f.revoke(func(session *model.AuthSession) bool { return session.ID == id })
+	}
+	return nil
+}
+
+func (f *fakeAuthSessions) RevokeByUserID(userID uuid.UUID) error {
+	f.revoke(func(session *model.AuthSession) bool { return session.UserID == userID })
+	return nil
+}
+
+func (f *fakeAuthSessions) DeleteExpired() error { return nil }
+
+// newTestAuthService creates a service for the given users without two-factor
+// authentication or email verification
+func newTestAuthService(users ...*model.User) (*AuthService, *fakeRefreshTokens, *fakeAuthSessions) {
+	userStore := fakeAuthUsers{}
+	for _, user := range users {
+		userStore.Create(user)
+	}
+	tokens := &fakeRefreshTokens{}
+	sessions := &fakeAuthSessions{}
+	cfg := &config.Config{JWT: config.JWTConfig{AccessTokenExpiry: 15 * time.Minute, RefreshTokenExpiry: time.Hour}}
+
+	s := &AuthService{
+		userRepo:         userStore,
+		refreshTokenRepo: tokens,
+		sessionRepo:      sessions,
+		accountService:   &AccountService{},
+		mfaService:       &MFAService{mfaRepo: newFakeMFA()},
+		denylist:         denylist.NewMemoryStore(),
+		jwtMgr:           jwt.NewManager("test-secret", cfg.JWT.AccessTokenExpiry, cfg.JWT.RefreshTokenExpiry),
+		cfg:              cfg,
+	}
+	return s, tokens, sessions
+}
+
+// accessRevoked reports whether the denylist rejects an access token
+func accessRevoked(t *testing.T, s *AuthService, accessToken string) bool {
+	t.Helper()
+	claims, err := s.ValidateToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	revoked, err := s.denylist.IsRevoked(claims.ID, claims.SessionID.String(), claims.UserID.String(), claims.IssuedAt.Time)
+	if err != nil {
+		t.Fatalf("IsRevoked() error = %v", err)
+	}
+	return revoked
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+	s, tokens, sessions := newTestAuthService(user)
+
+	first, err := s.startSession(user, ClientInfo{UserAgent: "laptop", IPAddress: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	second, err := s.RefreshToken(first.RefreshToken, ClientInfo{UserAgent: "laptop", IPAddress: "192.0.2.2"})
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+
+	// The rotated token stays in the family of the login session
+	if len(tokens.tokens) != 2 || tokens.tokens[0].UsedAt == nil || tokens.tokens[1].FamilyID != tokens.tokens[0].FamilyID {
+		t.Errorf("tokens = %+v, want the first used and the second in its family", tokens.tokens)
+	}
+	if len(sessions.sessions) != 1 || sessions.sessions[0].ID != tokens.tokens[0].FamilyID {
+		t.Fatalf("sessions = %+v, want one per family", sessions.sessions)
+	}
+	if sessions.sessions[0].IPAddress != "192.0.2.2" {
+		t.Errorf("session IP = %q, want the address of the refresh", sessions.sessions[0].IPAddress)
+	}
+	if _, err := s.RefreshToken(second.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("RefreshToken() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+	s, _, sessions := newTestAuthService(user)
+
+	stolen, err := s.startSession(user, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	other, err := s.startSession(user, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	rotated, err := s.RefreshToken(stolen.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+
+	// Presenting the used token again means one of its holders is not the owner
+	if _, err := s.RefreshToken(stolen.RefreshToken, ClientInfo{}); err == nil || !strings.Contains(err.Error(), "reuse") {
+		t.Fatalf("RefreshToken() with a used token error = %v, want reuse detected", err)
+	}
+	if _, err := s.RefreshToken(rotated.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("successor of a reused token still refreshes")
+	}
+	if sessions.sessions[0].RevokedAt == nil {
+		t.Error("login session of the reused token not revoked")
+	}
+	if !accessRevoked(t, s, rotated.AccessToken) {
+		t.Error("access token of the reused family not denied")
+	}
+
+	// Other login sessions of the user are not affected
+	if sessions.sessions[1].RevokedAt != nil || accessRevoked(t, s, other.AccessToken) {
+		t.Error("other login session revoked")
+	}
+	if _, err := s.RefreshToken(other.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("RefreshToken() of the other session error = %v", err)
+	}
+}
+
+func TestRefreshTokenRejectsInvalidTokens(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+	s, tokens, _ := newTestAuthService(user)
+
+	login, err := s.startSession(user, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+
+	// Access tokens and signed refresh tokens that were never stored are rejected
+	if _, err := s.RefreshToken(login.AccessToken, ClientInfo{}); err == nil {
+		t.Error("RefreshToken() accepted an access token")
+	}
+	unknown, _ := s.jwtMgr.GenerateRefreshToken(user.ID, user.Email)
+	if _, err := s.RefreshToken(unknown, ClientInfo{}); err == nil {
+		t.Error("RefreshToken() accepted a token that was not stored")
+	}
+
+	user.IsActive = false
+	if _, err := s.RefreshToken(login.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("RefreshToken() accepted a token of a deactivated user")
+	}
+	if tokens.tokens[0].UsedAt != nil {
+		t.Error("token of a deactivated user rotated")
+	}
+}
+
+func TestLogoutRevokesFamily(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+	s, _, sessions := newTestAuthService(user)
+
+	current, err := s.startSession(user, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	rotated, err := s.RefreshToken(current.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+
+	if err := s.Logout("", rotated.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := s.RefreshToken(rotated.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("refresh token still valid after logout")
+	}
+	if sessions.sessions[0].RevokedAt == nil || !accessRevoked(t, s, current.AccessToken) {
+		t.Error("login session still active after logout")
+	}
+
+	// Unknown tokens are ignored
+	if err := s.Logout("not-a-token", "not-a-token"); err != nil {
+		t.Errorf("Logout() with invalid tokens error = %v", err)
+	}
+}
+
+func TestSessionManagement(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+	s, _, sessions := newTestAuthService(user)
+
+	laptop, err := s.startSession(user, ClientInfo{UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", IPAddress: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	phone, err := s.startSession(user, ClientInfo{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) Mobile/15E148"})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	tablet, err := s.startSession(user, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession() error = %v", err)
+	}
+	laptopID := sessions.sessions[0].ID
+
+	list, err := s.GetSessions(user.ID, laptopID)
+	if err != nil {
+		t.Fatalf("GetSessions() error = %v", err)
+	}
+	if len(list) != 3 || !list[0].IsCurrent || list[1].IsCurrent || list[0].Device != "Firefox on Linux" || list[0].IPAddress != "192.0.2.1" {
+		t.Errorf("GetSessions() = %+v, want three with the laptop current", list)
+	}
+
+	// Sessions of other users cannot be revoked
+	if err := s.RevokeSession(uuid.New(), sessions.sessions[1].ID); err == nil {
+		t.Error("RevokeSession() revoked a session of another user")
+	}
+	if err := s.RevokeSession(user.ID, sessions.sessions[1].ID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if !accessRevoked(t, s, phone.AccessToken) {
+		t.Error("access token of the revoked session not denied")
+	}
+	if _, err := s.RefreshToken(phone.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("refresh token of the revoked session still valid")
+	}
+
+	revoked, err := s.RevokeOtherSessions(user.ID, laptopID)
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions() error = %v", err)
+	}
+	if revoked != 1 || !accessRevoked(t, s, tablet.AccessToken) {
+		t.Errorf("RevokeOtherSessions() revoked %d, want the tablet", revoked)
+	}
+	if accessRevoked(t, s, laptop.AccessToken) {
+		t.Error("current session revoked")
+	}
+	if list, _ := s.GetSessions(user.ID, laptopID); len(list) != 1 || list[0].ID != laptopID.String() {
+		t.Errorf("GetSessions() after revoking = %+v, want only the current one", list)
+	}
+}
+
+func TestTruncateUserAgent(t *testing.T) {
+	long := strings.Repeat("ж", maxUserAgentLength+10)
+	if got := truncateUserAgent(long); len([]rune(got)) != maxUserAgentLength {
+		t.Errorf("truncateUserAgent() kept %d characters, want %d", len([]rune(got)), maxUserAgentLength)
+	}
+	if got := truncateUserAgent("curl/8.4.0"); got != "curl/8.4.0" {
+		t.Errorf("truncateUserAgent() = %q", got)
+	}
+}
+
+// fakeAccountTokens keeps emailed tokens in memory
+type fakeAccountTokens struct {
+	tokens []*model.UserToken
+}
+
+func (f *fakeAccountTokens) Create(token *model.UserToken) error {
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+func (f *fakeAccountTokens) Use(tokenHash, purpose string) (*model.UserToken, error) {
+	return nil, repository.ErrUserTokenInvalid
+}
+
+func (f *fakeAccountTokens) InvalidateByUserID(userID uuid.UUID, purpose string) error {
+	return nil
+}
+
+func (f *fakeAccountTokens) DeleteExpired() error { return nil }
+
+// fakeMailer records sent emails
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (f *fakeMailer) Send(msg mailer.Message) error {
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+// newTestGuest creates an auth service with a guest signed in and returns the guest's tokens
+func newTestGuest(t *testing.T, users ...*model.User) (*AuthService, *model.User, *dto.GuestSessionResponse, *fakeMailer) {
+	t.Helper()
+	s, _, _ := newTestAuthService(users...)
+	s.cfg.Guest.Lifetime = 24 * time.Hour
+	mail := &fakeMailer{}
+	s.accountService = &AccountService{tokenRepo: &fakeAccountTokens{}, mailer: mail}
+
+	guestSession, err := s.CreateGuestSession(ClientInfo{})
+	if err != nil {
+		t.Fatalf("CreateGuestSession() error = %v", err)
+	}
+	guest, err := s.userRepo.GetByID(uuid.MustParse(guestSession.GuestID))
+	if err != nil {
+		t.Fatalf("guest not stored: %v", err)
+	}
+	return s, guest, guestSession, mail
+}
+
+func TestUpgradeGuest(t *testing.T) {
+	s, guest, guestSession, mail := newTestGuest(t)
+	guestID := guest.ID
+
+	response, err := s.UpgradeGuest(guest.ID, &dto.UpgradeGuestRequest{Email: "alice@example.com", Username: "alice", Password: "correct horse"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("UpgradeGuest() error = %v", err)
+	}
+
+	// The account keeps its ID, so its chats are kept
+	user, err := s.userRepo.GetByID(guestID)
+	if err != nil {
+		t.Fatalf("upgraded user not found: %v", err)
+	}
+	if user.IsGuest || user.ExpiresAt != nil {
+		t.Error("account is still a guest account that expires")
+	}
+	if user.Email != "alice@example.com" || user.Username != "alice" || !utils.CheckPasswordHash("correct horse", user.PasswordHash) {
+		t.Errorf("credentials not set: %s, %s", user.Email, user.Username)
+	}
+
+	// Guest tokens carry the guest flag and email: they are replaced by a new session
+	if _, err := s.RefreshToken(guestSession.RefreshToken, ClientInfo{}); err == nil {
+		t.Error("guest refresh token still accepted")
+	}
+	if sessions, _ := s.sessionRepo.GetActiveByUserID(guestID); len(sessions) != 1 {
+		t.Errorf("%d active sessions, want only the new one", len(sessions))
+	}
+	if response == nil || response.AccessToken == "" {
+		t.Fatalf("UpgradeGuest() = %+v, want tokens of a new session", response)
+	}
+	claims, err := s.ValidateToken(response.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.IsGuest || claims.Email != "alice@example.com" || claims.UserID != guestID {
+		t.Errorf("new access token claims = %+v", claims)
+	}
+	if len(mail.sent) != 1 || mail.sent[0].To != "alice@example.com" {
+		t.Errorf("sent %+v, want a verification email to the new address", mail.sent)
+	}
+}
+
+func TestUpgradeGuestRequiringVerification(t *testing.T) {
+	s, guest, _, _ := newTestGuest(t)
+	s.accountService.cfg.RequireEmailVerification = true
+
+	// No tokens until the email is verified, but the account is upgraded
+	response, err := s.UpgradeGuest(guest.ID, &dto.UpgradeGuestRequest{Email: "alice@example.com", Username: "alice", Password: "correct horse"}, ClientInfo{})
+	if err != nil || response != nil {
+		t.Fatalf("UpgradeGuest() = %+v, %v; want no tokens", response, err)
+	}
+	if user, _ := s.userRepo.GetByID(guest.ID); user.IsGuest {
+		t.Error("account not upgraded")
+	}
+}
+
+func TestUpgradeGuestRejections(t *testing.T) {
+	existing := &model.User{Email: "bob@example.com", Username: "bob", IsActive: true}
+	s, guest, guestSession, _ := newTestGuest(t, existing)
+
+	taken := []dto.UpgradeGuestRequest{
+		{Email: "bob@example.com", Username: "alice", Password: "correct horse"},
+		{Email: "alice@example.com", Username: "bob", Password: "correct horse"},
+	}
+	for _, req := range taken {
+		req := req
+		if _, err := s.UpgradeGuest(guest.ID, &req, ClientInfo{}); err == nil {
+			t.Errorf("UpgradeGuest(%s, %s) accepted taken credentials", req.Email, req.Username)
+		}
+	}
+	if user, _ := s.userRepo.GetByID(guest.ID); !user.IsGuest || accessRevoked(t, s, guestSession.AccessToken) {
+		t.Error("guest changed by a rejected upgrade")
+	}
+
+	// Registered accounts cannot be upgraded again
+	if _, err := s.UpgradeGuest(existing.ID, &dto.UpgradeGuestRequest{Email: "carol@example.com", Username: "carol", Password: "correct horse"}, ClientInfo{}); err == nil {
+		t.Error("UpgradeGuest() accepted a registered account")
+	}
+}
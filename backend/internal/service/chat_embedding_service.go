@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/vectorindex"
+)
+
+const (
+	// maxChatEmbeddingChars limits the text embedded per chat; longer chats keep their
+	// beginning (the original topic) and their most recent questions
+	maxChatEmbeddingChars = 4000
+	// chatIndexDebounce delays indexing after a message, so a chat that is being
+	// actively used is embedded once after a pause instead of after every message
+	chatIndexDebounce = 10 * time.Second
+	// chatIndexPollInterval is how often the indexer looks for chats that are due
+	chatIndexPollInterval = time.Second
+	// maxPendingChatIndexes limits the chats waiting to be indexed; chats dropped
+	// when the queue is full are indexed by the next backfill or on demand
+	maxPendingChatIndexes = 10000
+)
+
+// ChatEmbeddingService handles semantic search over chat sessions and related chats
+type ChatEmbeddingService struct {
+	embeddingRepo *repository.ChatEmbeddingRepository
+	chatRepo      *repository.ChatRepository
+	messageRepo   *repository.MessageRepository
+	embedder      Embedder
+	minScore      float64
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]time.Time // Chats waiting to be indexed, by the time they are due
+}
+
+// NewChatEmbeddingService creates a new chat embedding service
+func NewChatEmbeddingService(
+	embeddingRepo *repository.ChatEmbeddingRepository,
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	embedder Embedder,
+	cfg *config.Config,
+) *ChatEmbeddingService {
+	return &ChatEmbeddingService{
+		embeddingRepo: embeddingRepo,
+		chatRepo:      chatRepo,
+		messageRepo:   messageRepo,
+		embedder:      embedder,
+		minScore:      cfg.Search.SemanticMinScore,
+		pending:       make(map[uuid.UUID]time.Time),
+	}
+}
+
+// IndexChat embeds a chat session: its title followed by the user's messages.
+// Chats already embedded with the same model and message count are skipped.
+func (s *ChatEmbeddingService) IndexChat(sessionID uuid.UUID) error {
+	session, err := s.chatRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	messages, err := s.messageRepo.GetByChatSessionID(sessionID)
+	if err != nil || len(messages) == 0 {
+		return err
+	}
+
+	existing, err := s.embeddingRepo.GetByChatSessionID(sessionID)
+	if err == nil && existing.EmbeddingModel == s.embedder.Name() && existing.MessageCount == len(messages) {
+		return nil
+	}
+
+	vector, err := s.embedder.Embed(chatEmbeddingText(session.Title, messages))
+	if err != nil {
+		return fmt.Errorf("failed to embed chat: %w", err)
+	}
+
+	return s.embeddingRepo.Upsert(&model.ChatEmbedding{
+		ChatSessionID:  session.ID,
+		UserID:         session.UserID,
+		EmbeddingModel: s.embedder.Name(),
+		Embedding:      vector,
+		MessageCount:   len(messages),
+	})
+}
+
+// IndexChatAsync queues a chat session to be embedded in the background by RunIndexer.
+// Queuing a chat again postpones its indexing by chatIndexDebounce.
+func (s *ChatEmbeddingService) IndexChatAsync(sessionID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, queued := s.pending[sessionID]; !queued && len(s.pending) >= maxPendingChatIndexes {
+		log.Printf("Warning: Chat indexing queue is full, skipping chat %s", sessionID)
+		return
+	}
+	s.pending[sessionID] = time.Now().Add(chatIndexDebounce)
+}
+
+// RunIndexer embeds queued chat sessions one at a time until the context is canceled
+func (s *ChatEmbeddingService) RunIndexer(ctx context.Context) {
+	ticker := time.NewTicker(chatIndexPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, sessionID := range s.takeDue(now) {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := s.IndexChat(sessionID); err != nil {
+					// Log error: the chat is indexed again on the next completed message or backfill
+					log.Printf("Warning: Could not index chat %s: %v", sessionID, err)
+				}
+			}
+		}
+	}
+}
+
+// takeDue removes and returns the queued chat sessions due for indexing
+func (s *ChatEmbeddingService) takeDue(now time.Time) []uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []uuid.UUID
+	for sessionID, at := range s.pending {
+		if !at.After(now) {
+			due = append(due, sessionID)
+			delete(s.pending, sessionID)
+		}
+	}
+	return due
+}
+
+// IndexMissing embeds all chat sessions that have no embedding for the current model
+func (s *ChatEmbeddingService) IndexMissing() error {
+	for {
+		ids, err := s.embeddingRepo.GetUnindexedChatSessionIDs(s.embedder.Name(), 100)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, id := range ids {
+			// Stop on the first failure to avoid retrying the same chats forever
+			if err := s.IndexChat(id); err != nil {
+				return fmt.Errorf("failed to index chat %s: %w", id, err)
+			}
+		}
+	}
+}
+
+// GetRelatedChats finds the user's chats most similar to a chat session
+func (s *ChatEmbeddingService) GetRelatedChats(sessionID, userID uuid.UUID, limit int) ([]dto.ChatMatchResponse, error) {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	// Index the chat on demand if it was not indexed yet
+	embedding, err := s.embeddingRepo.GetByChatSessionID(sessionID)
+	if err != nil || embedding.EmbeddingModel != s.embedder.Name() {
+		if err := s.IndexChat(sessionID); err != nil {
+			return nil, err
+		}
+		if embedding, err = s.embeddingRepo.GetByChatSessionID(sessionID); err != nil {
+			// Chat has no messages yet
+			return []dto.ChatMatchResponse{}, nil
+		}
+	}
+
+	return s.rank(userID, embedding.Embedding, sessionID, limit)
+}
+
+// SearchChats finds the user's chats semantically similar to a query
+func (s *ChatEmbeddingService) SearchChats(userID uuid.UUID, query string, limit int) ([]dto.ChatMatchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	vector, err := s.embedder.Embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	return s.rank(userID, vector, uuid.Nil, limit)
+}
+
+// rank scores the user's chat embeddings against a vector
+func (s *ChatEmbeddingService) rank(userID uuid.UUID, vector []float32, excludeID uuid.UUID, limit int) ([]dto.ChatMatchResponse, error) {
+	embeddings, err := s.embeddingRepo.GetByUserID(userID, s.embedder.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]dto.ChatMatchResponse, 0)
+	for _, embedding := range embeddings {
+		if embedding.ChatSessionID == excludeID {
+			continue
+		}
+
+		score := vectorindex.Cosine(vector, embedding.Embedding)
+		if score < s.minScore {
+			continue
+		}
+
+		session := embedding.ChatSession
+		matches = append(matches, dto.ChatMatchResponse{
+			ChatSessionResponse: dto.ChatSessionResponse{
+				ID:           session.ID.String(),
+				Title:        session.Title,
+				ModelUsed:    session.ModelUsed,
+				CreatedAt:    session.CreatedAt,
+				UpdatedAt:    session.UpdatedAt,
+				IsArchived:   session.IsArchived,
+				MessageCount: embedding.MessageCount,
+			},
+			Score: score,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// chatEmbeddingText builds the text embedded for a chat: what the user asked about
+func chatEmbeddingText(title string, messages []model.Message) string {
+	var b strings.Builder
+	b.WriteString(title)
+	for _, message := range messages {
+		if message.Role == model.MessageRoleUser {
+			b.WriteString("\n")
+			b.WriteString(message.Content)
+		}
+	}
+
+	runes := []rune(b.String())
+	if len(runes) <= maxChatEmbeddingChars {
+		return string(runes)
+	}
+
+	half := maxChatEmbeddingChars / 2
+	return string(runes[:half]) + "\n" + string(runes[len(runes)-half:])
+}
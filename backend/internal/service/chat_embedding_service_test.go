@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+)
+
+func TestIndexChatAsyncDebounces(t *testing.T) {
+	s := NewChatEmbeddingService(nil, nil, nil, nil, &config.Config{})
+	id := uuid.New()
+
+	s.IndexChatAsync(id)
+	first := s.pending[id]
+	time.Sleep(time.Millisecond)
+	s.IndexChatAsync(id)
+
+	if len(s.pending) != 1 {
+		t.Fatalf("pending = %d chats, want 1", len(s.pending))
+	}
+	if !s.pending[id].After(first) {
+		t.Fatal("queuing a chat again should postpone its indexing")
+	}
+
+	if due := s.takeDue(time.Now()); len(due) != 0 {
+		t.Fatalf("takeDue() before the debounce = %v, want none", due)
+	}
+	due := s.takeDue(time.Now().Add(chatIndexDebounce + time.Second))
+	if len(due) != 1 || due[0] != id {
+		t.Fatalf("takeDue() = %v, want [%s]", due, id)
+	}
+	if len(s.pending) != 0 {
+		t.Fatal("due chats should leave the queue")
+	}
+}
+
+func TestIndexChatAsyncIsBounded(t *testing.T) {
+	s := NewChatEmbeddingService(nil, nil, nil, nil, &config.Config{})
+	for i := 0; i < maxPendingChatIndexes; i++ {
+		s.pending[uuid.New()] = time.Now()
+	}
+
+	s.IndexChatAsync(uuid.New())
+	if len(s.pending) != maxPendingChatIndexes {
+		t.Fatalf("pending = %d chats, want at most %d", len(s.pending), maxPendingChatIndexes)
+	}
+
+	// Chats already queued are still postponed
+	var queued uuid.UUID
+	for id := range s.pending {
+		queued = id
+		break
+	}
+	s.IndexChatAsync(queued)
+	if !s.pending[queued].After(time.Now()) {
+		t.Fatal("queued chat should be postponed when the queue is full")
+	}
+}
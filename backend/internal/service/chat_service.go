@@ -1,23 +1,45 @@
 package service
 
 import (
+	"log"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/llmchatbot/backend/internal/dto"
 	"github.com/llmchatbot/backend/internal/model"
 	"github.com/llmchatbot/backend/internal/repository"
 )
 
+const (
+	// chatPreviewLength is the maximum length of the last message preview in chat lists
+	chatPreviewLength = 120
+	// chatStatsBatchSize limits the number of chat sessions per statistics query
+	chatStatsBatchSize = 1000
+)
+
 // ChatService handles chat session business logic
 type ChatService struct {
-	chatRepo    *repository.ChatRepository
-	messageRepo *repository.MessageRepository
+	chatRepo      *repository.ChatRepository
+	messageRepo   *repository.MessageRepository
+	documentRepo  *repository.DocumentRepository
+	attachmentSvc *AttachmentService
+	index         VectorIndex
 }
 
 // NewChatService creates a new chat service
-func NewChatService(chatRepo *repository.ChatRepository, messageRepo *repository.MessageRepository) *ChatService {
+func NewChatService(
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	documentRepo *repository.DocumentRepository,
+	attachmentSvc *AttachmentService,
+	index VectorIndex,
+) *ChatService {
 	return &ChatService{
-		chatRepo:    chatRepo,
-		messageRepo: messageRepo,
+		chatRepo:      chatRepo,
+		messageRepo:   messageRepo,
+		documentRepo:  documentRepo,
+		attachmentSvc: attachmentSvc,
+		index:         index,
 	}
 }
 
@@ -47,9 +69,9 @@ func (s *ChatService) CreateChatSession(userID uuid.UUID, req *dto.CreateChatSes
 	return s.toChatSessionResponse(session), nil
 }
 
-// GetChatSessions retrieves all chat sessions for a user
-func (s *ChatService) GetChatSessions(userID uuid.UUID, includeArchived bool) ([]dto.ChatSessionResponse, error) {
-	sessions, err := s.chatRepo.GetByUserID(userID, includeArchived)
+// GetChatSessions retrieves the chat sessions of a user matching a filter
+func (s *ChatService) GetChatSessions(userID uuid.UUID, filter repository.ChatListFilter) ([]dto.ChatSessionResponse, error) {
+	sessions, err := s.chatRepo.List(userID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -59,17 +81,108 @@ func (s *ChatService) GetChatSessions(userID uuid.UUID, includeArchived bool) ([
 		responses[i] = *s.toChatSessionResponse(&session)
 	}
 
+	if err := s.addChatStats(responses); err != nil {
+		return nil, err
+	}
+
 	return responses, nil
 }
 
+// ListChatSessions retrieves a page of chat sessions of a user matching a filter.
+// The cursor is the next_cursor of the previous page (empty for the first page).
+func (s *ChatService) ListChatSessions(userID uuid.UUID, filter repository.ChatListFilter, cursor string, limit int) (*dto.ChatListResponse, error) {
+	if cursor != "" {
+		var position chatCursor
+		if err := decodeCursor(cursor, &position); err != nil {
+			return nil, err
+		}
+		filter.Cursor = &repository.ChatCursor{IsPinned: position.IsPinned, UpdatedAt: position.UpdatedAt, ID: position.ID}
+	}
+
+	// Load one extra row to know whether there is a next page
+	filter.Limit = limit + 1
+	sessions, err := s.chatRepo.List(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ChatListResponse{}
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+		last := sessions[len(sessions)-1]
+		response.NextCursor = encodeCursor(chatCursor{IsPinned: last.IsPinned, UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	response.Chats = make([]dto.ChatSessionResponse, len(sessions))
+	for i := range sessions {
+		response.Chats[i] = *s.toChatSessionResponse(&sessions[i])
+	}
+
+	if err := s.addChatStats(response.Chats); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetMessages retrieves a page of messages of a chat session, oldest first
+// (newest first if descending). The cursor is the next_cursor of the previous page.
+func (s *ChatService) GetMessages(sessionID, userID uuid.UUID, cursor string, limit int, descending bool) (*dto.MessageListResponse, error) {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	var position messageCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &position); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := s.messageRepo.GetPage(sessionID, position.SequenceNumber, limit+1, descending)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.MessageListResponse{}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		last := messages[len(messages)-1]
+		response.NextCursor = encodeCursor(messageCursor{SequenceNumber: last.SequenceNumber})
+	}
+
+	response.Messages = make([]dto.MessageResponse, len(messages))
+	for i, msg := range messages {
+		response.Messages[i] = dto.MessageResponse{
+			ID:             msg.ID.String(),
+			Role:           msg.Role,
+			Content:        msg.Content,
+			Tokens:         msg.Tokens,
+			IsIncomplete:   msg.IsIncomplete,
+			IsPinned:       msg.IsPinned,
+			IsExcluded:     msg.IsExcluded,
+			CreatedAt:      msg.CreatedAt,
+			SequenceNumber: msg.SequenceNumber,
+			Attachments:    toAttachmentResponses(msg.Attachments),
+		}
+	}
+
+	return response, nil
+}
+
 // GetChatSession retrieves a chat session by ID
 func (s *ChatService) GetChatSession(sessionID, userID uuid.UUID) (*dto.ChatSessionResponse, error) {
-	session, err := s.chatRepo.GetByIDAndUserID(sessionID, userID)
+	session, err := s.chatRepo.GetWithTags(sessionID, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.toChatSessionResponse(session), nil
+	responses := []dto.ChatSessionResponse{*s.toChatSessionResponse(session)}
+	if err := s.addChatStats(responses); err != nil {
+		return nil, err
+	}
+
+	return &responses[0], nil
 }
 
 // GetChatSessionWithMessages retrieves a chat session with all messages
@@ -90,8 +203,12 @@ func (s *ChatService) GetChatSessionWithMessages(sessionID, userID uuid.UUID) (*
 			Role:           msg.Role,
 			Content:        msg.Content,
 			Tokens:         msg.Tokens,
+			IsIncomplete:   msg.IsIncomplete,
+			IsPinned:       msg.IsPinned,
+			IsExcluded:     msg.IsExcluded,
 			CreatedAt:      msg.CreatedAt,
 			SequenceNumber: msg.SequenceNumber,
+			Attachments:    toAttachmentResponses(msg.Attachments),
 		}
 	}
 
@@ -100,7 +217,7 @@ func (s *ChatService) GetChatSessionWithMessages(sessionID, userID uuid.UUID) (*
 
 // UpdateChatSession updates a chat session title
 func (s *ChatService) UpdateChatSession(sessionID, userID uuid.UUID, req *dto.UpdateChatSessionRequest) (*dto.ChatSessionResponse, error) {
-	session, err := s.chatRepo.GetByIDAndUserID(sessionID, userID)
+	session, err := s.chatRepo.GetWithTags(sessionID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +242,7 @@ func (s *ChatService) RestoreChatSession(sessionID, userID uuid.UUID) (*dto.Chat
 		return nil, err
 	}
 
-	session, err := s.chatRepo.GetByIDAndUserID(sessionID, userID)
+	session, err := s.chatRepo.GetWithTags(sessionID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +250,75 @@ func (s *ChatService) RestoreChatSession(sessionID, userID uuid.UUID) (*dto.Chat
 	return s.toChatSessionResponse(session), nil
 }
 
+// PinChatSession pins or unpins a chat session
+func (s *ChatService) PinChatSession(sessionID, userID uuid.UUID, pinned bool) error {
+	return s.chatRepo.SetPinned(sessionID, userID, pinned)
+}
+
+// PinMessage pins or unpins a message; pinned messages are always sent to the model
+func (s *ChatService) PinMessage(sessionID, messageID, userID uuid.UUID, pinned bool) error {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.SetPinned(messageID, sessionID, pinned)
+}
+
+// ExcludeMessage excludes a message from the model context (or includes it again);
+// the message stays visible in the chat
+func (s *ChatService) ExcludeMessage(sessionID, messageID, userID uuid.UUID, excluded bool) error {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.SetExcluded(messageID, sessionID, excluded)
+}
+
+// DeleteMessage permanently deletes a message; other messages keep their sequence numbers
+func (s *ChatService) DeleteMessage(sessionID, messageID, userID uuid.UUID) error {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return err
+	}
+	attachments, err := s.attachmentSvc.messageAttachments(messageID)
+	if err != nil {
+		return err
+	}
+	if err := s.messageRepo.DeleteFromChat(messageID, sessionID); err != nil {
+		return err
+	}
+
+	s.cleanupAttachments(attachments)
+	return nil
+}
+
+// GetPinnedMessages retrieves all pinned messages of a chat session
+func (s *ChatService) GetPinnedMessages(sessionID, userID uuid.UUID) ([]dto.MessageResponse, error) {
+	if _, err := s.chatRepo.GetByIDAndUserID(sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.GetPinned(sessionID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.MessageResponse, len(messages))
+	for i, msg := range messages {
+		responses[i] = dto.MessageResponse{
+			ID:             msg.ID.String(),
+			Role:           msg.Role,
+			Content:        msg.Content,
+			Tokens:         msg.Tokens,
+			IsIncomplete:   msg.IsIncomplete,
+			IsPinned:       msg.IsPinned,
+			IsExcluded:     msg.IsExcluded,
+			CreatedAt:      msg.CreatedAt,
+			SequenceNumber: msg.SequenceNumber,
+			Attachments:    toAttachmentResponses(msg.Attachments),
+		}
+	}
+
+	return responses, nil
+}
+
 // RestoreChatSessions restores multiple archived chat sessions
 func (s *ChatService) RestoreChatSessions(sessionIDs []uuid.UUID, userID uuid.UUID) error {
 	return s.chatRepo.UnarchiveMultiple(sessionIDs, userID)
@@ -140,22 +326,133 @@ func (s *ChatService) RestoreChatSessions(sessionIDs []uuid.UUID, userID uuid.UU
 
 // DeleteChatSession permanently deletes a chat session
 func (s *ChatService) DeleteChatSession(sessionID, userID uuid.UUID) error {
-	return s.chatRepo.Delete(sessionID, userID)
+	attachments, err := s.attachmentSvc.sessionAttachments([]uuid.UUID{sessionID}, userID)
+	if err != nil {
+		return err
+	}
+	documentIDs, err := s.documentRepo.GetIDsByChatSessionIDs([]uuid.UUID{sessionID}, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRepo.Delete(sessionID, userID); err != nil {
+		return err
+	}
+
+	s.cleanupDeleted(attachments, documentIDs)
+	return nil
 }
 
 // DeleteChatSessions permanently deletes multiple chat sessions
 func (s *ChatService) DeleteChatSessions(sessionIDs []uuid.UUID, userID uuid.UUID) error {
-	return s.chatRepo.DeleteMultiple(sessionIDs, userID)
+	attachments, err := s.attachmentSvc.sessionAttachments(sessionIDs, userID)
+	if err != nil {
+		return err
+	}
+	documentIDs, err := s.documentRepo.GetIDsByChatSessionIDs(sessionIDs, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRepo.DeleteMultiple(sessionIDs, userID); err != nil {
+		return err
+	}
+
+	s.cleanupDeleted(attachments, documentIDs)
+	return nil
+}
+
+// cleanupDeleted removes attachments of deleted chat sessions from storage and
+// chunks of their documents, deleted by cascade, from the vector index
+func (s *ChatService) cleanupDeleted(attachments []model.Attachment, documentIDs []uuid.UUID) {
+	s.cleanupAttachments(attachments)
+	for _, documentID := range documentIDs {
+		if err := s.index.Remove(documentID); err != nil {
+			// Log error but don't fail deletion: the periodic cleanup prunes the index
+			log.Printf("Failed to remove document %s from vector index: %v", documentID, err)
+		}
+	}
+}
+
+// cleanupAttachments removes attachments of deleted messages from storage
+func (s *ChatService) cleanupAttachments(attachments []model.Attachment) {
+	if err := s.attachmentSvc.removeAttachments(attachments); err != nil {
+		// Log error but don't fail deletion: the periodic cleanup retries
+		log.Printf("Error cleaning up attachments: %v", err)
+	}
+}
+
+// addChatStats fills message count, token total and last message preview of chat
+// session responses, using one aggregated query per batch of sessions
+func (s *ChatService) addChatStats(responses []dto.ChatSessionResponse) error {
+	index := make(map[uuid.UUID]*dto.ChatSessionResponse, len(responses))
+	ids := make([]uuid.UUID, 0, len(responses))
+	for i := range responses {
+		id, err := uuid.Parse(responses[i].ID)
+		if err != nil {
+			return err
+		}
+		index[id] = &responses[i]
+		ids = append(ids, id)
+	}
+
+	for start := 0; start < len(ids); start += chatStatsBatchSize {
+		end := start + chatStatsBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		// Load extra characters so that whitespace collapsing still fills the preview
+		stats, err := s.messageRepo.GetChatStats(ids[start:end], chatPreviewLength*2)
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range stats {
+			response := index[stat.ChatSessionID]
+			if response == nil {
+				continue
+			}
+			response.MessageCount = stat.MessageCount
+			response.TotalTokens = stat.TotalTokens
+			response.LastMessageRole = stat.LastMessageRole
+			response.LastMessagePreview = messagePreview(stat.LastMessageText)
+			if response.LastActivityAt == nil || stat.LastMessageAt.After(*response.LastActivityAt) {
+				lastMessageAt := stat.LastMessageAt
+				response.LastActivityAt = &lastMessageAt
+			}
+		}
+	}
+
+	return nil
+}
+
+// messagePreview collapses whitespace and truncates text to chatPreviewLength characters
+func messagePreview(text string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= chatPreviewLength {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:chatPreviewLength])) + "…"
 }
 
 // toChatSessionResponse converts a ChatSession model to response DTO
 func (s *ChatService) toChatSessionResponse(session *model.ChatSession) *dto.ChatSessionResponse {
-	return &dto.ChatSessionResponse{
+	response := &dto.ChatSessionResponse{
 		ID:         session.ID.String(),
 		Title:      session.Title,
 		ModelUsed:  session.ModelUsed,
 		CreatedAt:  session.CreatedAt,
 		UpdatedAt:  session.UpdatedAt,
 		IsArchived: session.IsArchived,
+		IsPinned:   session.IsPinned,
+	}
+	lastActivityAt := session.UpdatedAt
+	response.LastActivityAt = &lastActivityAt
+	if session.FolderID != nil {
+		folderID := session.FolderID.String()
+		response.FolderID = &folderID
+	}
+	if len(session.Tags) > 0 {
+		response.Tags = toTagResponses(session.Tags)
 	}
+	return response
 }
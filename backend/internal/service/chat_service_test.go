@@ -0,0 +1,27 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessagePreview(t *testing.T) {
+	if got := messagePreview("  Hello,\n\n  world\t! "); got != "Hello, world !" {
+		t.Errorf("messagePreview() = %q, want whitespace collapsed", got)
+	}
+
+	exact := strings.Repeat("я", chatPreviewLength)
+	if got := messagePreview(exact); got != exact {
+		t.Errorf("messagePreview() truncated a preview of %d characters", chatPreviewLength)
+	}
+
+	// Long text is cut by characters, not bytes, and marked as truncated
+	got := messagePreview(strings.Repeat("я", chatPreviewLength-1) + " " + strings.Repeat("б", 50))
+	if want := strings.Repeat("я", chatPreviewLength-1) + "…"; got != want {
+		t.Errorf("messagePreview() = %q, want %q", got, want)
+	}
+
+	if got := messagePreview(""); got != "" {
+		t.Errorf("messagePreview(\"\") = %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned for malformed pagination cursors
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// chatCursor is the encoded position after the last chat session of a page
+type chatCursor struct {
+	IsPinned  bool      `json:"p,omitempty"`
+	UpdatedAt time.Time `json:"u"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// messageCursor is the encoded position after the last message of a page
+type messageCursor struct {
+	SequenceNumber int `json:"s"`
+}
+
+// encodeCursor encodes a cursor as an opaque URL-safe string
+func encodeCursor(cursor interface{}) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor
+func decodeCursor(value string, cursor interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
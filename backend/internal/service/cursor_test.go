@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := chatCursor{IsPinned: true, UpdatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+	encoded := encodeCursor(want)
+	if strings.ContainsAny(encoded, "+/=") {
+		t.Fatalf("encodeCursor() = %q, want a URL-safe string without padding", encoded)
+	}
+
+	var got chatCursor
+	if err := decodeCursor(encoded, &got); err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if got.IsPinned != want.IsPinned || !got.UpdatedAt.Equal(want.UpdatedAt) || got.ID != want.ID {
+		t.Fatalf("decodeCursor() = %+v, want %+v", got, want)
+	}
+
+	var message messageCursor
+	if err := decodeCursor(encodeCursor(messageCursor{SequenceNumber: 42}), &message); err != nil || message.SequenceNumber != 42 {
+		t.Fatalf("decodeCursor() = %+v, %v; want sequence number 42", message, err)
+	}
+}
+
+func TestDecodeCursorRejectsMalformedInput(t *testing.T) {
+	for _, value := range []string{"not base64!", "bm90IGpzb24", "eyJzIjoieCJ9"} {
+		var cursor messageCursor
+		if err := decodeCursor(value, &cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", value, err)
+		}
+	}
+}
+
+func TestListChatSessionsRejectsInvalidCursor(t *testing.T) {
+	// The cursor is decoded before the repository is used
+	s := &ChatService{}
+	if _, err := s.ListChatSessions(uuid.New(), repository.ChatListFilter{}, "%%%", 10); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("ListChatSessions() error = %v, want ErrInvalidCursor", err)
+	}
+}
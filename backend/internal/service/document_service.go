@@ -0,0 +1,299 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/textextract"
+	"github.com/llmchatbot/backend/pkg/textsplit"
+)
+
+// documentStore stores uploaded documents and their chunks
+type documentStore interface {
+	CreateWithChunks(document *model.Document, chunks []model.DocumentChunk) error
+	GetByIDAndUserID(id, userID uuid.UUID) (*model.Document, error)
+	GetByUserID(userID uuid.UUID, chatSessionID *uuid.UUID) ([]model.Document, error)
+	GetForRetrieval(userID, chatSessionID uuid.UUID, embeddingModel string) ([]model.Document, error)
+	GetChunksByIDs(ids []uuid.UUID) ([]model.DocumentChunk, error)
+	GetChunk(documentID, chunkID uuid.UUID) (*model.DocumentChunk, error)
+	Delete(id, userID uuid.UUID) error
+}
+
+// documentChatStore looks up the chat sessions documents are attached to
+type documentChatStore interface {
+	GetByIDAndUserID(id, userID uuid.UUID) (*model.ChatSession, error)
+}
+
+// DocumentService handles document upload and retrieval business logic
+type DocumentService struct {
+	documentRepo documentStore
+	chatRepo     documentChatStore
+	embedder     Embedder
+	index        VectorIndex
+	cfg          config.RAGConfig
+}
+
+// NewDocumentService creates a new document service
+func NewDocumentService(
+	documentRepo *repository.DocumentRepository,
+	chatRepo *repository.ChatRepository,
+	embedder Embedder,
+	index VectorIndex,
+	cfg *config.Config,
+) *DocumentService {
+	return &DocumentService{
+		documentRepo: documentRepo,
+		chatRepo:     chatRepo,
+		embedder:     embedder,
+		index:        index,
+		cfg:          cfg.RAG,
+	}
+}
+
+// scoredChunk is a document chunk ranked against a query
+type scoredChunk struct {
+	chunk    model.DocumentChunk
+	filename string
+	score    float64
+}
+
+// UploadDocument extracts, chunks and embeds a document and stores it
+// in a chat session or, if chatSessionID is nil, in the user's personal library
+func (s *DocumentService) UploadDocument(userID uuid.UUID, chatSessionID *uuid.UUID, filename string, data []byte) (*dto.DocumentResponse, error) {
+	if int64(len(data)) > s.cfg.MaxUploadSize {
+		return nil, fmt.Errorf("document exceeds maximum size of %d bytes", s.cfg.MaxUploadSize)
+	}
+
+	// Verify chat session exists and belongs to user
+	if chatSessionID != nil {
+		if _, err := s.chatRepo.GetByIDAndUserID(*chatSessionID, userID); err != nil {
+			return nil, errors.New("chat session not found")
+		}
+	}
+
+	filename = filepath.Base(filename)
+	if len(filename) > 255 {
+		filename = filename[len(filename)-255:]
+	}
+
+	mimeType := textextract.DetectMimeType(filename, data)
+	text, err := textextract.Extract(mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pieces := textsplit.Split(text, s.cfg.ChunkSize, s.cfg.ChunkOverlap)
+	if len(pieces) == 0 {
+		return nil, errors.New("document contains no text")
+	}
+
+	chunks := make([]model.DocumentChunk, len(pieces))
+	for i, piece := range pieces {
+		embedding, err := s.embedder.Embed(piece)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed document: %w", err)
+		}
+		chunks[i] = model.DocumentChunk{
+			ChunkIndex: i,
+			Content:    piece,
+			Embedding:  embedding,
+		}
+	}
+
+	document := &model.Document{
+		UserID:         userID,
+		ChatSessionID:  chatSessionID,
+		Filename:       filename,
+		MimeType:       mimeType,
+		Size:           int64(len(data)),
+		ChunkCount:     len(chunks),
+		EmbeddingModel: s.embedder.Name(),
+	}
+
+	if err := s.documentRepo.CreateWithChunks(document, chunks); err != nil {
+		return nil, err
+	}
+
+	if err := s.index.Add(chunks); err != nil {
+		// Log error but don't fail the upload: the index is rebuilt from the database on restart
+		log.Printf("Failed to add document %s to vector index: %v", document.ID, err)
+	}
+
+	return s.toDocumentResponse(document), nil
+}
+
+// GetDocuments retrieves documents of a user, optionally limited to a chat session
+func (s *DocumentService) GetDocuments(userID uuid.UUID, chatSessionID *uuid.UUID) ([]dto.DocumentResponse, error) {
+	documents, err := s.documentRepo.GetByUserID(userID, chatSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.DocumentResponse, len(documents))
+	for i, document := range documents {
+		responses[i] = *s.toDocumentResponse(&document)
+	}
+
+	return responses, nil
+}
+
+// GetDocument retrieves a document by ID
+func (s *DocumentService) GetDocument(documentID, userID uuid.UUID) (*dto.DocumentResponse, error) {
+	document, err := s.documentRepo.GetByIDAndUserID(documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDocumentResponse(document), nil
+}
+
+// GetDocumentChunk retrieves a single chunk of a document (used to resolve citations)
+func (s *DocumentService) GetDocumentChunk(documentID, chunkID, userID uuid.UUID) (*dto.DocumentChunkResponse, error) {
+	if _, err := s.documentRepo.GetByIDAndUserID(documentID, userID); err != nil {
+		return nil, err
+	}
+
+	chunk, err := s.documentRepo.GetChunk(documentID, chunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DocumentChunkResponse{
+		ID:         chunk.ID.String(),
+		DocumentID: chunk.DocumentID.String(),
+		ChunkIndex: chunk.ChunkIndex,
+		Content:    chunk.Content,
+	}, nil
+}
+
+// DeleteDocument permanently deletes a document
+func (s *DocumentService) DeleteDocument(documentID, userID uuid.UUID) error {
+	if err := s.documentRepo.Delete(documentID, userID); err != nil {
+		return err
+	}
+
+	if err := s.index.Remove(documentID); err != nil {
+		log.Printf("Failed to remove document %s from vector index: %v", documentID, err)
+	}
+
+	return nil
+}
+
+// PruneIndex drops chunks of documents deleted together with their chat or
+// their user from the vector index
+func (s *DocumentService) PruneIndex() error {
+	return s.index.Prune()
+}
+
+// AugmentPrompt retrieves the most relevant document chunks for a message and
+// returns the prompt extended with them together with citations for the chunks used.
+// The message is returned unchanged if the user has no documents available in the session.
+func (s *DocumentService) AugmentPrompt(userID, sessionID uuid.UUID, message string) (string, []dto.CitationResponse, error) {
+	results, err := s.retrieve(userID, sessionID, message)
+	if err != nil || len(results) == 0 {
+		return message, nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("Use the following excerpts from the user's documents to answer the question. ")
+	b.WriteString("Cite the excerpts you use by their number, e.g. [1]. ")
+	b.WriteString("If the excerpts are not relevant, answer from your own knowledge.\n\n")
+
+	citations := make([]dto.CitationResponse, len(results))
+	for i, result := range results {
+		fmt.Fprintf(&b, "[%d] (%s)\n%s\n\n", i+1, result.filename, result.chunk.Content)
+		citations[i] = dto.CitationResponse{
+			Index:      i + 1,
+			ChunkID:    result.chunk.ID.String(),
+			DocumentID: result.chunk.DocumentID.String(),
+			Filename:   result.filename,
+			ChunkIndex: result.chunk.ChunkIndex,
+			Score:      result.score,
+		}
+	}
+
+	b.WriteString("Question: ")
+	b.WriteString(message)
+
+	return b.String(), citations, nil
+}
+
+// retrieve ranks chunks of the documents available in a chat session against a query
+func (s *DocumentService) retrieve(userID, sessionID uuid.UUID, query string) ([]scoredChunk, error) {
+	documents, err := s.documentRepo.GetForRetrieval(userID, sessionID, s.embedder.Name())
+	if err != nil || len(documents) == 0 {
+		return nil, err
+	}
+
+	filenames := make(map[uuid.UUID]string, len(documents))
+	documentIDs := make([]uuid.UUID, len(documents))
+	for i, document := range documents {
+		filenames[document.ID] = document.Filename
+		documentIDs[i] = document.ID
+	}
+
+	queryEmbedding, err := s.embedder.Embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	matches, err := s.index.Search(queryEmbedding, documentIDs, s.cfg.TopK, s.cfg.MinScore)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+
+	chunkIDs := make([]uuid.UUID, len(matches))
+	for i, match := range matches {
+		chunkIDs[i] = match.ID
+	}
+
+	chunks, err := s.documentRepo.GetChunksByIDs(chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	chunksByID := make(map[uuid.UUID]model.DocumentChunk, len(chunks))
+	for _, chunk := range chunks {
+		chunksByID[chunk.ID] = chunk
+	}
+
+	// Keep the ranking order of the index
+	results := make([]scoredChunk, 0, len(matches))
+	for _, match := range matches {
+		chunk, ok := chunksByID[match.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, scoredChunk{
+			chunk:    chunk,
+			filename: filenames[chunk.DocumentID],
+			score:    match.Score,
+		})
+	}
+
+	return results, nil
+}
+
+// toDocumentResponse converts a Document model to response DTO
+func (s *DocumentService) toDocumentResponse(document *model.Document) *dto.DocumentResponse {
+	response := &dto.DocumentResponse{
+		ID:         document.ID.String(),
+		Filename:   document.Filename,
+		MimeType:   document.MimeType,
+		Size:       document.Size,
+		ChunkCount: document.ChunkCount,
+		CreatedAt:  document.CreatedAt,
+	}
+	if document.ChatSessionID != nil {
+		response.ChatSessionID = document.ChatSessionID.String()
+	}
+	return response
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/pkg/vectorindex"
+)
+
+// fakeDocuments keeps documents and their chunks in memory
+type fakeDocuments struct {
+	documents map[uuid.UUID]*model.Document
+	chunks    map[uuid.UUID]model.DocumentChunk
+}
+
+func (f *fakeDocuments) CreateWithChunks(document *model.Document, chunks []model.DocumentChunk) error {
+	document.ID = uuid.New()
+	document.CreatedAt = time.Now()
+	f.documents[document.ID] = document
+	for i := range chunks {
+		chunks[i].ID = uuid.New()
+		chunks[i].DocumentID = document.ID
+		f.chunks[chunks[i].ID] = chunks[i]
+	}
+	return nil
+}
+
+func (f *fakeDocuments) GetByIDAndUserID(id, userID uuid.UUID) (*model.Document, error) {
+	if document, ok := f.documents[id]; ok && document.UserID == userID {
+		return document, nil
+	}
+	return nil, errors.New("document not found")
+}
+
+func (f *fakeDocuments) GetByUserID(userID uuid.UUID, chatSessionID *uuid.UUID) ([]model.Document, error) {
+	var documents []model.Document
+	for _, document := range f.documents {
+		if document.UserID != userID {
+			continue
+		}
+		if chatSessionID != nil && (document.ChatSessionID == nil || *document.ChatSessionID != *chatSessionID) {
+			continue
+		}
+		documents = append(documents, *document)
+	}
+	return documents, nil
+}
+
+func (f *fakeDocuments) GetForRetrieval(userID, chatSessionID uuid.UUID, embeddingModel string) ([]model.Document, error) {
+	var documents []model.Document
+	for _, document := range f.documents {
+		if document.UserID != userID || document.EmbeddingModel != embeddingModel {
+			continue
+		}
+		if document.ChatSessionID != nil && *document.ChatSessionID != chatSessionID {
+			continue
+		}
+		documents = append(documents, *document)
+	}
+	return documents, nil
+}
+
+func (f *fakeDocuments) GetChunksByIDs(ids []uuid.UUID) ([]model.DocumentChunk, error) {
+	var chunks []model.DocumentChunk
+	for _, id := range ids {
+		if chunk, ok := f.chunks[id]; ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+func (f *fakeDocuments) GetChunk(documentID, chunkID uuid.UUID) (*model.DocumentChunk, error) {
+	if chunk, ok := f.chunks[chunkID]; ok && chunk.DocumentID == documentID {
+		return &chunk, nil
+	}
+	return nil, errors.New("document chunk not found")
+}
+
+func (f *fakeDocuments) Delete(id, userID uuid.UUID) error {
+	if _, err := f.GetByIDAndUserID(id, userID); err != nil {
+		return err
+	}
+	delete(f.documents, id)
+	for chunkID, chunk := range f.chunks {
+		if chunk.DocumentID == id {
+			delete(f.chunks, chunkID)
+		}
+	}
+	return nil
+}
+
+// fakeDocumentChats knows a fixed set of chat sessions
+type fakeDocumentChats map[uuid.UUID]uuid.UUID
+
+func (f fakeDocumentChats) GetByIDAndUserID(id, userID uuid.UUID) (*model.ChatSession, error) {
+	if owner, ok := f[id]; ok && owner == userID {
+		return &model.ChatSession{ID: id, UserID: userID}, nil
+	}
+	return nil, errors.New("chat session not found")
+}
+
+func newTestDocumentService(chats fakeDocumentChats) (*DocumentService, *fakeDocuments) {
+	documents := &fakeDocuments{
+		documents: make(map[uuid.UUID]*model.Document),
+		chunks:    make(map[uuid.UUID]model.DocumentChunk),
+	}
+	s := &DocumentService{
+		documentRepo: documents,
+		chatRepo:     chats,
+		embedder:     NewLocalEmbedder(256),
+		index:        &MemoryVectorIndex{index: vectorindex.New()},
+		cfg: config.RAGConfig{
+			ChunkSize:     120,
+			ChunkOverlap:  20,
+			TopK:          2,
+			MinScore:      0.1,
+			MaxUploadSize: 4096,
+		},
+	}
+	return s, documents
+}
+
+const testPolicy = `Refund policy
+
+Refunds are issued within fourteen days of purchase. Customers must keep the receipt.
+
+Shipping policy
+
+Parcels are shipped by courier. Delivery to remote islands takes up to three weeks.
+
+Warranty policy
+
+Laptops carry a two year warranty covering battery and keyboard defects.`
+
+func TestUploadDocumentChunksAndEmbeds(t *testing.T) {
+	userID, chatID := uuid.New(), uuid.New()
+	s, documents := newTestDocumentService(fakeDocumentChats{chatID: userID})
+
+	response, err := s.UploadDocument(userID, &chatID, "../../policy.md", []byte(testPolicy))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if response.Filename != "policy.md" || response.MimeType != "text/markdown" || response.ChatSessionID != chatID.String() {
+		t.Fatalf("UploadDocument() = %+v", response)
+	}
+	if response.ChunkCount < 3 || response.ChunkCount != len(documents.chunks) {
+		t.Fatalf("ChunkCount = %d with %d stored chunks, want at least 3", response.ChunkCount, len(documents.chunks))
+	}
+
+	seen := make(map[int]bool)
+	for _, chunk := range documents.chunks {
+		if len([]rune(chunk.Content)) > 120 {
+			t.Errorf("chunk %d has %d characters, want at most 120", chunk.ChunkIndex, len([]rune(chunk.Content)))
+		}
+		if len(chunk.Embedding) != 256 {
+			t.Errorf("chunk %d embedding has %d dimensions, want 256", chunk.ChunkIndex, len(chunk.Embedding))
+		}
+		seen[chunk.ChunkIndex] = true
+	}
+	for i := 0; i < response.ChunkCount; i++ {
+		if !seen[i] {
+			t.Errorf("chunk index %d is missing", i)
+		}
+	}
+}
+
+func TestUploadDocumentRejections(t *testing.T) {
+	userID, chatID := uuid.New(), uuid.New()
+	s, documents := newTestDocumentService(fakeDocumentChats{chatID: userID})
+
+	if _, err := s.UploadDocument(userID, &chatID, "big.txt", []byte(strings.Repeat("a", 4097))); err == nil {
+		t.Error("UploadDocument() accepted a document over the size limit")
+	}
+	otherChat := uuid.New()
+	if _, err := s.UploadDocument(userID, &otherChat, "notes.txt", []byte("text")); err == nil {
+		t.Error("UploadDocument() accepted a chat of another user")
+	}
+	if _, err := s.UploadDocument(userID, nil, "blank.txt", []byte(" \n\n ")); err == nil {
+		t.Error("UploadDocument() accepted a document without text")
+	}
+	if _, err := s.UploadDocument(userID, nil, "image.bin", []byte{0xff, 0xfe, 0x00}); err == nil {
+		t.Error("UploadDocument() accepted an unsupported document")
+	}
+	if len(documents.documents) != 0 {
+		t.Fatalf("%d documents stored after rejected uploads, want 0", len(documents.documents))
+	}
+}
+
+func TestAugmentPromptRetrievesRelevantChunks(t *testing.T) {
+	userID, chatID, otherChatID := uuid.New(), uuid.New(), uuid.New()
+	s, _ := newTestDocumentService(fakeDocumentChats{chatID: userID, otherChatID: userID})
+
+	// A library document is available in every chat, a chat document only in its own chat
+	if _, err := s.UploadDocument(userID, nil, "policy.md", []byte(testPolicy)); err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if _, err := s.UploadDocument(userID, &otherChatID, "menu.txt", []byte("Refunds for lunch are issued by the canteen cashier.")); err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+
+	prompt, citations, err := s.AugmentPrompt(userID, chatID, "How many days until refunds are issued?")
+	if err != nil {
+		t.Fatalf("AugmentPrompt() error = %v", err)
+	}
+	if len(citations) == 0 {
+		t.Fatal("AugmentPrompt() returned no citations")
+	}
+	if citations[0].Filename != "policy.md" || citations[0].Index != 1 {
+		t.Fatalf("first citation = %+v, want policy.md", citations[0])
+	}
+	for i := 1; i < len(citations); i++ {
+		if citations[i].Score > citations[i-1].Score {
+			t.Fatalf("citations are not ranked by score: %+v", citations)
+		}
+	}
+	for _, citation := range citations {
+		if citation.Filename == "menu.txt" {
+			t.Fatal("AugmentPrompt() used a document of another chat")
+		}
+	}
+	if !strings.Contains(prompt, "fourteen days") || !strings.HasSuffix(prompt, "Question: How many days until refunds are issued?") {
+		t.Fatalf("AugmentPrompt() prompt = %q", prompt)
+	}
+
+	// The cited chunk resolves to the stored content
+	documentID, _ := uuid.Parse(citations[0].DocumentID)
+	chunkID, _ := uuid.Parse(citations[0].ChunkID)
+	chunk, err := s.GetDocumentChunk(documentID, chunkID, userID)
+	if err != nil {
+		t.Fatalf("GetDocumentChunk() error = %v", err)
+	}
+	if !strings.Contains(chunk.Content, "fourteen days") {
+		t.Fatalf("GetDocumentChunk() content = %q", chunk.Content)
+	}
+	if _, err := s.GetDocumentChunk(documentID, chunkID, uuid.New()); err == nil {
+		t.Fatal("GetDocumentChunk() returned a chunk to another user")
+	}
+}
+
+func TestAugmentPromptWithoutDocuments(t *testing.T) {
+	s, _ := newTestDocumentService(fakeDocumentChats{})
+
+	prompt, citations, err := s.AugmentPrompt(uuid.New(), uuid.New(), "hello")
+	if err != nil || prompt != "hello" || citations != nil {
+		t.Fatalf("AugmentPrompt() = %q, %v, %v; want the message unchanged", prompt, citations, err)
+	}
+}
+
+func TestDeleteDocumentRemovesFromIndex(t *testing.T) {
+	userID, chatID := uuid.New(), uuid.New()
+	s, _ := newTestDocumentService(fakeDocumentChats{chatID: userID})
+
+	response, err := s.UploadDocument(userID, nil, "policy.md", []byte(testPolicy))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	documentID, _ := uuid.Parse(response.ID)
+	if err := s.DeleteDocument(documentID, userID); err != nil {
+		t.Fatalf("DeleteDocument() error = %v", err)
+	}
+
+	if n := s.index.(*MemoryVectorIndex).index.Len(); n != 0 {
+		t.Fatalf("index holds %d chunks after delete, want 0", n)
+	}
+	if _, citations, _ := s.AugmentPrompt(userID, chatID, "refunds"); len(citations) != 0 {
+		t.Fatalf("AugmentPrompt() cited a deleted document: %+v", citations)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/pkg/hashembed"
+)
+
+// Embedder converts text into embedding vectors
+type Embedder interface {
+	// Embed returns the embedding vector for the given text
+	Embed(text string) ([]float32, error)
+	// Name identifies the embedding model; vectors of different models are not comparable
+	Name() string
+}
+
+// NewEmbedder creates the embedder selected in configuration
+func NewEmbedder(cfg *config.Config) Embedder {
+	if cfg.RAG.Embedder == "local" {
+		return NewLocalEmbedder(cfg.RAG.LocalDimensions)
+	}
+	return NewRemoteEmbedder(cfg)
+}
+
+// RemoteEmbedder computes embeddings with the LLM service
+type RemoteEmbedder struct {
+	client *http.Client
+	llmURL string
+}
+
+// NewRemoteEmbedder creates a new embedder backed by the LLM service
+func NewRemoteEmbedder(cfg *config.Config) *RemoteEmbedder {
+	return &RemoteEmbedder{
+		client: &http.Client{
+			Timeout: cfg.LLM.Timeout,
+		},
+		llmURL: cfg.LLM.BaseURL,
+	}
+}
+
+// embeddingRequest represents request to LLM service embeddings endpoint
+type embeddingRequest struct {
+	Text string `json:"text"`
+}
+
+// embeddingResponse represents response from LLM service embeddings endpoint
+type embeddingResponse struct {
+	Embedding  []float32 `json:"embedding"`
+	Dimensions int       `json:"dimensions"`
+}
+
+// Embed returns the embedding vector for the given text
+func (e *RemoteEmbedder) Embed(text string) ([]float32, error) {
+	jsonData, err := json.Marshal(embeddingRequest{Text: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := e.client.Post(fmt.Sprintf("%s/api/v1/embeddings/", e.llmURL), "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LLM service returned status %d", resp.StatusCode)
+	}
+
+	var embResp embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	if len(embResp.Embedding) == 0 {
+		return nil, fmt.Errorf("LLM service returned empty embedding")
+	}
+
+	return embResp.Embedding, nil
+}
+
+// Name identifies the embedding model
+func (e *RemoteEmbedder) Name() string {
+	return "llm-service"
+}
+
+// LocalEmbedder computes feature-hashing embeddings in process.
+// It needs no model weights, so retrieval works without the LLM service.
+type LocalEmbedder struct {
+	dims int
+}
+
+// NewLocalEmbedder creates a new local embedder producing vectors of the given dimensionality
+func NewLocalEmbedder(dims int) *LocalEmbedder {
+	if dims <= 0 {
+		dims = 512
+	}
+	return &LocalEmbedder{dims: dims}
+}
+
+// Embed returns the embedding vector for the given text
+func (e *LocalEmbedder) Embed(text string) ([]float32, error) {
+	return hashembed.Embed(text, e.dims), nil
+}
+
+// Name identifies the embedding model
+func (e *LocalEmbedder) Name() string {
+	return fmt.Sprintf("hashing-%d", e.dims)
+}
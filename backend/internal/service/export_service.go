@@ -0,0 +1,305 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// Export formats
+const (
+	ExportFormatMarkdown = "md"
+	ExportFormatJSON     = "json"
+	ExportFormatHTML     = "html"
+)
+
+// ExportedFile is a rendered chat export
+type ExportedFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// exportChatStore loads the chat sessions to export
+type exportChatStore interface {
+	GetByUserID(userID uuid.UUID, includeArchived bool) ([]model.ChatSession, error)
+	GetWithMessages(id, userID uuid.UUID) (*model.ChatSession, error)
+}
+
+// ExportService handles chat export
+type ExportService struct {
+	chatRepo exportChatStore
+}
+
+// NewExportService creates a new export service
+func NewExportService(chatRepo *repository.ChatRepository) *ExportService {
+	return &ExportService{
+		chatRepo: chatRepo,
+	}
+}
+
+// ValidateExportFormat checks that an export format is supported
+func ValidateExportFormat(format string) error {
+	switch format {
+	case ExportFormatMarkdown, ExportFormatJSON, ExportFormatHTML:
+		return nil
+	default:
+		return errors.New("format must be md, json or html")
+	}
+}
+
+// ExportChat renders a chat session with all messages in the given format
+func (s *ExportService) ExportChat(sessionID, userID uuid.UUID, format string) (*ExportedFile, error) {
+	if err := ValidateExportFormat(format); err != nil {
+		return nil, err
+	}
+
+	session, err := s.chatRepo.GetWithMessages(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderExport(toChatExport(session), format, exportFilename(session))
+}
+
+// ExportAll writes all chat sessions of a user as a zip archive with one file per chat
+func (s *ExportService) ExportAll(userID uuid.UUID, format string, includeArchived bool, w io.Writer) error {
+	if err := ValidateExportFormat(format); err != nil {
+		return err
+	}
+
+	sessions, err := s.chatRepo.GetByUserID(userID, includeArchived)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, session := range sessions {
+		// Load messages one chat at a time to keep memory bounded
+		full, err := s.chatRepo.GetWithMessages(session.ID, userID)
+		if err != nil {
+			return err
+		}
+
+		file, err := renderExport(toChatExport(full), format, exportFilename(full))
+		if err != nil {
+			return err
+		}
+
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.Filename,
+			Method:   zip.Deflate,
+			Modified: full.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// toChatExport converts a chat session with messages to the export representation
+func toChatExport(session *model.ChatSession) *dto.ChatExport {
+	export := &dto.ChatExport{
+		Format:     dto.ChatExportFormat,
+		ExportedAt: time.Now().UTC(),
+		Title:      session.Title,
+		ModelUsed:  session.ModelUsed,
+		CreatedAt:  session.CreatedAt,
+		UpdatedAt:  session.UpdatedAt,
+		IsArchived: session.IsArchived,
+		Messages:   make([]dto.ChatExportMessage, len(session.Messages)),
+	}
+
+	for i, msg := range session.Messages {
+		export.Messages[i] = dto.ChatExportMessage{
+			SequenceNumber: msg.SequenceNumber,
+			Role:           msg.Role,
+			Content:        msg.Content,
+			Tokens:         msg.Tokens,
+			IsIncomplete:   msg.IsIncomplete,
+			CreatedAt:      msg.CreatedAt,
+		}
+		for _, attachment := range msg.Attachments {
+			export.Messages[i].Attachments = append(export.Messages[i].Attachments, dto.ChatExportAttachment{
+				Filename: attachment.Filename,
+				MimeType: attachment.MimeType,
+				Size:     attachment.Size,
+				Checksum: attachment.Checksum,
+			})
+		}
+	}
+
+	return export
+}
+
+// renderExport renders a chat export in the given format
+func renderExport(export *dto.ChatExport, format, basename string) (*ExportedFile, error) {
+	switch format {
+	case ExportFormatJSON:
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &ExportedFile{Filename: basename + ".json", ContentType: "application/json", Data: data}, nil
+	case ExportFormatHTML:
+		return &ExportedFile{Filename: basename + ".html", ContentType: "text/html; charset=utf-8", Data: renderHTML(export)}, nil
+	default:
+		return &ExportedFile{Filename: basename + ".md", ContentType: "text/markdown; charset=utf-8", Data: renderMarkdown(export)}, nil
+	}
+}
+
+// renderMarkdown renders a chat export as Markdown
+func renderMarkdown(export *dto.ChatExport) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", export.Title)
+	fmt.Fprintf(&b, "- Model: %s\n", export.ModelUsed)
+	fmt.Fprintf(&b, "- Created: %s\n", export.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Updated: %s\n", export.UpdatedAt.UTC().Format(time.RFC3339))
+	if export.IsArchived {
+		b.WriteString("- Archived\n")
+	}
+
+	for _, msg := range export.Messages {
+		fmt.Fprintf(&b, "\n---\n\n### %s · %s\n\n", roleTitle(msg.Role), msg.CreatedAt.UTC().Format(time.RFC3339))
+		b.WriteString(msg.Content)
+		b.WriteString("\n")
+
+		if len(msg.Attachments) > 0 {
+			b.WriteString("\n")
+		}
+		for _, attachment := range msg.Attachments {
+			fmt.Fprintf(&b, "- Attachment: %s (%s, %d bytes)\n", attachment.Filename, attachment.MimeType, attachment.Size)
+		}
+		if details := messageDetails(msg); details != "" {
+			fmt.Fprintf(&b, "\n_%s_\n", details)
+		}
+	}
+
+	return b.Bytes()
+}
+
+// exportHTMLStyle is embedded in HTML exports so they are self-contained
+const exportHTMLStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;max-width:860px;margin:2rem auto;padding:0 1rem;color:#1f2328;line-height:1.5}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1.5rem}
+dl{display:grid;grid-template-columns:max-content auto;gap:.25rem 1rem;color:#59636e}
+dd{margin:0}
+.message{border:1px solid #d0d7de;border-radius:8px;padding:.75rem 1rem;margin:1rem 0}
+.message.user{background:#f6f8fa}
+.meta{font-size:.85rem;color:#59636e;margin-bottom:.5rem}
+.content{white-space:pre-wrap;word-wrap:break-word}
+.incomplete{color:#9a6700}
+ul.attachments{font-size:.85rem;color:#59636e;margin:.5rem 0 0;padding-left:1.25rem}`
+
+// renderHTML renders a chat export as a standalone HTML page.
+// All user and model content is escaped; the page has no scripts or external resources.
+func renderHTML(export *dto.ChatExport) []byte {
+	var b bytes.Buffer
+	esc := html.EscapeString
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta http-equiv=\"Content-Security-Policy\" content=\"default-src 'none'; style-src 'unsafe-inline'\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n", esc(export.Title), exportHTMLStyle)
+
+	fmt.Fprintf(&b, "<header>\n<h1>%s</h1>\n<dl>\n", esc(export.Title))
+	fmt.Fprintf(&b, "<dt>Model</dt><dd>%s</dd>\n", esc(export.ModelUsed))
+	fmt.Fprintf(&b, "<dt>Created</dt><dd>%s</dd>\n", export.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "<dt>Updated</dt><dd>%s</dd>\n", export.UpdatedAt.UTC().Format(time.RFC3339))
+	if export.IsArchived {
+		b.WriteString("<dt>Status</dt><dd>Archived</dd>\n")
+	}
+	b.WriteString("</dl>\n</header>\n")
+
+	for _, msg := range export.Messages {
+		fmt.Fprintf(&b, "<section class=\"message %s\">\n", esc(msg.Role))
+		fmt.Fprintf(&b, "<div class=\"meta\"><strong>%s</strong> · %s", esc(roleTitle(msg.Role)), msg.CreatedAt.UTC().Format(time.RFC3339))
+		if details := messageDetails(msg); details != "" {
+			class := ""
+			if msg.IsIncomplete {
+				class = " class=\"incomplete\""
+			}
+			fmt.Fprintf(&b, " · <span%s>%s</span>", class, esc(details))
+		}
+		b.WriteString("</div>\n")
+		fmt.Fprintf(&b, "<div class=\"content\">%s</div>\n", esc(msg.Content))
+
+		if len(msg.Attachments) > 0 {
+			b.WriteString("<ul class=\"attachments\">\n")
+			for _, attachment := range msg.Attachments {
+				fmt.Fprintf(&b, "<li>%s (%s, %d bytes)</li>\n", esc(attachment.Filename), esc(attachment.MimeType), attachment.Size)
+			}
+			b.WriteString("</ul>\n")
+		}
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// roleTitle returns the display name of a message role
+func roleTitle(role string) string {
+	switch role {
+	case model.MessageRoleUser:
+		return "User"
+	case model.MessageRoleAssistant:
+		return "Assistant"
+	case model.MessageRoleSystem:
+		return "System"
+	default:
+		return role
+	}
+}
+
+// messageDetails describes token count and completeness of a message
+func messageDetails(msg dto.ChatExportMessage) string {
+	var details []string
+	if msg.Tokens > 0 {
+		details = append(details, fmt.Sprintf("%d tokens", msg.Tokens))
+	}
+	if msg.IsIncomplete {
+		details = append(details, "incomplete")
+	}
+	return strings.Join(details, ", ")
+}
+
+// exportFilename builds a file name (without extension) from the chat title and ID
+func exportFilename(session *model.ChatSession) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(session.Title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastDash = false
+		case !lastDash:
+			b.WriteRune('-')
+			lastDash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "chat"
+	}
+	return fmt.Sprintf("%s-%s", slug, session.ID.String()[:8])
+}
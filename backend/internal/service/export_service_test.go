@@ -0,0 +1,198 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+)
+
+// fakeExportChats keeps chat sessions with their messages in memory
+type fakeExportChats []*model.ChatSession
+
+func (f fakeExportChats) GetByUserID(userID uuid.UUID, includeArchived bool) ([]model.ChatSession, error) {
+	var sessions []model.ChatSession
+	for _, session := range f {
+		if session.UserID == userID && (includeArchived || !session.IsArchived) {
+			sessions = append(sessions, model.ChatSession{ID: session.ID, UserID: session.UserID, Title: session.Title})
+		}
+	}
+	return sessions, nil
+}
+
+func (f fakeExportChats) GetWithMessages(id, userID uuid.UUID) (*model.ChatSession, error) {
+	for _, session := range f {
+		if session.ID == id && session.UserID == userID {
+			return session, nil
+		}
+	}
+	return nil, errors.New("chat session not found")
+}
+
+// newTestExportChat creates a chat whose title and messages contain markup
+func newTestExportChat(userID uuid.UUID) *model.ChatSession {
+	created := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	return &model.ChatSession{
+		ID:        uuid.MustParse("12345678-0000-0000-0000-000000000001"),
+		UserID:    userID,
+		Title:     "Deploy <script>alert(1)</script> notes",
+		ModelUsed: "qwen2.5-3b",
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Minute),
+		Messages: []model.Message{
+			{SequenceNumber: 1, Role: model.MessageRoleUser, Content: "How do I <b>deploy</b>?", CreatedAt: created,
+				Attachments: []model.Attachment{{Filename: "log\".txt", MimeType: "text/plain", Size: 42}}},
+			{SequenceNumber: 2, Role: model.MessageRoleAssistant, Content: "Run `make deploy`", Tokens: 12, IsIncomplete: true, CreatedAt: created.Add(time.Second)},
+		},
+	}
+}
+
+func TestExportChatJSON(t *testing.T) {
+	userID := uuid.New()
+	s := &ExportService{chatRepo: fakeExportChats{newTestExportChat(userID)}}
+
+	file, err := s.ExportChat(uuid.MustParse("12345678-0000-0000-0000-000000000001"), userID, ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("ExportChat() error = %v", err)
+	}
+	if file.Filename != "deploy-script-alert-1-script-notes-12345678.json" || file.ContentType != "application/json" {
+		t.Errorf("file = %s (%s)", file.Filename, file.ContentType)
+	}
+
+	var export dto.ChatExport
+	if err := json.Unmarshal(file.Data, &export); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	if export.ModelUsed != "qwen2.5-3b" || len(export.Messages) != 2 {
+		t.Fatalf("export = %+v", export)
+	}
+	assistant := export.Messages[1]
+	if assistant.Role != model.MessageRoleAssistant || assistant.Tokens != 12 || !assistant.IsIncomplete || assistant.SequenceNumber != 2 {
+		t.Errorf("assistant message = %+v", assistant)
+	}
+	if len(export.Messages[0].Attachments) != 1 || export.Messages[0].Attachments[0].Size != 42 {
+		t.Errorf("attachments = %+v", export.Messages[0].Attachments)
+	}
+
+	// Chats of other users are not exported
+	if _, err := s.ExportChat(uuid.MustParse("12345678-0000-0000-0000-000000000001"), uuid.New(), ExportFormatJSON); err == nil {
+		t.Error("ExportChat() exported a chat of another user")
+	}
+}
+
+func TestExportChatHTMLIsEscaped(t *testing.T) {
+	page := string(renderHTML(toChatExport(newTestExportChat(uuid.New()))))
+
+	for _, unsafe := range []string{"<script>", "<b>", "log\".txt"} {
+		if strings.Contains(page, unsafe) {
+			t.Errorf("HTML export contains unescaped %q", unsafe)
+		}
+	}
+	for _, want := range []string{
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"How do I &lt;b&gt;deploy&lt;/b&gt;?",
+		"log&#34;.txt",
+		"12 tokens, incomplete",
+		// Self-contained: nothing is loaded from elsewhere
+		"default-src 'none'",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML export does not contain %q", want)
+		}
+	}
+	for _, external := range []string{"<script", "<link", "src=", "href="} {
+		if strings.Contains(page, external) {
+			t.Errorf("HTML export references %q", external)
+		}
+	}
+}
+
+func TestExportChatMarkdown(t *testing.T) {
+	page := string(renderMarkdown(toChatExport(newTestExportChat(uuid.New()))))
+	for _, want := range []string{
+		"# Deploy <script>alert(1)</script> notes",
+		"- Model: qwen2.5-3b",
+		"### User · 2024-03-10T12:00:00Z",
+		"- Attachment: log\".txt (text/plain, 42 bytes)",
+		"### Assistant · 2024-03-10T12:00:01Z",
+		"_12 tokens, incomplete_",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Markdown export does not contain %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestExportAll(t *testing.T) {
+	userID := uuid.New()
+	archived := &model.ChatSession{ID: uuid.New(), UserID: userID, Title: "Old", IsArchived: true}
+	other := &model.ChatSession{ID: uuid.New(), UserID: uuid.New(), Title: "Someone else's"}
+	s := &ExportService{chatRepo: fakeExportChats{newTestExportChat(userID), archived, other}}
+
+	files := func(includeArchived bool) []string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := s.ExportAll(userID, ExportFormatMarkdown, includeArchived, &buf); err != nil {
+			t.Fatalf("ExportAll() error = %v", err)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("export is not a zip: %v", err)
+		}
+		var names []string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+			reader, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := io.ReadAll(reader); !bytes.HasPrefix(data, []byte("# ")) {
+				t.Errorf("%s is not a Markdown export", file.Name)
+			}
+			reader.Close()
+		}
+		return names
+	}
+
+	if names := files(false); len(names) != 1 || names[0] != "deploy-script-alert-1-script-notes-12345678.md" {
+		t.Errorf("files = %v, want the active chat", names)
+	}
+	if names := files(true); len(names) != 2 {
+		t.Errorf("files = %v, want the active and the archived chat", names)
+	}
+
+	if err := s.ExportAll(userID, "pdf", false, io.Discard); err == nil {
+		t.Error("ExportAll() accepted an unknown format")
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	id := uuid.MustParse("abcdef12-0000-0000-0000-000000000000")
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Привет, мир!", "привет-мир-abcdef12"},
+		{"  ../../etc/passwd  ", "etc-passwd-abcdef12"},
+		{"???", "chat-abcdef12"},
+		{"", "chat-abcdef12"},
+	}
+	for _, tt := range tests {
+		if got := exportFilename(&model.ChatSession{ID: id, Title: tt.title}); got != tt.want {
+			t.Errorf("exportFilename(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+
+	long := exportFilename(&model.ChatSession{ID: id, Title: strings.Repeat("a", 200)})
+	if len(long) > 70 {
+		t.Errorf("exportFilename() of a long title has %d bytes", len(long))
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// folderStore stores the folders of users
+type folderStore interface {
+	Create(folder *model.Folder) error
+	GetByIDAndUserID(id, userID uuid.UUID) (*model.Folder, error)
+	GetByUserID(userID uuid.UUID) ([]model.Folder, error)
+	Update(folder *model.Folder) error
+	Delete(id, userID uuid.UUID) error
+}
+
+// FolderService handles chat folders
+type FolderService struct {
+	folderRepo folderStore
+	chatRepo   *repository.ChatRepository
+}
+
+// NewFolderService creates a new folder service
+func NewFolderService(folderRepo *repository.FolderRepository, chatRepo *repository.ChatRepository) *FolderService {
+	return &FolderService{
+		folderRepo: folderRepo,
+		chatRepo:   chatRepo,
+	}
+}
+
+// GetFolders retrieves all folders of a user as a flat list; nesting is given by parent_id
+func (s *FolderService) GetFolders(userID uuid.UUID) ([]dto.FolderResponse, error) {
+	folders, err := s.folderRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.FolderResponse, len(folders))
+	for i := range folders {
+		responses[i] = *toFolderResponse(&folders[i])
+	}
+	return responses, nil
+}
+
+// CreateFolder creates a folder, optionally inside another folder
+func (s *FolderService) CreateFolder(userID uuid.UUID, req *dto.CreateFolderRequest) (*dto.FolderResponse, error) {
+	parentID, err := s.resolveFolderID(userID, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &model.Folder{
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     req.Name,
+	}
+	if err := s.folderRepo.Create(folder); err != nil {
+		return nil, err
+	}
+
+	return toFolderResponse(folder), nil
+}
+
+// UpdateFolder renames a folder and moves it under another parent
+func (s *FolderService) UpdateFolder(folderID, userID uuid.UUID, req *dto.UpdateFolderRequest) (*dto.FolderResponse, error) {
+	folder, err := s.folderRepo.GetByIDAndUserID(folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID, err := s.resolveFolderID(userID, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != nil {
+		if err := s.checkNotDescendant(userID, folder.ID, *parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	folder.Name = req.Name
+	folder.ParentID = parentID
+	if err := s.folderRepo.Update(folder); err != nil {
+		return nil, err
+	}
+
+	return toFolderResponse(folder), nil
+}
+
+// DeleteFolder deletes a folder with its subfolders; their chats are kept outside of folders
+func (s *FolderService) DeleteFolder(folderID, userID uuid.UUID) error {
+	return s.folderRepo.Delete(folderID, userID)
+}
+
+// MoveChats moves multiple chat sessions into a folder, or out of folders if folderID is nil
+func (s *FolderService) MoveChats(sessionIDs []uuid.UUID, userID uuid.UUID, folderID *string) error {
+	id, err := s.resolveFolderID(userID, folderID)
+	if err != nil {
+		return err
+	}
+	return s.chatRepo.MoveToFolder(sessionIDs, userID, id)
+}
+
+// resolveFolderID parses an optional folder ID and checks that the folder belongs to the user
+func (s *FolderService) resolveFolderID(userID uuid.UUID, folderID *string) (*uuid.UUID, error) {
+	if folderID == nil || *folderID == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(*folderID)
+	if err != nil {
+		return nil, errors.New("invalid folder ID")
+	}
+	if _, err := s.folderRepo.GetByIDAndUserID(id, userID); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// checkNotDescendant rejects moving a folder into itself or one of its subfolders
+func (s *FolderService) checkNotDescendant(userID, folderID, parentID uuid.UUID) error {
+	folders, err := s.folderRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	parents := make(map[uuid.UUID]*uuid.UUID, len(folders))
+	for _, folder := range folders {
+		parents[folder.ID] = folder.ParentID
+	}
+
+	// Walk up from the new parent; the walk is bounded in case of existing cycles
+	current := &parentID
+	for steps := 0; current != nil && steps <= len(folders); steps++ {
+		if *current == folderID {
+			return errors.New("cannot move a folder into itself or its subfolder")
+		}
+		current = parents[*current]
+	}
+	return nil
+}
+
+// toFolderResponse converts a Folder model to response DTO
+func toFolderResponse(folder *model.Folder) *dto.FolderResponse {
+	response := &dto.FolderResponse{
+		ID:        folder.ID.String(),
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+	if folder.ParentID != nil {
+		parentID := folder.ParentID.String()
+		response.ParentID = &parentID
+	}
+	return response
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+)
+
+// fakeFolders keeps folders in memory
+type fakeFolders map[uuid.UUID]*model.Folder
+
+func (f fakeFolders) Create(folder *model.Folder) error {
+	folder.ID = uuid.New()
+	f[folder.ID] = folder
+	return nil
+}
+
+func (f fakeFolders) GetByIDAndUserID(id, userID uuid.UUID) (*model.Folder, error) {
+	if folder, ok := f[id]; ok && folder.UserID == userID {
+		copied := *folder
+		return &copied, nil
+	}
+	return nil, errors.New("folder not found")
+}
+
+func (f fakeFolders) GetByUserID(userID uuid.UUID) ([]model.Folder, error) {
+	var folders []model.Folder
+	for _, folder := range f {
+		if folder.UserID == userID {
+			folders = append(folders, *folder)
+		}
+	}
+	return folders, nil
+}
+
+func (f fakeFolders) Update(folder *model.Folder) error {
+	f[folder.ID] = folder
+	return nil
+}
+
+func (f fakeFolders) Delete(id, userID uuid.UUID) error {
+	delete(f, id)
+	return nil
+}
+
+// createFolder creates a folder through the service and returns its ID
+func createFolder(t *testing.T, s *FolderService, userID uuid.UUID, name string, parentID *string) string {
+	t.Helper()
+	folder, err := s.CreateFolder(userID, &dto.CreateFolderRequest{Name: name, ParentID: parentID})
+	if err != nil {
+		t.Fatalf("CreateFolder(%q) error = %v", name, err)
+	}
+	return folder.ID
+}
+
+func TestUpdateFolderRejectsCycles(t *testing.T) {
+	userID := uuid.New()
+	s := &FolderService{folderRepo: fakeFolders{}}
+
+	// work > projects > archive
+	work := createFolder(t, s, userID, "work", nil)
+	projects := createFolder(t, s, userID, "projects", &work)
+	archive := createFolder(t, s, userID, "archive", &projects)
+	other := createFolder(t, s, userID, "other", nil)
+
+	for _, parent := range []string{work, projects, archive} {
+		parent := parent
+		_, err := s.UpdateFolder(uuid.MustParse(work), userID, &dto.UpdateFolderRequest{Name: "work", ParentID: &parent})
+		if err == nil {
+			t.Errorf("UpdateFolder() moved a folder into %s, which is itself or its subfolder", parent)
+		}
+	}
+
+	// Moving into an unrelated folder or to the top level is allowed
+	moved, err := s.UpdateFolder(uuid.MustParse(projects), userID, &dto.UpdateFolderRequest{Name: "projects", ParentID: &other})
+	if err != nil {
+		t.Fatalf("UpdateFolder() error = %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != other {
+		t.Fatalf("UpdateFolder() parent = %v, want %s", moved.ParentID, other)
+	}
+	if _, err := s.UpdateFolder(uuid.MustParse(archive), userID, &dto.UpdateFolderRequest{Name: "archive"}); err != nil {
+		t.Fatalf("UpdateFolder() to the top level error = %v", err)
+	}
+}
+
+func TestCheckNotDescendantStopsOnExistingCycles(t *testing.T) {
+	userID := uuid.New()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	folders := fakeFolders{
+		a: {ID: a, UserID: userID, ParentID: &b},
+		b: {ID: b, UserID: userID, ParentID: &a},
+		c: {ID: c, UserID: userID},
+	}
+	s := &FolderService{folderRepo: folders}
+
+	// A corrupted tree must not hang the check
+	if err := s.checkNotDescendant(userID, c, a); err != nil {
+		t.Fatalf("checkNotDescendant() error = %v", err)
+	}
+	if err := s.checkNotDescendant(userID, a, b); err == nil {
+		t.Fatal("checkNotDescendant() allowed moving a folder under its own child")
+	}
+}
+
+func TestFolderOfAnotherUserIsRejected(t *testing.T) {
+	s := &FolderService{folderRepo: fakeFolders{}}
+	foreign := createFolder(t, s, uuid.New(), "theirs", nil)
+
+	if _, err := s.CreateFolder(uuid.New(), &dto.CreateFolderRequest{Name: "mine", ParentID: &foreign}); err == nil {
+		t.Fatal("CreateFolder() accepted a parent folder of another user")
+	}
+	invalid := "not-a-uuid"
+	if _, err := s.CreateFolder(uuid.New(), &dto.CreateFolderRequest{Name: "mine", ParentID: &invalid}); err == nil {
+		t.Fatal("CreateFolder() accepted an invalid parent ID")
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// ErrGuestLimitReached is returned when a guest account exceeds one of its limits
+var ErrGuestLimitReached = errors.New("guest limit reached")
+
+// guestMessageWindow is the window of the daily guest message limit
+const guestMessageWindow = 24 * time.Hour
+
+// GuestService enforces the limits of guest accounts
+type GuestService struct {
+	userRepo *repository.UserRepository
+	chatRepo *repository.ChatRepository
+	cfg      config.GuestConfig
+}
+
+// NewGuestService creates a new guest service
+func NewGuestService(userRepo *repository.UserRepository, chatRepo *repository.ChatRepository, cfg *config.Config) *GuestService {
+	return &GuestService{
+		userRepo: userRepo,
+		chatRepo: chatRepo,
+		cfg:      cfg.Guest,
+	}
+}
+
+// CheckCreateChat checks that a guest can create another chat with the given model.
+// An empty model is the default model, which is checked when sending messages.
+func (s *GuestService) CheckCreateChat(userID uuid.UUID, modelName string) error {
+	if modelName != "" {
+		if err := s.checkModel(modelName); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.MaxChats > 0 {
+		count, err := s.chatRepo.CountByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.cfg.MaxChats) {
+			return fmt.Errorf("%w: guests can create up to %d chats, sign up to create more", ErrGuestLimitReached, s.cfg.MaxChats)
+		}
+	}
+	return nil
+}
+
+// CheckSendMessage checks that a guest can send a message to a chat with the given
+// model and counts the message against the daily limit
+func (s *GuestService) CheckSendMessage(userID uuid.UUID, modelName string) error {
+	if err := s.checkModel(modelName); err != nil {
+		return err
+	}
+
+	if s.cfg.MaxMessagesPerDay > 0 {
+		counted, err := s.userRepo.IncrementGuestMessages(userID, s.cfg.MaxMessagesPerDay, guestMessageWindow)
+		if err != nil {
+			return err
+		}
+		if !counted {
+			return fmt.Errorf("%w: guests can send up to %d messages per day, sign up to continue", ErrGuestLimitReached, s.cfg.MaxMessagesPerDay)
+		}
+	}
+	return nil
+}
+
+// MaxTokens returns the maximum tokens of a response generated for a guest (0 = no limit)
+func (s *GuestService) MaxTokens() int {
+	return s.cfg.MaxTokens
+}
+
+// checkModel checks that a model is available to guests
+func (s *GuestService) checkModel(modelName string) error {
+	if len(s.cfg.Models) == 0 {
+		return nil
+	}
+	for _, allowed := range s.cfg.Models {
+		if allowed == modelName {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: model %s is not available to guests", ErrGuestLimitReached, modelName)
+}
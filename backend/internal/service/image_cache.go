@@ -0,0 +1,96 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// imageCacheSize limits the total size of base64-encoded images kept in memory
+const imageCacheSize = 64 << 20
+
+// imageCache keeps recently sent images encoded for the LLM service, so chat
+// history does not re-read and re-encode every image on every turn.
+// Attachment content never changes, so entries are only evicted, never refreshed.
+type imageCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	order    *list.List // Front is most recently used
+	entries  map[uuid.UUID]*list.Element
+}
+
+// imageCacheEntry is an image cached for the user owning it
+type imageCacheEntry struct {
+	attachmentID uuid.UUID
+	userID       uuid.UUID
+	image        *ImageInput
+}
+
+// newImageCache creates an image cache holding up to maxBytes of encoded image data
+func newImageCache(maxBytes int) *imageCache {
+	return &imageCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[uuid.UUID]*list.Element),
+	}
+}
+
+// get returns a cached image of an attachment owned by the user
+func (c *imageCache) get(attachmentID, userID uuid.UUID) (*ImageInput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[attachmentID]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*imageCacheEntry)
+	if entry.userID != userID {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.image, true
+}
+
+// put caches an image, evicting the least recently used images over the size limit
+func (c *imageCache) put(attachmentID, userID uuid.UUID, image *ImageInput) {
+	size := len(image.Data)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeLocked(attachmentID)
+	c.entries[attachmentID] = c.order.PushFront(&imageCacheEntry{
+		attachmentID: attachmentID,
+		userID:       userID,
+		image:        image,
+	})
+	c.bytes += size
+
+	for c.bytes > c.maxBytes {
+		c.removeLocked(c.order.Back().Value.(*imageCacheEntry).attachmentID)
+	}
+}
+
+// remove drops the image of a deleted attachment
+func (c *imageCache) remove(attachmentID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeLocked(attachmentID)
+}
+
+func (c *imageCache) removeLocked(attachmentID uuid.UUID) {
+	element, ok := c.entries[attachmentID]
+	if !ok {
+		return
+	}
+	c.order.Remove(element)
+	delete(c.entries, attachmentID)
+	c.bytes -= len(element.Value.(*imageCacheEntry).image.Data)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestImageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newImageCache(10)
+	user := uuid.New()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	cache.put(a, user, &ImageInput{Data: "aaaa"})
+	cache.put(b, user, &ImageInput{Data: "bbbb"})
+	if _, ok := cache.get(a, user); !ok {
+		t.Fatal("a should be cached")
+	}
+
+	// Exceeds the limit: b is the least recently used
+	cache.put(c, user, &ImageInput{Data: "cccc"})
+	if _, ok := cache.get(b, user); ok {
+		t.Fatal("b should have been evicted")
+	}
+	if _, ok := cache.get(a, user); !ok {
+		t.Fatal("a should still be cached")
+	}
+	if cache.bytes != 8 {
+		t.Fatalf("bytes = %d, want 8", cache.bytes)
+	}
+
+	cache.remove(a)
+	if _, ok := cache.get(a, user); ok {
+		t.Fatal("a should have been removed")
+	}
+	if cache.bytes != 4 {
+		t.Fatalf("bytes = %d, want 4", cache.bytes)
+	}
+}
+
+func TestImageCacheChecksOwner(t *testing.T) {
+	cache := newImageCache(100)
+	owner, other := uuid.New(), uuid.New()
+	id := uuid.New()
+
+	cache.put(id, owner, &ImageInput{Data: "data"})
+	if _, ok := cache.get(id, other); ok {
+		t.Fatal("image should not be served to another user")
+	}
+	if image, ok := cache.get(id, owner); !ok || image.Data != "data" {
+		t.Fatal("image should be served to its owner")
+	}
+}
+
+func TestImageCacheSkipsOversizedImages(t *testing.T) {
+	cache := newImageCache(10)
+	id := uuid.New()
+
+	cache.put(id, uuid.New(), &ImageInput{Data: strings.Repeat("x", 11)})
+	if len(cache.entries) != 0 || cache.bytes != 0 {
+		t.Fatal("oversized image should not be cached")
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/chatgptexport"
+)
+
+// Import sources
+const (
+	ImportSourceChatGPT    = "chatgpt"
+	ImportSourceLLMChatbot = "llmchatbot"
+)
+
+// defaultImportTitle is used for conversations without a title
+const defaultImportTitle = "Imported chat"
+
+// importedChat is a parsed conversation ready to be stored
+type importedChat struct {
+	source   string
+	session  model.ChatSession
+	messages []model.Message
+	err      error // Parse error; the conversation is reported as failed
+}
+
+// ImportService handles chat import from export files
+type ImportService struct {
+	chatRepo         *repository.ChatRepository
+	chatEmbeddingSvc *ChatEmbeddingService
+	modelRegistry    *ModelRegistry
+	cfg              config.ImportConfig
+}
+
+// NewImportService creates a new import service
+func NewImportService(
+	chatRepo *repository.ChatRepository,
+	chatEmbeddingSvc *ChatEmbeddingService,
+	modelRegistry *ModelRegistry,
+	cfg *config.Config,
+) *ImportService {
+	return &ImportService{
+		chatRepo:         chatRepo,
+		chatEmbeddingSvc: chatEmbeddingSvc,
+		modelRegistry:    modelRegistry,
+		cfg:              cfg.Import,
+	}
+}
+
+// Import creates chat sessions from an export file.
+// Supported inputs are the ChatGPT conversations.json (or the export zip containing it)
+// and our own JSON export (a single chat, an array of chats or a zip of chat files).
+// Every conversation is imported independently and reported in the results.
+func (s *ImportService) Import(userID uuid.UUID, data []byte) (*dto.ImportResponse, error) {
+	if int64(len(data)) > s.cfg.MaxUploadSize {
+		return nil, fmt.Errorf("import file exceeds maximum size of %d bytes", s.cfg.MaxUploadSize)
+	}
+
+	chats, err := s.parseFile(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return nil, errors.New("import file contains no conversations")
+	}
+	if len(chats) > s.cfg.MaxChats {
+		return nil, fmt.Errorf("import file exceeds %d conversations", s.cfg.MaxChats)
+	}
+
+	response := &dto.ImportResponse{Results: make([]dto.ImportResultResponse, len(chats))}
+	for i, chat := range chats {
+		result := dto.ImportResultResponse{
+			Index:  i,
+			Source: chat.source,
+			Title:  chat.session.Title,
+		}
+
+		err := chat.err
+		if err == nil {
+			chat.session.UserID = userID
+			err = s.chatRepo.CreateWithMessages(&chat.session, chat.messages)
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			result.ChatID = chat.session.ID.String()
+			result.MessageCount = len(chat.messages)
+			response.Imported++
+		}
+		response.Results[i] = result
+	}
+
+	if response.Imported > 0 {
+		go func() {
+			if err := s.chatEmbeddingSvc.IndexMissing(); err != nil {
+				// Log error: imported chats are indexed again on the next startup
+				log.Printf("Warning: Could not index imported chats: %v", err)
+			}
+		}()
+	}
+
+	return response, nil
+}
+
+// parseFile detects the file type and parses all conversations
+func (s *ImportService) parseFile(data []byte) ([]importedChat, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return s.parseZip(data)
+	}
+	return s.parseJSON(data)
+}
+
+// parseZip parses the JSON files of a zip archive. A ChatGPT export zip is
+// recognized by its conversations.json; other JSON files are parsed as chat exports.
+// Every chat export holds at least one conversation, so the number of files is
+// limited to the conversations per import, and their total uncompressed size to
+// the upload limit.
+func (s *ImportService) parseZip(data []byte) ([]importedChat, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var files []*zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".json") {
+			continue
+		}
+		if path.Base(file.Name) == "conversations.json" {
+			files = []*zip.File{file}
+			break
+		}
+		files = append(files, file)
+	}
+	if len(files) > s.cfg.MaxChats {
+		return nil, fmt.Errorf("import archive exceeds %d files", s.cfg.MaxChats)
+	}
+
+	var chats []importedChat
+	remaining := s.cfg.MaxUploadSize
+	for _, file := range files {
+		content, err := readZipFile(file, remaining+1)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+		if remaining < 0 {
+			return nil, fmt.Errorf("uncompressed import archive exceeds maximum size of %d bytes", s.cfg.MaxUploadSize)
+		}
+
+		parsed, err := s.parseJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		chats = append(chats, parsed...)
+	}
+
+	return chats, nil
+}
+
+// readZipFile reads at most limit uncompressed bytes of a zip entry
+func readZipFile(file *zip.File, limit int64) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	return content, nil
+}
+
+// parseJSON parses a single conversation object or an array of conversations
+func (s *ImportService) parseJSON(data []byte) ([]importedChat, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+
+	var items []json.RawMessage
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case bytes.HasPrefix(data, []byte("{")):
+		items = []json.RawMessage{data}
+	default:
+		return nil, errors.New("unsupported import file: expected JSON or zip")
+	}
+
+	chats := make([]importedChat, len(items))
+	for i, item := range items {
+		if chatgptexport.IsConversation(item) {
+			chats[i] = s.parseChatGPT(item)
+		} else {
+			chats[i] = s.parseChatExport(item)
+		}
+	}
+	return chats, nil
+}
+
+// parseChatGPT converts a ChatGPT conversation. ChatGPT models are not served
+// here, so the chat uses the default model.
+func (s *ImportService) parseChatGPT(data json.RawMessage) importedChat {
+	chat := importedChat{source: ImportSourceChatGPT}
+
+	conversation, err := chatgptexport.ParseConversation(data)
+	if err != nil {
+		chat.err = err
+		return chat
+	}
+
+	chat.session = newImportedSession(conversation.Title, s.modelRegistry.Default(), conversation.CreatedAt, conversation.UpdatedAt)
+	chat.messages = make([]model.Message, len(conversation.Messages))
+	for i, msg := range conversation.Messages {
+		chat.messages[i] = model.Message{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			CreatedAt: msg.CreatedAt,
+		}
+	}
+	finishImportedChat(&chat)
+	return chat
+}
+
+// parseChatExport converts a chat exported in our JSON format
+func (s *ImportService) parseChatExport(data json.RawMessage) importedChat {
+	chat := importedChat{source: ImportSourceLLMChatbot}
+
+	var export dto.ChatExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		chat.err = fmt.Errorf("invalid chat export: %w", err)
+		return chat
+	}
+	chat.session.Title = export.Title
+	if export.Format != dto.ChatExportFormat {
+		chat.err = fmt.Errorf("unsupported format %q: expected a ChatGPT conversation or %s", export.Format, dto.ChatExportFormat)
+		return chat
+	}
+	if len(export.Messages) == 0 {
+		chat.err = errors.New("conversation has no messages")
+		return chat
+	}
+
+	modelUsed := export.ModelUsed
+	if modelUsed == "" {
+		modelUsed = s.modelRegistry.Default()
+	}
+	chat.session = newImportedSession(export.Title, modelUsed, export.CreatedAt, export.UpdatedAt)
+	chat.session.IsArchived = export.IsArchived
+
+	messages := export.Messages
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].SequenceNumber < messages[j].SequenceNumber
+	})
+
+	chat.messages = make([]model.Message, len(messages))
+	for i, msg := range messages {
+		if !isImportRole(msg.Role) {
+			chat.err = fmt.Errorf("message %d has invalid role %q", msg.SequenceNumber, msg.Role)
+			return chat
+		}
+		chat.messages[i] = model.Message{
+			Role:         msg.Role,
+			Content:      msg.Content,
+			Tokens:       msg.Tokens,
+			IsIncomplete: msg.IsIncomplete,
+			CreatedAt:    msg.CreatedAt,
+		}
+	}
+	finishImportedChat(&chat)
+	return chat
+}
+
+// newImportedSession creates a chat session with the original title and timestamps
+func newImportedSession(title, modelUsed string, createdAt, updatedAt time.Time) model.ChatSession {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = defaultImportTitle
+	}
+	if runes := []rune(title); len(runes) > 255 {
+		title = string(runes[:255])
+	}
+
+	return model.ChatSession{
+		Title:     title,
+		ModelUsed: modelUsed,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// finishImportedChat numbers the messages and fills in missing timestamps:
+// messages without a time inherit the previous message's time, and the session
+// spans its messages when the export has no session times
+func finishImportedChat(chat *importedChat) {
+	last := chat.session.CreatedAt
+	for i := range chat.messages {
+		msg := &chat.messages[i]
+		msg.SequenceNumber = i + 1
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = last
+		}
+		last = msg.CreatedAt
+	}
+
+	if chat.session.CreatedAt.IsZero() && len(chat.messages) > 0 {
+		chat.session.CreatedAt = firstMessageTime(chat.messages)
+	}
+	if chat.session.UpdatedAt.IsZero() {
+		chat.session.UpdatedAt = last
+	}
+
+	// Fall back to the import time when the export has no timestamps at all
+	if chat.session.CreatedAt.IsZero() {
+		chat.session.CreatedAt = time.Now()
+	}
+	if chat.session.UpdatedAt.IsZero() {
+		chat.session.UpdatedAt = chat.session.CreatedAt
+	}
+	for i := range chat.messages {
+		if chat.messages[i].CreatedAt.IsZero() {
+			chat.messages[i].CreatedAt = chat.session.CreatedAt
+		}
+	}
+}
+
+// firstMessageTime returns the first non-zero message time
+func firstMessageTime(messages []model.Message) time.Time {
+	for _, msg := range messages {
+		if !msg.CreatedAt.IsZero() {
+			return msg.CreatedAt
+		}
+	}
+	return time.Time{}
+}
+
+// isImportRole reports whether a role can be stored as a message role
+func isImportRole(role string) bool {
+	switch role {
+	case model.MessageRoleUser, model.MessageRoleAssistant, model.MessageRoleSystem:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llmchatbot/backend/internal/config"
+)
+
+// zipArchive builds a zip archive from file names and contents
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// chatExportFile is a chat export that parses without touching the model registry
+func chatExportFile(padding int) string {
+	return fmt.Sprintf(`{"format":"other","title":"%s"}`, strings.Repeat("a", padding))
+}
+
+func TestParseZipReadsJSONFiles(t *testing.T) {
+	s := &ImportService{cfg: config.ImportConfig{MaxUploadSize: 1 << 20, MaxChats: 10}}
+
+	data := zipArchive(t, map[string]string{
+		"chats/one.json": chatExportFile(1),
+		"chats/two.JSON": chatExportFile(2),
+		"images/cat.png": "not json",
+	})
+	chats, err := s.parseZip(data)
+	if err != nil {
+		t.Fatalf("parseZip() error = %v", err)
+	}
+	if len(chats) != 2 {
+		t.Fatalf("parseZip() returned %d chats, want 2", len(chats))
+	}
+}
+
+func TestParseZipLimitsTotalUncompressedSize(t *testing.T) {
+	s := &ImportService{cfg: config.ImportConfig{MaxUploadSize: 1000, MaxChats: 10}}
+
+	// Every file fits the limit on its own, together they exceed it
+	data := zipArchive(t, map[string]string{
+		"one.json":   chatExportFile(400),
+		"two.json":   chatExportFile(400),
+		"three.json": chatExportFile(400),
+	})
+	if _, err := s.parseZip(data); err == nil || !strings.Contains(err.Error(), "exceeds maximum size of 1000 bytes") {
+		t.Fatalf("parseZip() error = %v, want the size limit", err)
+	}
+
+	// A highly compressible entry is stopped at the limit, not read in full
+	data = zipArchive(t, map[string]string{"bomb.json": chatExportFile(10 << 20)})
+	if len(data) > 1<<16 {
+		t.Fatalf("test archive is %d bytes, want a small compressed archive", len(data))
+	}
+	if _, err := s.parseZip(data); err == nil {
+		t.Fatal("parseZip() accepted an entry over the size limit")
+	}
+}
+
+func TestParseZipLimitsFileCount(t *testing.T) {
+	s := &ImportService{cfg: config.ImportConfig{MaxUploadSize: 1 << 20, MaxChats: 3}}
+
+	files := make(map[string]string)
+	for i := 0; i < 4; i++ {
+		files[fmt.Sprintf("chat-%d.json", i)] = chatExportFile(1)
+	}
+	if _, err := s.parseZip(zipArchive(t, files)); err == nil || !strings.Contains(err.Error(), "exceeds 3 files") {
+		t.Fatalf("parseZip() error = %v, want the file limit", err)
+	}
+
+	// Other entries, such as images of a ChatGPT export, don't count
+	files = map[string]string{"conversations.json": "[]"}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("image-%d.png", i)] = "png"
+	}
+	if _, err := s.parseZip(zipArchive(t, files)); err != nil {
+		t.Fatalf("parseZip() error = %v", err)
+	}
+}
@@ -9,22 +9,34 @@ import (
 	"github.com/llmchatbot/backend/internal/repository"
 )
 
+// messageStore stores the messages of chat sessions
+type messageStore interface {
+	Create(message *model.Message) error
+	GetLastN(chatSessionID uuid.UUID, n int) ([]model.Message, error)
+	GetPinned(chatSessionID uuid.UUID, beforeSeq int) ([]model.Message, error)
+	GetNextSequenceNumber(chatSessionID uuid.UUID) (int, error)
+	DeleteFromChat(id, chatSessionID uuid.UUID) error
+}
+
 // MessageService handles message business logic
 type MessageService struct {
-	messageRepo *repository.MessageRepository
-	chatRepo    *repository.ChatRepository
+	messageRepo    messageStore
+	chatRepo       *repository.ChatRepository
+	attachmentRepo *repository.AttachmentRepository
 }
 
 // NewMessageService creates a new message service
-func NewMessageService(messageRepo *repository.MessageRepository, chatRepo *repository.ChatRepository) *MessageService {
+func NewMessageService(messageRepo *repository.MessageRepository, chatRepo *repository.ChatRepository, attachmentRepo *repository.AttachmentRepository) *MessageService {
 	return &MessageService{
-		messageRepo: messageRepo,
-		chatRepo:    chatRepo,
+		messageRepo:    messageRepo,
+		chatRepo:       chatRepo,
+		attachmentRepo: attachmentRepo,
 	}
 }
 
-// CreateUserMessage creates a user message in a chat session
-func (s *MessageService) CreateUserMessage(sessionID, userID uuid.UUID, content string) (*dto.MessageResponse, error) {
+// CreateUserMessage creates a user message in a chat session.
+// Pending attachments of the session with the given IDs are attached to the message.
+func (s *MessageService) CreateUserMessage(sessionID, userID uuid.UUID, content string, attachmentIDs []uuid.UUID) (*dto.MessageResponse, error) {
 	// Verify chat session exists and belongs to user
 	session, err := s.chatRepo.GetByIDAndUserID(sessionID, userID)
 	if err != nil {
@@ -50,6 +62,27 @@ func (s *MessageService) CreateUserMessage(sessionID, userID uuid.UUID, content
 		return nil, err
 	}
 
+	// Attach uploaded files to the message
+	var attachments []model.Attachment
+	if len(attachmentIDs) > 0 {
+		attachments, err = s.attachmentRepo.GetPending(attachmentIDs, sessionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uuid.UUID, len(attachments))
+		for i := range attachments {
+			ids[i] = attachments[i].ID
+		}
+		if err := s.attachmentRepo.AttachToMessage(ids, message.ID, sessionID, userID); err != nil {
+			// Another message took the attachments in the meantime
+			_ = s.messageRepo.DeleteFromChat(message.ID, sessionID)
+			return nil, err
+		}
+		for i := range attachments {
+			attachments[i].MessageID = &message.ID
+		}
+	}
+
 	// Update session updated_at timestamp
 	if err := s.chatRepo.Update(session); err != nil {
 		// Log error but don't fail message creation
@@ -62,6 +95,7 @@ func (s *MessageService) CreateUserMessage(sessionID, userID uuid.UUID, content
 		Tokens:         message.Tokens,
 		CreatedAt:      message.CreatedAt,
 		SequenceNumber: message.SequenceNumber,
+		Attachments:    toAttachmentResponses(attachments),
 	}, nil
 }
 
@@ -104,13 +138,29 @@ func (s *MessageService) CreateAssistantMessage(sessionID uuid.UUID, content str
 	}, nil
 }
 
-// GetChatHistory retrieves chat history for context (last N messages)
+// GetChatHistory retrieves chat history for context: the last N messages,
+// preceded by pinned messages that are older than that window
 func (s *MessageService) GetChatHistory(sessionID uuid.UUID, limit int) ([]*dto.MessageResponse, error) {
 	messages, err := s.messageRepo.GetLastN(sessionID, limit)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(messages) > 0 {
+		pinned, err := s.messageRepo.GetPinned(sessionID, messages[0].SequenceNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		included := make([]model.Message, 0, len(pinned)+len(messages))
+		for _, msg := range pinned {
+			if !msg.IsExcluded {
+				included = append(included, msg)
+			}
+		}
+		messages = append(included, messages...)
+	}
+
 	responses := make([]*dto.MessageResponse, len(messages))
 	for i, msg := range messages {
 		responses[i] = &dto.MessageResponse{
@@ -119,8 +169,11 @@ func (s *MessageService) GetChatHistory(sessionID uuid.UUID, limit int) ([]*dto.
 			Content:        msg.Content,
 			Tokens:         msg.Tokens,
 			IsIncomplete:   msg.IsIncomplete,
+			IsPinned:       msg.IsPinned,
+			IsExcluded:     msg.IsExcluded,
 			CreatedAt:      msg.CreatedAt,
 			SequenceNumber: msg.SequenceNumber,
+			Attachments:    toAttachmentResponses(msg.Attachments),
 		}
 	}
 
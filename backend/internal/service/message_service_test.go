@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+)
+
+// fakeMessages keeps the messages of one chat session in memory, in sequence order
+type fakeMessages struct {
+	messages []model.Message
+}
+
+func (f *fakeMessages) Create(message *model.Message) error {
+	message.ID = uuid.New()
+	f.messages = append(f.messages, *message)
+	return nil
+}
+
+func (f *fakeMessages) GetLastN(chatSessionID uuid.UUID, n int) ([]model.Message, error) {
+	var messages []model.Message
+	for _, msg := range f.messages {
+		if !msg.IsExcluded {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) > n {
+		messages = messages[len(messages)-n:]
+	}
+	return messages, nil
+}
+
+func (f *fakeMessages) GetPinned(chatSessionID uuid.UUID, beforeSeq int) ([]model.Message, error) {
+	var messages []model.Message
+	for _, msg := range f.messages {
+		if msg.IsPinned && (beforeSeq == 0 || msg.SequenceNumber < beforeSeq) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (f *fakeMessages) GetNextSequenceNumber(chatSessionID uuid.UUID) (int, error) {
+	return len(f.messages) + 1, nil
+}
+
+func (f *fakeMessages) DeleteFromChat(id, chatSessionID uuid.UUID) error {
+	return nil
+}
+
+// newTestChatHistory creates a chat of n messages with the given message contents "1".."n"
+func newTestChatHistory(n int) *fakeMessages {
+	messages := &fakeMessages{}
+	for i := 1; i <= n; i++ {
+		messages.Create(&model.Message{Role: model.MessageRoleUser, Content: string(rune('0' + i)), SequenceNumber: i})
+	}
+	return messages
+}
+
+// historyContents returns the contents of the chat history used as model context
+func historyContents(t *testing.T, s *MessageService, limit int) string {
+	t.Helper()
+	history, err := s.GetChatHistory(uuid.New(), limit)
+	if err != nil {
+		t.Fatalf("GetChatHistory() error = %v", err)
+	}
+	var contents string
+	for _, msg := range history {
+		contents += msg.Content
+	}
+	return contents
+}
+
+func TestGetChatHistoryKeepsPinnedMessages(t *testing.T) {
+	messages := newTestChatHistory(9)
+	messages.messages[1].IsPinned = true // 2, outside the window
+	messages.messages[7].IsPinned = true // 8, inside the window
+	s := &MessageService{messageRepo: messages}
+
+	// Pinned messages older than the window come first, in order; a pinned
+	// message inside the window is not sent twice
+	if got := historyContents(t, s, 3); got != "2789" {
+		t.Errorf("history = %q, want %q", got, "2789")
+	}
+	if got := historyContents(t, s, 20); got != "123456789" {
+		t.Errorf("history = %q, want %q", got, "123456789")
+	}
+}
+
+func TestGetChatHistoryEmptyChat(t *testing.T) {
+	s := &MessageService{messageRepo: &fakeMessages{}}
+	if got := historyContents(t, s, 3); got != "" {
+		t.Errorf("history = %q, want empty", got)
+	}
+}
+
+func TestGetChatHistorySkipsExcludedMessages(t *testing.T) {
+	messages := newTestChatHistory(9)
+	messages.messages[1].IsPinned = true   // 2
+	messages.messages[1].IsExcluded = true // excluding wins over pinning
+	messages.messages[2].IsPinned = true   // 3
+	messages.messages[7].IsExcluded = true // 8
+	s := &MessageService{messageRepo: messages}
+
+	// The window is filled with the last three messages that are not excluded
+	if got := historyContents(t, s, 3); got != "3679" {
+		t.Errorf("history = %q, want %q", got, "3679")
+	}
+}
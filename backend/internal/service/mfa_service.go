@@ -0,0 +1,353 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/totp"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes generated at once
+	recoveryCodeCount = 10
+	// totpSkew is the number of time steps a code may be early or late
+	totpSkew = 1
+)
+
+var (
+	// ErrInvalidMFACode is returned for a wrong TOTP or recovery code
+	ErrInvalidMFACode = errors.New("invalid authentication code")
+	// ErrMFALocked is returned while a factor is locked after too many wrong codes
+	ErrMFALocked = errors.New("too many invalid codes, try again later")
+)
+
+// mfaStore stores TOTP factors and recovery codes
+type mfaStore interface {
+	GetFactor(userID uuid.UUID) (*model.TOTPFactor, error)
+	SaveFactor(factor *model.TOTPFactor) error
+	AcceptCode(factorID uuid.UUID, step int64) (bool, error)
+	Confirm(factorID uuid.UUID) error
+	RecordFailure(factorID uuid.UUID, maxAttempts int, lockUntil time.Time) error
+	ResetFailures(factorID uuid.UUID) error
+	Delete(userID uuid.UUID) error
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []model.RecoveryCode) error
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(userID uuid.UUID) (int64, error)
+}
+
+// mfaUserStore looks up the users setting up two-factor authentication
+type mfaUserStore interface {
+	GetByID(id uuid.UUID) (*model.User, error)
+}
+
+// mfaTokenStore stores the challenge tokens continuing a sign-in with a second factor
+type mfaTokenStore interface {
+	Create(token *model.UserToken) error
+	GetValid(tokenHash, purpose string) (*model.UserToken, error)
+	Use(tokenHash, purpose string) (*model.UserToken, error)
+}
+
+// MFAService handles TOTP two-factor authentication
+type MFAService struct {
+	mfaRepo   mfaStore
+	userRepo  mfaUserStore
+	tokenRepo mfaTokenStore
+	cfg       config.MFAConfig
+}
+
+// NewMFAService creates a new two-factor authentication service
+func NewMFAService(
+	mfaRepo *repository.MFARepository,
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.UserTokenRepository,
+	cfg *config.Config,
+) *MFAService {
+	return &MFAService{
+		mfaRepo:   mfaRepo,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		cfg:       cfg.MFA,
+	}
+}
+
+// GetStatus reports whether two-factor authentication is enabled and required for a user
+func (s *MFAService) GetStatus(userID uuid.UUID) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.Enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.MFAStatusResponse{Enabled: enabled, Required: s.Required(user)}
+	if enabled {
+		count, err := s.mfaRepo.CountRecoveryCodes(userID)
+		if err != nil {
+			return nil, err
+		}
+		status.RecoveryCodesLeft = int(count)
+	}
+	return status, nil
+}
+
+// Enabled reports whether a user has a confirmed TOTP factor
+func (s *MFAService) Enabled(userID uuid.UUID) (bool, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if errors.Is(err, repository.ErrMFANotSetUp) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return factor.ConfirmedAt != nil, nil
+}
+
+// Required reports whether the role of a user must use two-factor authentication.
+// Guests cannot set it up, so it is never required for them.
+func (s *MFAService) Required(user *model.User) bool {
+	if user.IsGuest {
+		return false
+	}
+	for _, role := range s.cfg.RequiredRoles {
+		if role == user.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// BeginEnrollment generates a new TOTP secret for a user. The factor is used
+// only after ConfirmEnrollment verifies a code from the authenticator app.
+func (s *MFAService) BeginEnrollment(userID uuid.UUID) (*dto.TOTPEnrollmentResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsGuest {
+		return nil, errors.New("guest accounts cannot use two-factor authentication")
+	}
+
+	enabled, err := s.Enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.Encrypt(s.cfg.EncryptionKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveFactor(&model.TOTPFactor{UserID: userID, SecretEncrypted: encrypted}); err != nil {
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.cfg.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables the pending TOTP factor of a user with a code from
+// the authenticator app and returns new recovery codes
+func (s *MFAService) ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if err := s.checkTOTP(factor, code); err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Confirm(factor.ID); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(userID)
+}
+
+// Disable turns off two-factor authentication after verifying a TOTP or recovery code.
+// Users whose role requires it cannot turn it off.
+func (s *MFAService) Disable(userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if s.Required(user) {
+		return errors.New("two-factor authentication is required for your role")
+	}
+
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after verifying a code
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(userID)
+}
+
+// VerifyCode checks a TOTP code or an unused recovery code of a user with
+// two-factor authentication enabled. Recovery codes are used up.
+func (s *MFAService) VerifyCode(userID uuid.UUID, code string) error {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return err
+	}
+	if factor.ConfirmedAt == nil {
+		return repository.ErrMFANotSetUp
+	}
+	if factor.LockedUntil != nil && factor.LockedUntil.After(time.Now()) {
+		return ErrMFALocked
+	}
+
+	// Recovery codes are longer than TOTP codes
+	if len(normalizeRecoveryCode(code)) > totp.Digits {
+		used, err := s.mfaRepo.UseRecoveryCode(userID, utils.HashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return s.recordFailure(factor)
+		}
+		return s.mfaRepo.ResetFailures(factor.ID)
+	}
+
+	return s.checkTOTP(factor, code)
+}
+
+// NewChallenge creates a short-lived token that continues a sign-in with a
+// second factor: a challenge for users with a factor, an enrollment otherwise
+func (s *MFAService) NewChallenge(userID uuid.UUID, purpose string) (string, error) {
+	token := utils.GenerateRandomString(64)
+	if token == "" {
+		return "", errors.New("failed to generate token")
+	}
+
+	err := s.tokenRepo.Create(&model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.ChallengeExpiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CheckChallenge returns the user of a valid challenge token without using it up
+func (s *MFAService) CheckChallenge(rawToken, purpose string) (uuid.UUID, error) {
+	token, err := s.tokenRepo.GetValid(utils.HashToken(rawToken), purpose)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return token.UserID, nil
+}
+
+// CompleteChallenge uses up a challenge token once its sign-in step succeeded
+func (s *MFAService) CompleteChallenge(rawToken, purpose string) error {
+	_, err := s.tokenRepo.Use(utils.HashToken(rawToken), purpose)
+	return err
+}
+
+// ChallengeExpiry returns the lifetime of challenge tokens
+func (s *MFAService) ChallengeExpiry() time.Duration {
+	return s.cfg.ChallengeExpiry
+}
+
+// checkTOTP checks a TOTP code against a factor, rejecting codes already used
+func (s *MFAService) checkTOTP(factor *model.TOTPFactor, code string) error {
+	if factor.LockedUntil != nil && factor.LockedUntil.After(time.Now()) {
+		return ErrMFALocked
+	}
+
+	secret, err := utils.Decrypt(s.cfg.EncryptionKey, factor.SecretEncrypted)
+	if err != nil {
+		return errors.New("two-factor secret cannot be decrypted, set it up again")
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return s.recordFailure(factor)
+	}
+
+	accepted, err := s.mfaRepo.AcceptCode(factor.ID, step)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return s.recordFailure(factor)
+	}
+	return nil
+}
+
+// recordFailure counts a wrong code and returns the error to report
+func (s *MFAService) recordFailure(factor *model.TOTPFactor) error {
+	if s.cfg.MaxFailedAttempts > 0 {
+		if err := s.mfaRepo.RecordFailure(factor.ID, s.cfg.MaxFailedAttempts, time.Now().Add(s.cfg.LockoutDuration)); err != nil {
+			return err
+		}
+	}
+	return ErrInvalidMFACode
+}
+
+// newRecoveryCodes replaces the recovery codes of a user and returns them.
+// Only their hashes are stored, so they are shown once.
+func (s *MFAService) newRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = model.RecoveryCode{UserID: userID, CodeHash: utils.HashToken(normalizeRecoveryCode(code))}
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode generates an 80-bit code formatted as XXXX-XXXX-XXXX-XXXX
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	encoded := base32.StdEncoding.EncodeToString(raw)
+	return encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16], nil
+}
+
+// normalizeRecoveryCode removes separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
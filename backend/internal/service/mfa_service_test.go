@@ -0,0 +1,360 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/totp"
+)
+
+// fakeMFA keeps TOTP factors and recovery codes in memory
+type fakeMFA struct {
+	factors map[uuid.UUID]*model.TOTPFactor
+	codes   []model.RecoveryCode
+}
+
+func newFakeMFA() *fakeMFA {
+	return &fakeMFA{factors: make(map[uuid.UUID]*model.TOTPFactor)}
+}
+
+func (f *fakeMFA) GetFactor(userID uuid.UUID) (*model.TOTPFactor, error) {
+	factor, ok := f.factors[userID]
+	if !ok {
+		return nil, repository.ErrMFANotSetUp
+	}
+	copied := *factor
+	return &copied, nil
+}
+
+func (f *fakeMFA) SaveFactor(factor *model.TOTPFactor) error {
+	factor.ID = uuid.New()
+	copied := *factor
+	f.factors[factor.UserID] = &copied
+	return nil
+}
+
+func (f *fakeMFA) byID(factorID uuid.UUID) *model.TOTPFactor {
+	for _, factor := range f.factors {
+		if factor.ID == factorID {
+			return factor
+		}
+	}
+	return nil
+}
+
+func (f *fakeMFA) AcceptCode(factorID uuid.UUID, step int64) (bool, error) {
+	factor := f.byID(factorID)
+	if factor == nil || factor.LastUsedStep >= step {
+		return false, nil
+	}
+	factor.LastUsedStep = step
+	factor.FailedAttempts = 0
+	factor.LockedUntil = nil
+	return true, nil
+}
+
+func (f *fakeMFA) Confirm(factorID uuid.UUID) error {
+	now := time.Now()
+	f.byID(factorID).ConfirmedAt = &now
+	return nil
+}
+
+func (f *fakeMFA) RecordFailure(factorID uuid.UUID, maxAttempts int, lockUntil time.Time) error {
+	factor := f.byID(factorID)
+	factor.FailedAttempts++
+	if factor.FailedAttempts >= maxAttempts {
+		factor.FailedAttempts = 0
+		factor.LockedUntil = &lockUntil
+	}
+	return nil
+}
+
+func (f *fakeMFA) ResetFailures(factorID uuid.UUID) error {
+	factor := f.byID(factorID)
+	factor.FailedAttempts = 0
+	factor.LockedUntil = nil
+	return nil
+}
+
+func (f *fakeMFA) Delete(userID uuid.UUID) error {
+	delete(f.factors, userID)
+	f.ReplaceRecoveryCodes(userID, nil)
+	return nil
+}
+
+func (f *fakeMFA) ReplaceRecoveryCodes(userID uuid.UUID, codes []model.RecoveryCode) error {
+	kept := f.codes[:0]
+	for _, code := range f.codes {
+		if code.UserID != userID {
+			kept = append(kept, code)
+		}
+	}
+	f.codes = append(kept, codes...)
+	return nil
+}
+
+func (f *fakeMFA) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	for i := range f.codes {
+		code := &f.codes[i]
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			now := time.Now()
+			code.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeMFA) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, code := range f.codes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeMFAUsers knows a fixed set of users
+type fakeMFAUsers map[uuid.UUID]*model.User
+
+func (f fakeMFAUsers) GetByID(id uuid.UUID) (*model.User, error) {
+	if user, ok := f[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+// fakeMFATokens keeps challenge tokens in memory
+type fakeMFATokens map[string]*model.UserToken
+
+func (f fakeMFATokens) Create(token *model.UserToken) error {
+	f[token.TokenHash] = token
+	return nil
+}
+
+func (f fakeMFATokens) GetValid(tokenHash, purpose string) (*model.UserToken, error) {
+	token, ok := f[tokenHash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, repository.ErrUserTokenInvalid
+	}
+	return token, nil
+}
+
+func (f fakeMFATokens) Use(tokenHash, purpose string) (*model.UserToken, error) {
+	token, err := f.GetValid(tokenHash, purpose)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return token, nil
+}
+
+// newTestMFAService creates a service for a user with a confirmed factor and
+// returns the TOTP secret and the recovery codes
+func newTestMFAService(t *testing.T, user *model.User) (*MFAService, *fakeMFA, string, []string) {
+	t.Helper()
+	store := newFakeMFA()
+	s := &MFAService{
+		mfaRepo:   store,
+		userRepo:  fakeMFAUsers{user.ID: user},
+		tokenRepo: fakeMFATokens{},
+		cfg: config.MFAConfig{
+			Issuer:            "Test",
+			EncryptionKey:     "test-key",
+			ChallengeExpiry:   time.Minute,
+			MaxFailedAttempts: 3,
+			LockoutDuration:   time.Minute,
+		},
+	}
+
+	enrollment, err := s.BeginEnrollment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	if enabled, _ := s.Enabled(user.ID); enabled {
+		t.Fatal("Enabled() before the enrollment is confirmed")
+	}
+
+	// Confirm with the code of the previous step, so tests can use the current one
+	codes, err := s.ConfirmEnrollment(user.ID, currentCode(t, enrollment.Secret, -1))
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("ConfirmEnrollment() returned %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	return s, store, enrollment.Secret, codes
+}
+
+// currentCode returns the code of a secret for the current time step moved by offset steps
+func currentCode(t *testing.T, secret string, offset int) string {
+	t.Helper()
+	code, err := totp.Generate(secret, time.Now().Add(time.Duration(offset)*totp.Period*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongCode returns a code not accepted for the secret around the current time
+func wrongCode(t *testing.T, secret string) string {
+	t.Helper()
+	valid := map[string]bool{}
+	for offset := -totpSkew - 1; offset <= totpSkew+1; offset++ {
+		valid[currentCode(t, secret, offset)] = true
+	}
+	for _, code := range []string{"000000", "111111", "222222", "333333", "444444", "555555"} {
+		if !valid[code] {
+			return code
+		}
+	}
+	t.Fatal("no wrong code found")
+	return ""
+}
+
+func TestMFARejectsReplayedCodes(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "user@example.com", Role: "user"}
+	s, _, secret, _ := newTestMFAService(t, user)
+
+	code := currentCode(t, secret, 0)
+	if err := s.VerifyCode(user.ID, code); err != nil {
+		t.Fatalf("VerifyCode() error = %v", err)
+	}
+	if err := s.VerifyCode(user.ID, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyCode() replay error = %v, want ErrInvalidMFACode", err)
+	}
+
+	// A code of an earlier step, still within the skew, is rejected once a later one was used
+	if err := s.VerifyCode(user.ID, currentCode(t, secret, -1)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyCode() earlier step error = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFARecoveryCodesAreSingleUse(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "user@example.com", Role: "user"}
+	s, _, _, codes := newTestMFAService(t, user)
+
+	// Recovery codes are accepted without separators and in lower case
+	typed := strings.ToLower(strings.ReplaceAll(codes[0], "-", " "))
+	if err := s.VerifyCode(user.ID, typed); err != nil {
+		t.Fatalf("VerifyCode() with a recovery code error = %v", err)
+	}
+	if err := s.VerifyCode(user.ID, codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyCode() with a used recovery code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	status, err := s.GetStatus(user.ID)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.Enabled || status.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Fatalf("GetStatus() = %+v, want enabled with %d recovery codes left", status, recoveryCodeCount-1)
+	}
+
+	// Regenerating replaces all codes, including the unused ones
+	fresh, err := s.RegenerateRecoveryCodes(user.ID, codes[1])
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+	if err := s.VerifyCode(user.ID, codes[2]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyCode() with a replaced recovery code error = %v, want ErrInvalidMFACode", err)
+	}
+	if err := s.VerifyCode(user.ID, fresh[0]); err != nil {
+		t.Fatalf("VerifyCode() with a new recovery code error = %v", err)
+	}
+}
+
+func TestMFALockout(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "user@example.com", Role: "user"}
+	s, store, secret, codes := newTestMFAService(t, user)
+	wrong := wrongCode(t, secret)
+
+	// Failures below the limit are reset by a correct code
+	for i := 0; i < 2; i++ {
+		if err := s.VerifyCode(user.ID, wrong); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("VerifyCode() wrong code error = %v, want ErrInvalidMFACode", err)
+		}
+	}
+	if err := s.VerifyCode(user.ID, currentCode(t, secret, 0)); err != nil {
+		t.Fatalf("VerifyCode() error = %v", err)
+	}
+	if n := store.factors[user.ID].FailedAttempts; n != 0 {
+		t.Fatalf("FailedAttempts = %d after a correct code, want 0", n)
+	}
+
+	// Wrong recovery codes count too
+	for i := 0; i < 2; i++ {
+		s.VerifyCode(user.ID, wrong)
+	}
+	if err := s.VerifyCode(user.ID, "AAAA-BBBB-CCCC-DDDD"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("VerifyCode() wrong recovery code error = %v, want ErrInvalidMFACode", err)
+	}
+
+	// Once locked, even correct codes are refused
+	if err := s.VerifyCode(user.ID, currentCode(t, secret, 1)); !errors.Is(err, ErrMFALocked) {
+		t.Fatalf("VerifyCode() while locked error = %v, want ErrMFALocked", err)
+	}
+	if err := s.VerifyCode(user.ID, codes[0]); !errors.Is(err, ErrMFALocked) {
+		t.Fatalf("VerifyCode() with a recovery code while locked error = %v, want ErrMFALocked", err)
+	}
+
+	// The lock expires
+	past := time.Now().Add(-time.Second)
+	store.factors[user.ID].LockedUntil = &past
+	if err := s.VerifyCode(user.ID, currentCode(t, secret, 1)); err != nil {
+		t.Fatalf("VerifyCode() after the lock expired error = %v", err)
+	}
+}
+
+func TestMFARequiredRoleCannotDisable(t *testing.T) {
+	admin := &model.User{ID: uuid.New(), Email: "admin@example.com", Role: "admin"}
+	s, store, secret, _ := newTestMFAService(t, admin)
+	s.cfg.RequiredRoles = []string{"admin"}
+
+	if err := s.Disable(admin.ID, currentCode(t, secret, 0)); err == nil {
+		t.Fatal("Disable() succeeded for a role that requires two-factor authentication")
+	}
+
+	s.cfg.RequiredRoles = nil
+	if err := s.Disable(admin.ID, currentCode(t, secret, 0)); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if _, ok := store.factors[admin.ID]; ok || len(store.codes) != 0 {
+		t.Fatal("Disable() kept the factor or the recovery codes")
+	}
+}
+
+func TestMFAChallengeIsSingleUse(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "user@example.com", Role: "user"}
+	s, _, _, _ := newTestMFAService(t, user)
+
+	token, err := s.NewChallenge(user.ID, model.UserTokenMFAChallenge)
+	if err != nil {
+		t.Fatalf("NewChallenge() error = %v", err)
+	}
+	if _, err := s.CheckChallenge(token, model.UserTokenMFAEnrollment); err == nil {
+		t.Fatal("CheckChallenge() accepted a token of another purpose")
+	}
+
+	// Checking does not use the token up, completing does
+	for i := 0; i < 2; i++ {
+		userID, err := s.CheckChallenge(token, model.UserTokenMFAChallenge)
+		if err != nil || userID != user.ID {
+			t.Fatalf("CheckChallenge() = %s, %v; want %s", userID, err, user.ID)
+		}
+	}
+	if err := s.CompleteChallenge(token, model.UserTokenMFAChallenge); err != nil {
+		t.Fatalf("CompleteChallenge() error = %v", err)
+	}
+	if _, err := s.CheckChallenge(token, model.UserTokenMFAChallenge); err == nil {
+		t.Fatal("CheckChallenge() accepted a completed challenge")
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+)
+
+// ModelInfo describes a model available for chats
+type ModelInfo struct {
+	Name   string
+	Vision bool // Model accepts image inputs
+}
+
+// ModelRegistry holds the models available for chats and their capabilities
+type ModelRegistry struct {
+	models []ModelInfo
+}
+
+// NewModelRegistry creates a model registry from configuration.
+// Entries have the form "name" or "name:vision".
+func NewModelRegistry(cfg *config.Config) *ModelRegistry {
+	registry := &ModelRegistry{}
+	for _, entry := range cfg.LLM.Models {
+		name, flags, _ := strings.Cut(entry, ":")
+		registry.models = append(registry.models, ModelInfo{
+			Name:   strings.TrimSpace(name),
+			Vision: strings.TrimSpace(flags) == "vision",
+		})
+	}
+	return registry
+}
+
+// Get returns a model by name
+func (r *ModelRegistry) Get(name string) (ModelInfo, bool) {
+	for _, info := range r.models {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// Default returns the model used when none is specified
+func (r *ModelRegistry) Default() string {
+	if len(r.models) == 0 {
+		return ""
+	}
+	return r.models[0].Name
+}
+
+// SupportsImages reports whether a model accepts image inputs
+func (r *ModelRegistry) SupportsImages(name string) bool {
+	info, ok := r.Get(name)
+	return ok && info.Vision
+}
+
+// List returns all registered models
+func (r *ModelRegistry) List() []dto.ModelResponse {
+	responses := make([]dto.ModelResponse, len(r.models))
+	for i, info := range r.models {
+		responses[i] = dto.ModelResponse{
+			Name:   info.Name,
+			Vision: info.Vision,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/sso"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+// oidcLoginCodeExpiry is the lifetime of the code the frontend exchanges for tokens
+const oidcLoginCodeExpiry = time.Minute
+
+// usernameDisallowed matches characters not used in generated usernames
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ErrUnknownProvider is returned for a single sign-on provider that is not configured
+var ErrUnknownProvider = errors.New("unknown sign-on provider")
+
+// ssoIdentityStore stores the provider identities linked to users
+type ssoIdentityStore interface {
+	Create(identity *model.UserIdentity) error
+	GetByProviderSubject(provider, subject string) (*model.UserIdentity, error)
+	GetByUserID(userID uuid.UUID) ([]model.UserIdentity, error)
+	Touch(id uuid.UUID, email string) error
+	DeleteByIDAndUserID(id, userID uuid.UUID) error
+	DeleteByUserID(userID uuid.UUID) error
+}
+
+// ssoUserStore stores the users signing in with a provider
+type ssoUserStore interface {
+	Create(user *model.User) error
+	GetByID(id uuid.UUID) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	Update(user *model.User) error
+	UsernameExists(username string) (bool, error)
+}
+
+// ssoAPIKeyStore revokes the API keys of users whose account is taken over
+type ssoAPIKeyStore interface {
+	DeleteByUserID(userID uuid.UUID) error
+}
+
+// ssoMFAStore removes the second factor of users whose account is taken over
+type ssoMFAStore interface {
+	Delete(userID uuid.UUID) error
+}
+
+// ssoSessions starts and ends the login sessions of users signing in with a provider
+type ssoSessions interface {
+	SignIn(user *model.User, client ClientInfo) (*dto.AuthResponse, error)
+	RevokeAllSessions(userID uuid.UUID) error
+}
+
+// OIDCService handles single sign-on with OpenID Connect and OAuth2 providers
+type OIDCService struct {
+	providers    map[string]sso.Provider
+	providerList []dto.OIDCProviderResponse
+	loginRepo    *repository.OIDCLoginRepository
+	identityRepo ssoIdentityStore
+	userRepo     ssoUserStore
+	tokenRepo    *repository.UserTokenRepository
+	apiKeyRepo   ssoAPIKeyStore
+	mfaRepo      ssoMFAStore
+	authService  ssoSessions
+	cfg          config.OIDCConfig
+}
+
+// NewOIDCService creates a new single sign-on service
+func NewOIDCService(
+	providers map[string]sso.Provider,
+	loginRepo *repository.OIDCLoginRepository,
+	identityRepo *repository.UserIdentityRepository,
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.UserTokenRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	mfaRepo *repository.MFARepository,
+	authService *AuthService,
+	cfg *config.Config,
+) *OIDCService {
+	providerList := []dto.OIDCProviderResponse{}
+	for _, providerCfg := range cfg.OIDC.Providers {
+		providerList = append(providerList, dto.OIDCProviderResponse{ID: providerCfg.ID, Name: providerCfg.Name})
+	}
+
+	return &OIDCService{
+		providers:    providers,
+		providerList: providerList,
+		loginRepo:    loginRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		apiKeyRepo:   apiKeyRepo,
+		mfaRepo:      mfaRepo,
+		authService:  authService,
+		cfg:          cfg.OIDC,
+	}
+}
+
+// NewSSOProviders creates the sign-on providers configured in OIDC_PROVIDERS
+func NewSSOProviders(cfg *config.Config) (map[string]sso.Provider, error) {
+	providers := make(map[string]sso.Provider, len(cfg.OIDC.Providers))
+	for _, providerCfg := range cfg.OIDC.Providers {
+		provider, err := newSSOProvider(providerCfg, cfg.OIDC.CallbackURL)
+		if err != nil {
+			return nil, fmt.Errorf("sign-on provider %s: %w", providerCfg.ID, err)
+		}
+		providers[providerCfg.ID] = provider
+	}
+	return providers, nil
+}
+
+// newSSOProvider creates a sign-on provider of the configured type
+func newSSOProvider(cfg config.OIDCProviderConfig, callbackURL string) (sso.Provider, error) {
+	clientCfg := sso.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  callbackURL + "/api/v1/auth/oidc/" + cfg.ID + "/callback",
+		Scopes:       cfg.Scopes,
+	}
+
+	switch cfg.Type {
+	case "oidc":
+		return sso.NewOIDCProvider(cfg.Issuer, clientCfg)
+	case "github":
+		return sso.NewGitHubProvider(clientCfg)
+	default:
+		return nil, fmt.Errorf("unknown provider type: %s", cfg.Type)
+	}
+}
+
+// GetProviders lists the configured providers for the login page
+func (s *OIDCService) GetProviders() []dto.OIDCProviderResponse {
+	return s.providerList
+}
+
+// StartLogin begins a sign-in with a provider. It returns the provider's consent
+// page URL and the state, which the client must present again in the callback.
+func (s *OIDCService) StartLogin(ctx context.Context, providerID string) (string, string, error) {
+	provider, ok := s.providers[providerID]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state := utils.GenerateRandomString(32)
+	nonce := utils.GenerateRandomString(32)
+	verifier := sso.GenerateVerifier()
+	if state == "" || nonce == "" {
+		return "", "", errors.New("failed to generate sign-in state")
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.loginRepo.Create(&model.OIDCLogin{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.cfg.LoginExpiry),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteLogin handles the provider's redirect back: the authorization code is
+// redeemed, the identity is resolved to a user, and a short-lived login code is
+// returned for the frontend to exchange for tokens
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerID, state, code string, client ClientInfo) (string, error) {
+	login, err := s.loginRepo.Consume(utils.HashToken(state))
+	if err != nil {
+		return "", err
+	}
+	if login.Provider != providerID {
+		return "", repository.ErrOIDCLoginInvalid
+	}
+
+	provider, ok := s.providers[providerID]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	identity, err := provider.Exchange(ctx, code, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.resolveUser(providerID, identity, client)
+	if err != nil {
+		return "", err
+	}
+
+	loginCode := utils.GenerateRandomString(64)
+	if loginCode == "" {
+		return "", errors.New("failed to generate login code")
+	}
+	err = s.tokenRepo.Create(&model.UserToken{
+		UserID:    user.ID,
+		Purpose:   model.UserTokenOIDCLogin,
+		TokenHash: utils.HashToken(loginCode),
+		ExpiresAt: time.Now().Add(oidcLoginCodeExpiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return loginCode, nil
+}
+
+// ExchangeLoginCode exchanges a login code from CompleteLogin for tokens of a new session
+func (s *OIDCService) ExchangeLoginCode(code string, client ClientInfo) (*dto.AuthResponse, error) {
+	token, err := s.tokenRepo.Use(utils.HashToken(code), model.UserTokenOIDCLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return nil, repository.ErrUserTokenInvalid
+	}
+	return s.authService.SignIn(user, client)
+}
+
+// GetIdentities lists the sign-on identities linked to a user
+func (s *OIDCService) GetIdentities(userID uuid.UUID) ([]dto.UserIdentityResponse, error) {
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = dto.UserIdentityResponse{
+			ID:          identity.ID.String(),
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			CreatedAt:   identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		}
+	}
+	return responses, nil
+}
+
+// UnlinkIdentity removes a sign-on identity from a user
+func (s *OIDCService) UnlinkIdentity(userID, identityID uuid.UUID) error {
+	return s.identityRepo.DeleteByIDAndUserID(identityID, userID)
+}
+
+// CleanupExpiredLogins removes sign-ins that were never completed
+func (s *OIDCService) CleanupExpiredLogins() error {
+	return s.loginRepo.DeleteExpired()
+}
+
+// resolveUser finds the user of a provider identity. An unknown identity is linked
+// to the user with the same email, or a new user is created. Both require an
+// email the provider has verified, since the email is what proves the link.
+func (s *OIDCService) resolveUser(providerID string, identity *sso.Identity, client ClientInfo) (*model.User, error) {
+	if identity.Subject == "" {
+		return nil, errors.New("provider returned no user ID")
+	}
+
+	linked, err := s.identityRepo.GetByProviderSubject(providerID, identity.Subject)
+	if err == nil {
+		if err := s.identityRepo.Touch(linked.ID, identity.Email); err != nil {
+			// Log error but don't fail the sign-in
+			log.Printf("Warning: Could not update identity %s: %v", linked.ID, err)
+		}
+		return s.userRepo.GetByID(linked.UserID)
+	}
+
+	if identity.Email == "" {
+		return nil, errors.New("provider returned no email address")
+	}
+	if !identity.EmailVerified {
+		return nil, errors.New("email address is not verified by the provider")
+	}
+
+	user, err := s.userRepo.GetByEmail(identity.Email)
+	if err == nil {
+		if err := s.prepareLink(user); err != nil {
+			return nil, err
+		}
+	} else {
+		if user, err = s.createUser(identity, client); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	err = s.identityRepo.Create(&model.UserIdentity{
+		UserID:      user.ID,
+		Provider:    providerID,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// prepareLink prepares an existing user for linking a provider identity with the same
+// verified email. If the user never verified the email, whoever set the password may
+// not own the mailbox, so everything they could have left behind to keep access is
+// removed: the password, sessions, API keys, second factor and other linked identities.
+func (s *OIDCService) prepareLink(user *model.User) error {
+	if user.IsGuest {
+		return errors.New("email already exists")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	passwordHash, err := utils.HashPassword(utils.GenerateRandomString(32))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.PasswordHash = passwordHash
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	if err := s.authService.RevokeAllSessions(user.ID); err != nil {
+		return err
+	}
+	if err := s.apiKeyRepo.DeleteByUserID(user.ID); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(user.ID); err != nil {
+		return err
+	}
+	return s.identityRepo.DeleteByUserID(user.ID)
+}
+
+// createUser creates a user for a provider identity. The user has a random password
+// and can set one with a password reset.
+func (s *OIDCService) createUser(identity *sso.Identity, client ClientInfo) (*model.User, error) {
+	username, err := s.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := utils.HashPassword(utils.GenerateRandomString(32))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &model.User{
+		Email:           identity.Email,
+		Username:        username,
+		PasswordHash:    passwordHash,
+		IsActive:        true,
+		SignupIP:        client.IPAddress,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername derives an unused username from the provider username or the email
+func (s *OIDCService) availableUsername(identity *sso.Identity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = usernameDisallowed.ReplaceAllString(base, "")
+	if len(base) > 90 {
+		base = base[:90]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.userRepo.UsernameExists(username)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return username, nil
+		}
+		username = base + "_" + utils.GenerateRandomString(6)
+	}
+	return "", errors.New("could not find an available username")
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/sso"
+)
+
+// fakeSSOUsers keeps users in memory
+type fakeSSOUsers struct {
+	users   map[uuid.UUID]*model.User
+	updated []uuid.UUID
+}
+
+func (f *fakeSSOUsers) Create(user *model.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeSSOUsers) GetByID(id uuid.UUID) (*model.User, error) {
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f *fakeSSOUsers) GetByEmail(email string) (*model.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (f *fakeSSOUsers) Update(user *model.User) error {
+	f.updated = append(f.updated, user.ID)
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeSSOUsers) UsernameExists(username string) (bool, error) {
+	for _, user := range f.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeSSOIdentities keeps provider identities in memory
+type fakeSSOIdentities struct {
+	identities []*model.UserIdentity
+	touched    map[uuid.UUID]string
+}
+
+func (f *fakeSSOIdentities) Create(identity *model.UserIdentity) error {
+	identity.ID = uuid.New()
+	f.identities = append(f.identities, identity)
+	return nil
+}
+
+func (f *fakeSSOIdentities) GetByProviderSubject(provider, subject string) (*model.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, errors.New("identity not found")
+}
+
+func (f *fakeSSOIdentities) GetByUserID(userID uuid.UUID) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	for _, identity := range f.identities {
+		if identity.UserID == userID {
+			identities = append(identities, *identity)
+		}
+	}
+	return identities, nil
+}
+
+func (f *fakeSSOIdentities) Touch(id uuid.UUID, email string) error {
+	f.touched[id] = email
+	return nil
+}
+
+func (f *fakeSSOIdentities) DeleteByIDAndUserID(id, userID uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeSSOIdentities) DeleteByUserID(userID uuid.UUID) error {
+	kept := f.identities[:0]
+	for _, identity := range f.identities {
+		if identity.UserID != userID {
+			kept = append(kept, identity)
+		}
+	}
+	f.identities = kept
+	return nil
+}
+
+// fakeSSOCredentials records the users whose API keys and second factor were removed
+type fakeSSOCredentials struct {
+	apiKeysRevoked []uuid.UUID
+	mfaDeleted     []uuid.UUID
+}
+
+func (f *fakeSSOCredentials) DeleteByUserID(userID uuid.UUID) error {
+	f.apiKeysRevoked = append(f.apiKeysRevoked, userID)
+	return nil
+}
+
+func (f *fakeSSOCredentials) Delete(userID uuid.UUID) error {
+	f.mfaDeleted = append(f.mfaDeleted, userID)
+	return nil
+}
+
+// fakeSSOSessions records ended sessions
+type fakeSSOSessions struct {
+	revoked []uuid.UUID
+}
+
+func (f *fakeSSOSessions) SignIn(user *model.User, client ClientInfo) (*dto.AuthResponse, error) {
+	return &dto.AuthResponse{}, nil
+}
+
+func (f *fakeSSOSessions) RevokeAllSessions(userID uuid.UUID) error {
+	f.revoked = append(f.revoked, userID)
+	return nil
+}
+
+func newTestOIDCService(users ...*model.User) (*OIDCService, *fakeSSOUsers, *fakeSSOIdentities, *fakeSSOSessions, *fakeSSOCredentials) {
+	userStore := &fakeSSOUsers{users: make(map[uuid.UUID]*model.User)}
+	for _, user := range users {
+		userStore.Create(user)
+	}
+	identities := &fakeSSOIdentities{touched: make(map[uuid.UUID]string)}
+	sessions := &fakeSSOSessions{}
+	credentials := &fakeSSOCredentials{}
+
+	s := &OIDCService{
+		identityRepo: identities,
+		userRepo:     userStore,
+		apiKeyRepo:   credentials,
+		mfaRepo:      credentials,
+		authService:  sessions,
+	}
+	return s, userStore, identities, sessions, credentials
+}
+
+func TestResolveUserLinkedIdentity(t *testing.T) {
+	user := &model.User{Email: "old@example.com", Username: "alice", IsActive: true}
+	s, _, identities, _, _ := newTestOIDCService(user)
+	identities.Create(&model.UserIdentity{UserID: user.ID, Provider: "google", Subject: "sub-1"})
+
+	// A linked identity signs in even if the provider no longer verifies the email
+	got, err := s.resolveUser("google", &sso.Identity{Subject: "sub-1", Email: "new@example.com"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("resolved user %s, want %s", got.ID, user.ID)
+	}
+	if email := identities.touched[identities.identities[0].ID]; email != "new@example.com" {
+		t.Errorf("identity email updated to %q", email)
+	}
+}
+
+func TestResolveUserRejectsUnverifiedIdentities(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", IsActive: true}
+
+	tests := []struct {
+		name     string
+		identity sso.Identity
+		want     string
+	}{
+		{"no subject", sso.Identity{Email: "alice@example.com", EmailVerified: true}, "no user ID"},
+		{"no email", sso.Identity{Subject: "sub-1", EmailVerified: true}, "no email"},
+		{"unverified email", sso.Identity{Subject: "sub-1", Email: "alice@example.com"}, "not verified"},
+		{"unverified new email", sso.Identity{Subject: "sub-1", Email: "bob@example.com"}, "not verified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users, identities, sessions, _ := newTestOIDCService(user)
+
+			_, err := s.resolveUser("google", &tt.identity, ClientInfo{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("resolveUser() error = %v, want %q", err, tt.want)
+			}
+			if len(identities.identities) != 0 || len(users.users) != 1 || len(sessions.revoked) != 0 {
+				t.Error("rejected identity changed users or identities")
+			}
+		})
+	}
+}
+
+func TestResolveUserLinksVerifiedAccount(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	user := &model.User{Email: "alice@example.com", Username: "alice", PasswordHash: "hash", IsActive: true, EmailVerifiedAt: &verifiedAt}
+	s, users, identities, sessions, credentials := newTestOIDCService(user)
+
+	got, err := s.resolveUser("google", &sso.Identity{Subject: "sub-1", Email: "alice@example.com", EmailVerified: true}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("resolved user %s, want %s", got.ID, user.ID)
+	}
+	// The owner of a verified account keeps their password, sessions and credentials
+	if got.PasswordHash != "hash" || len(users.updated) != 0 || len(sessions.revoked) != 0 {
+		t.Error("verified account was changed by linking")
+	}
+	if len(credentials.apiKeysRevoked) != 0 || len(credentials.mfaDeleted) != 0 {
+		t.Error("credentials of a verified account were removed by linking")
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserID != user.ID || identities.identities[0].Subject != "sub-1" {
+		t.Errorf("identities = %+v, want one linked to the user", identities.identities)
+	}
+}
+
+func TestResolveUserTakesOverUnverifiedAccount(t *testing.T) {
+	user := &model.User{Email: "alice@example.com", Username: "alice", PasswordHash: "squatter-hash", IsActive: true}
+	s, users, identities, sessions, credentials := newTestOIDCService(user)
+	identities.Create(&model.UserIdentity{UserID: user.ID, Provider: "github", Subject: "squatter"})
+
+	got, err := s.resolveUser("google", &sso.Identity{Subject: "sub-1", Email: "alice@example.com", EmailVerified: true}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("resolved user %s, want %s", got.ID, user.ID)
+	}
+
+	// Whoever registered the unverified email may not own the mailbox: the
+	// password is replaced, their sessions are ended and the API keys, second
+	// factor and identities they could have added are removed
+	if got.PasswordHash == "squatter-hash" {
+		t.Error("password of the unverified account kept")
+	}
+	if got.EmailVerifiedAt == nil {
+		t.Error("email not marked verified")
+	}
+	if len(users.updated) != 1 || len(sessions.revoked) != 1 || sessions.revoked[0] != user.ID {
+		t.Errorf("updated %v, revoked %v; want the user updated and signed out", users.updated, sessions.revoked)
+	}
+	if len(credentials.apiKeysRevoked) != 1 || credentials.apiKeysRevoked[0] != user.ID {
+		t.Errorf("API keys revoked for %v, want the user", credentials.apiKeysRevoked)
+	}
+	if len(credentials.mfaDeleted) != 1 || credentials.mfaDeleted[0] != user.ID {
+		t.Errorf("second factor deleted for %v, want the user", credentials.mfaDeleted)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].Subject != "sub-1" {
+		t.Errorf("identities = %+v, want only the new one", identities.identities)
+	}
+}
+
+func TestResolveUserRefusesGuestEmail(t *testing.T) {
+	guest := &model.User{Email: "guest_1@guest.local", Username: "guest_1", IsGuest: true, IsActive: true}
+	s, _, identities, _, _ := newTestOIDCService(guest)
+
+	_, err := s.resolveUser("google", &sso.Identity{Subject: "sub-1", Email: "guest_1@guest.local", EmailVerified: true}, ClientInfo{})
+	if err == nil {
+		t.Fatal("resolveUser() linked a guest account")
+	}
+	if len(identities.identities) != 0 {
+		t.Error("identity linked to a guest account")
+	}
+}
+
+func TestResolveUserCreatesUser(t *testing.T) {
+	existing := &model.User{Email: "other@example.com", Username: "alice", IsActive: true}
+	s, users, identities, _, _ := newTestOIDCService(existing)
+
+	got, err := s.resolveUser("github", &sso.Identity{
+		Subject:       "42",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Username:      "alice",
+	}, ClientInfo{IPAddress: "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+
+	if got.ID == existing.ID || len(users.users) != 2 {
+		t.Fatal("no new user created")
+	}
+	if got.Email != "alice@example.com" || got.EmailVerifiedAt == nil || !got.IsActive || got.SignupIP != "203.0.113.7" {
+		t.Errorf("created user = %+v", got)
+	}
+	if got.Username == "alice" || !strings.HasPrefix(got.Username, "alice_") {
+		t.Errorf("username = %q, want a variant of the taken username", got.Username)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserID != got.ID || identities.identities[0].Provider != "github" {
+		t.Errorf("identities = %+v, want one linked to the new user", identities.identities)
+	}
+}
+
+func TestAvailableUsername(t *testing.T) {
+	s, _, _, _, _ := newTestOIDCService()
+
+	tests := []struct {
+		identity sso.Identity
+		want     string
+	}{
+		{sso.Identity{Username: "Alice.Smith"}, "Alice.Smith"},
+		{sso.Identity{Email: "bob+tag@example.com"}, "bobtag"},
+		{sso.Identity{Username: "Иван"}, "___"},
+		{sso.Identity{Email: "x@example.com"}, "x__"},
+	}
+	for _, tt := range tests {
+		got, err := s.availableUsername(&tt.identity)
+		if err != nil {
+			t.Fatalf("availableUsername(%+v) error = %v", tt.identity, err)
+		}
+		if got != tt.want {
+			t.Errorf("availableUsername(%+v) = %q, want %q", tt.identity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// roleNamePattern restricts custom role names to lowercase identifiers
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ErrInsufficientPrivileges is returned when a user tries to act on an administrator,
+// grant the admin role or grant permissions beyond their own role
+var ErrInsufficientPrivileges = errors.New("insufficient privileges")
+
+// RoleService handles roles and their permissions
+type RoleService struct {
+	roleRepo *repository.RoleRepository
+	userRepo *repository.UserRepository
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo *repository.RoleRepository, userRepo *repository.UserRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// HasPermission reports whether the role of a user grants a permission.
+// Deactivated users have no permissions.
+func (s *RoleService) HasPermission(userID uuid.UUID, permission string) (bool, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if !user.IsActive {
+		return false, nil
+	}
+
+	switch user.Role {
+	case model.RoleAdmin:
+		return true, nil
+	case model.RoleUser:
+		return false, nil
+	}
+
+	role, err := s.roleRepo.GetByName(user.Role)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return role.HasPermission(permission), nil
+}
+
+// RoleExists reports whether a built-in or custom role has the given name
+func (s *RoleService) RoleExists(name string) (bool, error) {
+	if isBuiltInRole(name) {
+		return true, nil
+	}
+	_, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetRoles lists the built-in roles followed by the custom roles
+func (s *RoleService) GetRoles() ([]dto.RoleResponse, error) {
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.RoleResponse{
+		{Name: model.RoleUser, Description: "Default role", Permissions: []string{}, IsBuiltIn: true},
+		{Name: model.RoleAdmin, Description: "All permissions", Permissions: model.Permissions, IsBuiltIn: true},
+	}
+	for i := range roles {
+		responses = append(responses, toRoleResponse(&roles[i]))
+	}
+	return responses, nil
+}
+
+// CreateRole creates a custom role. Only administrators may grant permissions
+// their own role does not have.
+func (s *RoleService) CreateRole(actorID uuid.UUID, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, errors.New("role name must start with a lowercase letter and contain only lowercase letters, digits, '-' and '_'")
+	}
+	exists, err := s.RoleExists(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("role %q already exists", req.Name)
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanGrant(actor, permissions); err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Permissions: strings.Join(permissions, ","),
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+// UpdateRole changes the description or permissions of a custom role.
+// Built-in roles cannot be changed, users cannot change their own role, and
+// only administrators may grant permissions their own role does not have.
+func (s *RoleService) UpdateRole(actorID uuid.UUID, name string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	if isBuiltInRole(name) {
+		return nil, errors.New("built-in roles cannot be changed")
+	}
+
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role == name {
+		return nil, fmt.Errorf("%w: cannot change your own role", ErrInsufficientPrivileges)
+	}
+
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Permissions != nil {
+		permissions, err := normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkCanGrant(actor, permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = strings.Join(permissions, ",")
+	}
+
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+// DeleteRole deletes a custom role that is not assigned to any user
+func (s *RoleService) DeleteRole(name string) error {
+	if isBuiltInRole(name) {
+		return errors.New("built-in roles cannot be deleted")
+	}
+
+	count, err := s.userRepo.CountByRole(name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("role is assigned to %d users", count)
+	}
+
+	return s.roleRepo.Delete(name)
+}
+
+// CheckCanManage checks that a user may act on the account of a target user:
+// only administrators may act on administrators
+func (s *RoleService) CheckCanManage(actor, target *model.User) error {
+	if target.Role == model.RoleAdmin && actor.Role != model.RoleAdmin {
+		return fmt.Errorf("%w: only administrators can manage administrators", ErrInsufficientPrivileges)
+	}
+	return nil
+}
+
+// CheckCanAssign checks that a user may assign a role: only administrators may
+// assign the admin role or a role with permissions their own role does not have
+func (s *RoleService) CheckCanAssign(actor *model.User, roleName string) error {
+	if actor.Role == model.RoleAdmin {
+		return nil
+	}
+	if roleName == model.RoleAdmin {
+		return fmt.Errorf("%w: only administrators can grant the admin role", ErrInsufficientPrivileges)
+	}
+
+	permissions, err := s.rolePermissions(roleName)
+	if err != nil {
+		return err
+	}
+	return s.checkCanGrant(actor, permissions)
+}
+
+// checkCanGrant checks that a user may grant permissions: administrators may grant
+// every permission, other users only the permissions of their own role
+func (s *RoleService) checkCanGrant(actor *model.User, permissions []string) error {
+	if actor.Role == model.RoleAdmin {
+		return nil
+	}
+
+	held, err := s.rolePermissions(actor.Role)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if !containsString(held, permission) {
+			return fmt.Errorf("%w: cannot grant the %s permission, which your role does not have", ErrInsufficientPrivileges, permission)
+		}
+	}
+	return nil
+}
+
+// rolePermissions returns the permissions a built-in or custom role grants
+func (s *RoleService) rolePermissions(name string) ([]string, error) {
+	switch name {
+	case model.RoleAdmin:
+		return model.Permissions, nil
+	case model.RoleUser:
+		return []string{}, nil
+	}
+
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return role.PermissionList(), nil
+}
+
+// PromoteAdmins gives the admin role to the users with the given emails
+func (s *RoleService) PromoteAdmins(emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	promoted, err := s.userRepo.SetRoleByEmails(emails, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if promoted > 0 {
+		log.Printf("Granted the admin role to %d users", promoted)
+	}
+	return nil
+}
+
+// normalizePermissions checks permissions and removes duplicates
+func normalizePermissions(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	permissions := make([]string, 0, len(requested))
+	for _, permission := range requested {
+		permission = strings.TrimSpace(permission)
+		if !isPermission(permission) {
+			return nil, fmt.Errorf("unknown permission %q: expected one of %s", permission, strings.Join(model.Permissions, ", "))
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
+
+// isPermission reports whether a permission exists
+func isPermission(permission string) bool {
+	return containsString(model.Permissions, permission)
+}
+
+// containsString reports whether a list contains a value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isBuiltInRole reports whether a role is defined in code
+func isBuiltInRole(name string) bool {
+	return name == model.RoleUser || name == model.RoleAdmin
+}
+
+// toRoleResponse converts a custom role to its response
+func toRoleResponse(role *model.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.PermissionList(),
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/llmchatbot/backend/internal/model"
+)
+
+func TestCheckCanManage(t *testing.T) {
+	s := &RoleService{}
+	admin := &model.User{Role: model.RoleAdmin}
+	support := &model.User{Role: "support"}
+	user := &model.User{Role: model.RoleUser}
+
+	tests := []struct {
+		actor, target *model.User
+		allowed       bool
+	}{
+		{admin, admin, true},
+		{admin, support, true},
+		{support, user, true},
+		{support, support, true},
+		{support, admin, false},
+	}
+	for _, tt := range tests {
+		err := s.CheckCanManage(tt.actor, tt.target)
+		if tt.allowed && err != nil {
+			t.Errorf("%s managing %s: error = %v", tt.actor.Role, tt.target.Role, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrInsufficientPrivileges) {
+			t.Errorf("%s managing %s: error = %v, want ErrInsufficientPrivileges", tt.actor.Role, tt.target.Role, err)
+		}
+	}
+}
+
+func TestCheckCanAssignBuiltInRoles(t *testing.T) {
+	s := &RoleService{}
+	admin := &model.User{Role: model.RoleAdmin}
+	user := &model.User{Role: model.RoleUser}
+
+	if err := s.CheckCanAssign(admin, model.RoleAdmin); err != nil {
+		t.Errorf("admin assigning admin: error = %v", err)
+	}
+	if err := s.CheckCanAssign(user, model.RoleUser); err != nil {
+		t.Errorf("assigning the default role: error = %v", err)
+	}
+	if err := s.CheckCanAssign(user, model.RoleAdmin); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("non-admin assigning admin: error = %v, want ErrInsufficientPrivileges", err)
+	}
+}
+
+func TestCheckCanGrant(t *testing.T) {
+	s := &RoleService{}
+
+	if err := s.checkCanGrant(&model.User{Role: model.RoleAdmin}, model.Permissions); err != nil {
+		t.Errorf("admin granting every permission: error = %v", err)
+	}
+	if err := s.checkCanGrant(&model.User{Role: model.RoleUser}, nil); err != nil {
+		t.Errorf("granting no permissions: error = %v", err)
+	}
+	err := s.checkCanGrant(&model.User{Role: model.RoleUser}, []string{model.PermissionUsersRead})
+	if !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("granting a permission the role lacks: error = %v, want ErrInsufficientPrivileges", err)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchStore runs full-text search queries
+type searchStore interface {
+	Search(filter repository.SearchFilter) ([]repository.SearchResult, int64, error)
+}
+
+// SearchService handles full-text search across chats and messages
+type SearchService struct {
+	searchRepo searchStore
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(searchRepo *repository.SearchRepository) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search finds chat titles and messages of a user matching the query
+func (s *SearchService) Search(filter repository.SearchFilter) (*dto.SearchResponse, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if filter.Role != "" && filter.Role != model.MessageRoleUser && filter.Role != model.MessageRoleAssistant && filter.Role != model.MessageRoleSystem {
+		return nil, errors.New("role must be user, assistant or system")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("from must be before to")
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	results, total, err := s.searchRepo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.SearchResponse{
+		Query:   filter.Query,
+		Results: make([]dto.SearchResultResponse, len(results)),
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}
+	for i, result := range results {
+		response.Results[i] = dto.SearchResultResponse{
+			Type:           result.Type,
+			ChatID:         result.ChatID.String(),
+			ChatTitle:      result.ChatTitle,
+			ModelUsed:      result.ModelUsed,
+			IsArchived:     result.IsArchived,
+			CreatedAt:      result.CreatedAt,
+			Rank:           result.Rank,
+			Snippet:        result.Snippet,
+			SequenceNumber: result.SequenceNumber,
+		}
+		if result.MessageID != nil {
+			response.Results[i].MessageID = result.MessageID.String()
+		}
+		if result.Role != nil {
+			response.Results[i].Role = *result.Role
+		}
+	}
+
+	return response, nil
+}
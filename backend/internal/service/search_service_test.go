@@ -0,0 +1,106 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// fakeSearch records the filter it was called with and returns fixed results
+type fakeSearch struct {
+	filter  *repository.SearchFilter
+	results []repository.SearchResult
+}
+
+func (f *fakeSearch) Search(filter repository.SearchFilter) ([]repository.SearchResult, int64, error) {
+	f.filter = &filter
+	return f.results, int64(len(f.results)), nil
+}
+
+func TestSearchValidation(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter repository.SearchFilter
+		want   string
+	}{
+		{"empty query", repository.SearchFilter{Query: "   "}, "query is required"},
+		{"unknown role", repository.SearchFilter{Query: "go", Role: "admin"}, "role must be"},
+		{"reversed range", repository.SearchFilter{Query: "go", From: &from, To: &to}, "from must be before to"},
+		{"empty range", repository.SearchFilter{Query: "go", From: &from, To: &from}, "from must be before to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeSearch{}
+			s := &SearchService{searchRepo: store}
+			if _, err := s.Search(tt.filter); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Search() error = %v, want %q", err, tt.want)
+			}
+			if store.filter != nil {
+				t.Error("invalid search reached the repository")
+			}
+		})
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	tests := []struct {
+		limit, offset         int
+		wantLimit, wantOffset int
+	}{
+		{0, 0, defaultSearchLimit, 0},
+		{-5, -1, defaultSearchLimit, 0},
+		{maxSearchLimit + 1, 40, maxSearchLimit, 40},
+		{10, 20, 10, 20},
+	}
+
+	for _, tt := range tests {
+		store := &fakeSearch{}
+		s := &SearchService{searchRepo: store}
+		response, err := s.Search(repository.SearchFilter{Query: "  go  ", Limit: tt.limit, Offset: tt.offset})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if store.filter.Query != "go" || store.filter.Limit != tt.wantLimit || store.filter.Offset != tt.wantOffset {
+			t.Errorf("limit %d, offset %d: searched %+v", tt.limit, tt.offset, *store.filter)
+		}
+		if response.Limit != tt.wantLimit || response.Offset != tt.wantOffset {
+			t.Errorf("limit %d, offset %d: response paging %d, %d", tt.limit, tt.offset, response.Limit, response.Offset)
+		}
+	}
+}
+
+func TestSearchResultsLinkToMessages(t *testing.T) {
+	messageID := uuid.New()
+	role := model.MessageRoleAssistant
+	sequenceNumber := 7
+	store := &fakeSearch{results: []repository.SearchResult{
+		{Type: "message", ChatID: uuid.New(), MessageID: &messageID, Role: &role, SequenceNumber: &sequenceNumber, Snippet: "<mark>go</mark>"},
+		{Type: "chat", ChatID: uuid.New(), ChatTitle: "Go questions"},
+	}}
+	s := &SearchService{searchRepo: store}
+
+	response, err := s.Search(repository.SearchFilter{Query: "go"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if response.Total != 2 || len(response.Results) != 2 {
+		t.Fatalf("Search() = %+v, want two results", response)
+	}
+
+	message := response.Results[0]
+	if message.MessageID != messageID.String() || message.Role != role || message.SequenceNumber == nil || *message.SequenceNumber != 7 {
+		t.Errorf("message result = %+v, want a link to message 7", message)
+	}
+	title := response.Results[1]
+	if title.MessageID != "" || title.Role != "" || title.SequenceNumber != nil {
+		t.Errorf("title result = %+v, want no message fields", title)
+	}
+}
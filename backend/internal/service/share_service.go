@@ -0,0 +1,189 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/utils"
+)
+
+// shareTokenLength is the length of share link tokens (hex characters)
+const shareTokenLength = 48
+
+var (
+	// ErrSharePasswordRequired is returned when a protected share link is opened without a password
+	ErrSharePasswordRequired = errors.New("share link requires a password")
+	// ErrShareInvalidPassword is returned when the share link password does not match
+	ErrShareInvalidPassword = errors.New("invalid share link password")
+)
+
+// shareStore stores share links
+type shareStore interface {
+	Create(share *model.ChatShare) error
+	GetByToken(token string) (*model.ChatShare, error)
+	GetActiveByUserID(userID, chatSessionID uuid.UUID) ([]model.ChatShare, error)
+	Revoke(id, userID uuid.UUID) error
+	IncrementViewCount(id uuid.UUID) error
+}
+
+// shareChatStore loads the chat sessions to share
+type shareChatStore interface {
+	GetWithMessages(id, userID uuid.UUID) (*model.ChatSession, error)
+}
+
+// ShareService handles public share links of chat sessions
+type ShareService struct {
+	shareRepo shareStore
+	chatRepo  shareChatStore
+}
+
+// NewShareService creates a new share service
+func NewShareService(shareRepo *repository.ChatShareRepository, chatRepo *repository.ChatRepository) *ShareService {
+	return &ShareService{
+		shareRepo: shareRepo,
+		chatRepo:  chatRepo,
+	}
+}
+
+// CreateShare publishes a snapshot of a chat session. Messages sent after
+// the link is created are not visible through it.
+func (s *ShareService) CreateShare(sessionID, userID uuid.UUID, req *dto.CreateChatShareRequest) (*dto.ChatShareResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	session, err := s.chatRepo.GetWithMessages(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(session.Messages) == 0 {
+		return nil, errors.New("cannot share a chat without messages")
+	}
+
+	snapshot := dto.SharedChatResponse{
+		Title:     session.Title,
+		ModelUsed: session.ModelUsed,
+		SharedAt:  time.Now().UTC(),
+		ExpiresAt: req.ExpiresAt,
+		Messages:  make([]dto.SharedMessageResponse, len(session.Messages)),
+	}
+	for i, msg := range session.Messages {
+		snapshot.Messages[i] = dto.SharedMessageResponse{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			CreatedAt: msg.CreatedAt,
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	token := utils.GenerateRandomString(shareTokenLength)
+	if token == "" {
+		return nil, errors.New("failed to generate share token")
+	}
+
+	share := &model.ChatShare{
+		UserID:        userID,
+		ChatSessionID: session.ID,
+		Token:         token,
+		Title:         session.Title,
+		Snapshot:      string(data),
+		MessageCount:  len(session.Messages),
+		ExpiresAt:     req.ExpiresAt,
+	}
+	if req.Password != "" {
+		hash, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		share.PasswordHash = hash
+	}
+
+	if err := s.shareRepo.Create(share); err != nil {
+		return nil, err
+	}
+
+	return toChatShareResponse(share), nil
+}
+
+// GetShares retrieves the active share links of a user, optionally of one chat session
+func (s *ShareService) GetShares(userID, sessionID uuid.UUID) ([]dto.ChatShareResponse, error) {
+	shares, err := s.shareRepo.GetActiveByUserID(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ChatShareResponse, len(shares))
+	for i := range shares {
+		responses[i] = *toChatShareResponse(&shares[i])
+	}
+	return responses, nil
+}
+
+// RevokeShare revokes a share link
+func (s *ShareService) RevokeShare(shareID, userID uuid.UUID) error {
+	return s.shareRepo.Revoke(shareID, userID)
+}
+
+// GetSharedChat returns the snapshot behind a share link. The owner of the
+// link (viewerID) can open it without the password.
+func (s *ShareService) GetSharedChat(token, password string, viewerID uuid.UUID) (*dto.SharedChatResponse, error) {
+	share, err := s.shareRepo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// Revoked and expired links are indistinguishable from unknown ones
+	if !share.IsActive(time.Now()) {
+		return nil, errors.New("share link not found")
+	}
+
+	isOwner := viewerID == share.UserID
+	if share.PasswordHash != "" && !isOwner {
+		if password == "" {
+			return nil, ErrSharePasswordRequired
+		}
+		if !utils.CheckPasswordHash(password, share.PasswordHash) {
+			return nil, ErrShareInvalidPassword
+		}
+	}
+
+	var snapshot dto.SharedChatResponse
+	if err := json.Unmarshal([]byte(share.Snapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read shared chat: %w", err)
+	}
+
+	if !isOwner {
+		if err := s.shareRepo.IncrementViewCount(share.ID); err != nil {
+			// Log error but don't fail the request
+			log.Printf("Warning: Could not count view of share link %s: %v", share.ID, err)
+		}
+	}
+
+	return &snapshot, nil
+}
+
+// toChatShareResponse converts a share link to its owner response
+func toChatShareResponse(share *model.ChatShare) *dto.ChatShareResponse {
+	return &dto.ChatShareResponse{
+		ID:           share.ID.String(),
+		ChatID:       share.ChatSessionID.String(),
+		Token:        share.Token,
+		URL:          "/api/v1/shared/" + share.Token,
+		Title:        share.Title,
+		MessageCount: share.MessageCount,
+		HasPassword:  share.PasswordHash != "",
+		ExpiresAt:    share.ExpiresAt,
+		ViewCount:    share.ViewCount,
+		CreatedAt:    share.CreatedAt,
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+)
+
+// fakeShares keeps share links in memory
+type fakeShares struct {
+	shares []*model.ChatShare
+}
+
+func (f *fakeShares) Create(share *model.ChatShare) error {
+	share.ID = uuid.New()
+	f.shares = append(f.shares, share)
+	return nil
+}
+
+func (f *fakeShares) GetByToken(token string) (*model.ChatShare, error) {
+	for _, share := range f.shares {
+		if share.Token == token {
+			return share, nil
+		}
+	}
+	return nil, errors.New("share link not found")
+}
+
+func (f *fakeShares) GetActiveByUserID(userID, chatSessionID uuid.UUID) ([]model.ChatShare, error) {
+	var shares []model.ChatShare
+	for _, share := range f.shares {
+		if share.UserID == userID && share.IsActive(time.Now()) && (chatSessionID == uuid.Nil || share.ChatSessionID == chatSessionID) {
+			shares = append(shares, *share)
+		}
+	}
+	return shares, nil
+}
+
+func (f *fakeShares) Revoke(id, userID uuid.UUID) error {
+	for _, share := range f.shares {
+		if share.ID == id && share.UserID == userID {
+			now := time.Now()
+			share.RevokedAt = &now
+			return nil
+		}
+	}
+	return errors.New("share link not found")
+}
+
+func (f *fakeShares) IncrementViewCount(id uuid.UUID) error {
+	for _, share := range f.shares {
+		if share.ID == id {
+			share.ViewCount++
+		}
+	}
+	return nil
+}
+
+// fakeShareChats serves one chat session of its owner
+type fakeShareChats struct {
+	session *model.ChatSession
+}
+
+func (f *fakeShareChats) GetWithMessages(id, userID uuid.UUID) (*model.ChatSession, error) {
+	if f.session.ID != id || f.session.UserID != userID {
+		return nil, errors.New("chat session not found")
+	}
+	copied := *f.session
+	copied.Messages = append([]model.Message(nil), f.session.Messages...)
+	return &copied, nil
+}
+
+func newTestShareService() (*ShareService, *fakeShares, *model.ChatSession) {
+	session := &model.ChatSession{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Title:     "Trip plan",
+		ModelUsed: "qwen2.5-3b",
+		Messages: []model.Message{
+			{SequenceNumber: 1, Role: model.MessageRoleUser, Content: "Plan a trip"},
+			{SequenceNumber: 2, Role: model.MessageRoleAssistant, Content: "Day 1: ..."},
+		},
+	}
+	shares := &fakeShares{}
+	return &ShareService{shareRepo: shares, chatRepo: &fakeShareChats{session: session}}, shares, session
+}
+
+func TestSharedChatIsSnapshot(t *testing.T) {
+	s, _, session := newTestShareService()
+
+	share, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+	if share.MessageCount != 2 || share.URL != "/api/v1/shared/"+share.Token || len(share.Token) != shareTokenLength {
+		t.Errorf("share = %+v", share)
+	}
+
+	// Messages sent after sharing are not exposed
+	session.Messages = append(session.Messages, model.Message{SequenceNumber: 3, Role: model.MessageRoleUser, Content: "private follow-up"})
+	session.Title = "Renamed"
+
+	shared, err := s.GetSharedChat(share.Token, "", uuid.Nil)
+	if err != nil {
+		t.Fatalf("GetSharedChat() error = %v", err)
+	}
+	if shared.Title != "Trip plan" || len(shared.Messages) != 2 || shared.Messages[1].Content != "Day 1: ..." {
+		t.Errorf("shared chat = %+v, want the snapshot", shared)
+	}
+}
+
+func TestCreateShareRejections(t *testing.T) {
+	s, shares, session := newTestShareService()
+	past := time.Now().Add(-time.Minute)
+
+	if _, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{ExpiresAt: &past}); err == nil {
+		t.Error("CreateShare() accepted an expiry in the past")
+	}
+	if _, err := s.CreateShare(session.ID, uuid.New(), &dto.CreateChatShareRequest{}); err == nil {
+		t.Error("CreateShare() shared a chat of another user")
+	}
+	session.Messages = nil
+	if _, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{}); err == nil {
+		t.Error("CreateShare() shared an empty chat")
+	}
+	if len(shares.shares) != 0 {
+		t.Errorf("%d share links stored", len(shares.shares))
+	}
+}
+
+func TestSharedChatPassword(t *testing.T) {
+	s, shares, session := newTestShareService()
+	share, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{Password: "open sesame"})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+	if !share.HasPassword || shares.shares[0].PasswordHash == "open sesame" {
+		t.Error("password not stored hashed")
+	}
+
+	if _, err := s.GetSharedChat(share.Token, "", uuid.Nil); !errors.Is(err, ErrSharePasswordRequired) {
+		t.Errorf("GetSharedChat() without password error = %v", err)
+	}
+	if _, err := s.GetSharedChat(share.Token, "guess", uuid.Nil); !errors.Is(err, ErrShareInvalidPassword) {
+		t.Errorf("GetSharedChat() with a wrong password error = %v", err)
+	}
+	if shares.shares[0].ViewCount != 0 {
+		t.Error("rejected views counted")
+	}
+
+	if _, err := s.GetSharedChat(share.Token, "open sesame", uuid.Nil); err != nil {
+		t.Errorf("GetSharedChat() with the password error = %v", err)
+	}
+	// The owner needs no password, and their views are not counted
+	if _, err := s.GetSharedChat(share.Token, "", session.UserID); err != nil {
+		t.Errorf("GetSharedChat() by the owner error = %v", err)
+	}
+	if shares.shares[0].ViewCount != 1 {
+		t.Errorf("view count = %d, want 1", shares.shares[0].ViewCount)
+	}
+}
+
+func TestSharedChatRevokedAndExpired(t *testing.T) {
+	s, shares, session := newTestShareService()
+	expiring := time.Now().Add(time.Hour)
+
+	revoked, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+	expired, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{ExpiresAt: &expiring})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+	active, err := s.CreateShare(session.ID, session.UserID, &dto.CreateChatShareRequest{})
+	if err != nil {
+		t.Fatalf("CreateShare() error = %v", err)
+	}
+
+	revokedID := uuid.MustParse(revoked.ID)
+	if err := s.RevokeShare(revokedID, uuid.New()); err == nil {
+		t.Error("RevokeShare() revoked a link of another user")
+	}
+	if err := s.RevokeShare(revokedID, session.UserID); err != nil {
+		t.Fatalf("RevokeShare() error = %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	shares.shares[1].ExpiresAt = &past
+
+	// Even the owner cannot open revoked or expired links
+	for _, token := range []string{revoked.Token, expired.Token} {
+		if _, err := s.GetSharedChat(token, "", session.UserID); err == nil {
+			t.Error("GetSharedChat() opened an inactive link")
+		}
+	}
+
+	list, err := s.GetShares(session.UserID, uuid.Nil)
+	if err != nil {
+		t.Fatalf("GetShares() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Token != active.Token {
+		t.Errorf("GetShares() = %+v, want only the active link", list)
+	}
+}
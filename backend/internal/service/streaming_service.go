@@ -16,33 +16,60 @@ import (
 
 // StreamingService handles streaming communication with LLM service
 type StreamingService struct {
-	llmClient *http.Client
-	llmURL    string
-	msgSvc    *MessageService
+	llmClient     *http.Client
+	httpClient    *http.Client
+	llmURL        string
+	msgSvc        *MessageService
+	attachmentSvc *AttachmentService
+	modelRegistry *ModelRegistry
 }
 
 // NewStreamingService creates a new streaming service
-// Note: This service is used ONLY for streaming requests to the LLM service.
-// The HTTP client has no timeout (Timeout: 0) because streaming can take a long time
-// and is limited by max_tokens anyway. Regular non-streaming HTTP requests
-// to other services should use a client with appropriate timeout settings.
-func NewStreamingService(cfg *config.Config, msgSvc *MessageService) *StreamingService {
+// Note: The streaming HTTP client has no timeout (Timeout: 0) because streaming can take
+// a long time and is limited by max_tokens anyway. Non-streaming requests (Generate)
+// use a separate client with the configured LLM service timeout.
+func NewStreamingService(cfg *config.Config, msgSvc *MessageService, attachmentSvc *AttachmentService, modelRegistry *ModelRegistry) *StreamingService {
 	return &StreamingService{
 		// Use client without timeout for streaming requests
 		// Streaming can take a long time, so we don't want to interrupt it
 		llmClient: &http.Client{
 			Timeout: 0, // No timeout for streaming requests (generation limited by max_tokens)
 		},
-		llmURL: cfg.LLM.BaseURL,
-		msgSvc: msgSvc,
+		httpClient: &http.Client{
+			Timeout: cfg.LLM.Timeout,
+		},
+		llmURL:        cfg.LLM.BaseURL,
+		msgSvc:        msgSvc,
+		attachmentSvc: attachmentSvc,
+		modelRegistry: modelRegistry,
 	}
 }
 
+// ImageInput represents a base64-encoded image content part
+type ImageInput struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+// ChatMessage represents a conversation message sent to LLM service
+type ChatMessage struct {
+	Role    string       `json:"role"`
+	Content string       `json:"content"`
+	Images  []ImageInput `json:"images,omitempty"` // Only sent to vision-capable models
+}
+
 // GenerationRequest represents request to LLM service
 type GenerationRequest struct {
-	Prompt  string                 `json:"prompt"`
-	History []*dto.MessageResponse `json:"history,omitempty"`
-	Model   string                 `json:"model,omitempty"`
+	Prompt    string        `json:"prompt"`
+	History   []ChatMessage `json:"history,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	MaxTokens int           `json:"max_tokens,omitempty"` // 0 = LLM service default
+}
+
+// GenerationResponse represents a non-streaming response from LLM service
+type GenerationResponse struct {
+	Response string `json:"response"`
+	Tokens   int    `json:"tokens"`
 }
 
 // TokenResponse represents a token response from LLM service
@@ -52,8 +79,73 @@ type TokenResponse struct {
 	Tokens  int    `json:"tokens,omitempty"`
 }
 
-// StreamGeneration streams tokens from LLM service
-func (s *StreamingService) StreamGeneration(sessionID uuid.UUID, message string, history []*dto.MessageResponse, model string) (<-chan TokenResponse, <-chan error) {
+// BuildHistory converts chat history to LLM service messages.
+// For vision-capable models, image attachments are included base64-encoded.
+func (s *StreamingService) BuildHistory(userID uuid.UUID, history []*dto.MessageResponse, model string) ([]ChatMessage, error) {
+	supportsImages := s.modelRegistry.SupportsImages(model)
+
+	messages := make([]ChatMessage, len(history))
+	for i, msg := range history {
+		messages[i] = ChatMessage{
+			Role:    msg.Role,
+			Content: msg.Content,
+		}
+		if !supportsImages {
+			continue
+		}
+
+		for _, attachment := range msg.Attachments {
+			if !isImageMimeType(attachment.MimeType) {
+				continue
+			}
+
+			attachmentID, err := uuid.Parse(attachment.ID)
+			if err != nil {
+				return nil, err
+			}
+			image, err := s.attachmentSvc.LoadImage(attachmentID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load image %s: %w", attachment.Filename, err)
+			}
+			messages[i].Images = append(messages[i].Images, *image)
+		}
+	}
+
+	return messages, nil
+}
+
+// Generate requests a complete (non-streaming) response from LLM service
+func (s *StreamingService) Generate(message string, history []ChatMessage, model string) (string, error) {
+	jsonData, err := json.Marshal(GenerationRequest{
+		Prompt:  message,
+		History: history,
+		Model:   model,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := s.httpClient.Post(fmt.Sprintf("%s/api/v1/generate/", s.llmURL), "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LLM service returned status %d", resp.StatusCode)
+	}
+
+	var result GenerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Response, nil
+}
+
+// StreamGeneration streams tokens from LLM service.
+// maxTokens limits the response length (0 = LLM service default).
+func (s *StreamingService) StreamGeneration(sessionID uuid.UUID, message string, history []ChatMessage, model string, maxTokens int) (<-chan TokenResponse, <-chan error) {
 	tokenChan := make(chan TokenResponse, 100)
 	errChan := make(chan error, 1)
 
@@ -63,9 +155,10 @@ func (s *StreamingService) StreamGeneration(sessionID uuid.UUID, message string,
 
 		// Prepare request
 		reqBody := GenerationRequest{
-			Prompt:  message,
-			History: history,
-			Model:   model,
+			Prompt:    message,
+			History:   history,
+			Model:     model,
+			MaxTokens: maxTokens,
 		}
 
 		jsonData, err := json.Marshal(reqBody)
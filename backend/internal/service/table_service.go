@@ -0,0 +1,367 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/pkg/tabular"
+)
+
+// maxCachedTables limits the number of parsed tables kept in memory
+const maxCachedTables = 32
+
+// tableQueryHelp describes the query language to the model
+const tableQueryHelp = `Query language (stages separated by "|"):
+  from <table> | where <column> <op> <value> [and ...]   (op: = != < <= > >= contains)
+  group by <column>, ... aggregate <agg>, ...             (agg: count(), sum(col), avg(col), min(col), max(col), optionally "as name")
+  aggregate <agg>, ...                                    (totals over all rows)
+  select <column>, ... | sort <column> [asc|desc] | limit <n>
+Quote column names with spaces in backticks and text values in single quotes.
+Example: from ` + "`sales.csv`" + ` | where region = 'EU' | group by product aggregate sum(amount) as total | sort total desc | limit 5`
+
+// TableService lets the model answer questions about CSV/TSV attachments
+// by running queries in the restricted tabular query language
+type TableService struct {
+	attachmentSvc *AttachmentService
+	streamingSvc  *StreamingService
+	cfg           config.TabularConfig
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]*tabular.Table // Parsed tables by attachment ID (attachments are immutable)
+}
+
+// NewTableService creates a new table service
+func NewTableService(attachmentSvc *AttachmentService, streamingSvc *StreamingService, cfg *config.Config) *TableService {
+	return &TableService{
+		attachmentSvc: attachmentSvc,
+		streamingSvc:  streamingSvc,
+		cfg:           cfg.Tabular,
+		cache:         make(map[uuid.UUID]*tabular.Table),
+	}
+}
+
+// AnswerWithTables gives the model a query tool over the CSV/TSV attachments of a chat session.
+// If the question looks like it needs the table data, the model is first asked which queries
+// to run; the queries are executed and the returned prompt contains their results as Markdown
+// tables, so the answer uses computed numbers. Other questions only get the table schemas,
+// without the extra model round trip. The message (the prompt built for the question so far)
+// is returned unchanged if the session has no table attachments.
+func (s *TableService) AnswerWithTables(userID, sessionID uuid.UUID, question, message string, history []ChatMessage, model string) (string, []dto.TableQueryResponse, error) {
+	tables, err := s.loadTables(userID, sessionID)
+	if err != nil || len(tables) == 0 {
+		return message, nil, err
+	}
+
+	var schema strings.Builder
+	for _, table := range tables {
+		schema.WriteString(table.Describe(3))
+		schema.WriteString("\n")
+	}
+
+	results := make([]dto.TableQueryResponse, 0)
+	if needsTableQuery(question, tables) {
+		results, err = s.planQueries(tables, schema.String(), message, history, model)
+		if err != nil {
+			return message, nil, err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("The user attached the following tables.\n\n")
+	b.WriteString(schema.String())
+	if len(results) > 0 {
+		b.WriteString("\nThese query results were computed exactly from the tables. ")
+		b.WriteString("Use these numbers as they are, do not recalculate them.\n\n")
+		for _, result := range results {
+			fmt.Fprintf(&b, "Query: %s\n", result.Query)
+			if result.Error != "" {
+				fmt.Fprintf(&b, "Error: %s\n\n", result.Error)
+			} else {
+				fmt.Fprintf(&b, "Result:\n%s\n", result.Result)
+			}
+		}
+	}
+	b.WriteString("\nQuestion: ")
+	b.WriteString(message)
+
+	return b.String(), results, nil
+}
+
+// planQueries asks the model which queries answer the question and runs them
+func (s *TableService) planQueries(tables []*tabular.Table, schema, message string, history []ChatMessage, model string) ([]dto.TableQueryResponse, error) {
+	var plan strings.Builder
+	plan.WriteString("You can query the user's tables to answer the question.\n\n")
+	plan.WriteString(schema)
+	plan.WriteString(tableQueryHelp)
+	fmt.Fprintf(&plan, "\n\nReply only with up to %d lines of the form \"QUERY: <query>\" ", s.cfg.MaxQueries)
+	plan.WriteString("that compute the data needed to answer, or \"NONE\" if no query is needed.\n\n")
+	plan.WriteString("Question: ")
+	plan.WriteString(message)
+
+	response, err := s.streamingSvc.Generate(plan.String(), history, model)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.TableQueryResponse, 0)
+	for _, query := range extractQueries(response, s.cfg.MaxQueries) {
+		results = append(results, s.runQuery(tables, query))
+	}
+	return results, nil
+}
+
+// runQuery parses and executes a query against the matching table
+func (s *TableService) runQuery(tables []*tabular.Table, queryText string) dto.TableQueryResponse {
+	result := dto.TableQueryResponse{Query: queryText}
+
+	query, err := tabular.ParseQuery(queryText)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	table, err := findTable(tables, query.Table)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Table = table.Name
+
+	output, err := query.Execute(table)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Result = output.Markdown(s.cfg.ResultRows)
+	return result
+}
+
+// loadTables parses the CSV/TSV attachments of a chat session
+func (s *TableService) loadTables(userID, sessionID uuid.UUID) ([]*tabular.Table, error) {
+	attachments, err := s.attachmentSvc.GetAttachments(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*tabular.Table
+	names := make(map[string]bool)
+	for _, attachment := range attachments {
+		if !isTableAttachment(attachment) {
+			continue
+		}
+
+		table, err := s.loadTable(attachment, userID)
+		if err != nil {
+			// Log error but keep the remaining tables usable
+			log.Printf("Warning: Could not load table %s: %v", attachment.Filename, err)
+			continue
+		}
+
+		// Tables are referenced by file name; disambiguate duplicates
+		name := table.Name
+		for i := 2; names[strings.ToLower(name)]; i++ {
+			name = fmt.Sprintf("%s (%d)", table.Name, i)
+		}
+		names[strings.ToLower(name)] = true
+		if name != table.Name {
+			renamed := *table
+			renamed.Name = name
+			table = &renamed
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// loadTable returns a parsed attachment from cache or parses its content
+func (s *TableService) loadTable(attachment dto.AttachmentResponse, userID uuid.UUID) (*tabular.Table, error) {
+	attachmentID, err := uuid.Parse(attachment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	table, ok := s.cache[attachmentID]
+	s.mu.Unlock()
+	if ok {
+		return table, nil
+	}
+
+	_, content, err := s.attachmentSvc.OpenAttachment(attachmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err = tabular.Parse(attachment.Filename, data, s.cfg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= maxCachedTables {
+		// Evict an arbitrary entry; tables are cheap to parse again
+		for id := range s.cache {
+			delete(s.cache, id)
+			break
+		}
+	}
+	s.cache[attachmentID] = table
+	s.mu.Unlock()
+
+	return table, nil
+}
+
+// findTable resolves the table a query refers to
+func findTable(tables []*tabular.Table, name string) (*tabular.Table, error) {
+	if name == "" {
+		if len(tables) == 1 {
+			return tables[0], nil
+		}
+		return nil, fmt.Errorf("query must name a table with \"from\" (%d tables attached)", len(tables))
+	}
+
+	for _, table := range tables {
+		if strings.EqualFold(table.Name, name) {
+			return table, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown table %q", name)
+}
+
+// needsTableQuery reports whether a question likely needs computations over the
+// attached tables: it names a table or column, or asks for counts, totals,
+// extremes, rankings or comparisons
+func needsTableQuery(question string, tables []*tabular.Table) bool {
+	words := splitWords(question)
+	if strings.Contains(question, "%") {
+		return true
+	}
+
+	for _, table := range tables {
+		name := strings.TrimSuffix(table.Name, filepath.Ext(table.Name))
+		if containsPhrase(words, name) {
+			return true
+		}
+		for _, column := range table.Columns {
+			if containsPhrase(words, column.Name) {
+				return true
+			}
+		}
+	}
+
+	for _, phrase := range tableQueryPhrases {
+		if containsPhrase(words, phrase) {
+			return true
+		}
+	}
+	for _, word := range words {
+		for _, stem := range tableQueryStems {
+			if strings.HasPrefix(word, stem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableQueryPhrases are English words and phrases of questions about table data
+var tableQueryPhrases = []string{
+	"table", "tables", "csv", "tsv", "spreadsheet", "column", "columns", "row", "rows", "data",
+	"how many", "how much", "number of", "count", "total", "totals", "sum", "average", "avg", "mean",
+	"median", "max", "maximum", "min", "minimum", "highest", "lowest", "largest", "smallest",
+	"biggest", "most", "least", "top", "rank", "ranking", "sort", "sorted", "percent",
+	"percentage", "compare", "group", "per", "each", "filter",
+}
+
+// tableQueryStems are Russian word stems of questions about table data
+var tableQueryStems = []string{
+	"таблиц", "столб", "колонк", "строк", "данн", "сколько", "количеств", "число",
+	"итог", "всего", "сумм", "средн", "медиан", "максим", "миним", "наибол", "наимен",
+	"больше", "меньше", "топ", "процент", "сравн", "группир", "кажд", "сортир", "фильтр",
+}
+
+// splitWords splits text into lowercase words
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsPhrase reports whether the words of a phrase occur consecutively in words
+func containsPhrase(words []string, phrase string) bool {
+	target := splitWords(phrase)
+	if len(target) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(target) <= len(words); i++ {
+		match := true
+		for j := range target {
+			if words[i+j] != target[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// extractQueries collects "QUERY:" lines from a model response
+func extractQueries(response string, limit int) []string {
+	var queries []string
+	for _, line := range strings.Split(response, "\n") {
+		line = unwrapBackticks(strings.TrimSpace(line))
+		if len(line) < 6 || !strings.EqualFold(line[:6], "QUERY:") {
+			continue
+		}
+
+		if query := unwrapBackticks(strings.TrimSpace(line[6:])); query != "" {
+			queries = append(queries, query)
+		}
+		if len(queries) == limit {
+			break
+		}
+	}
+	return queries
+}
+
+// unwrapBackticks removes backticks the model put around a whole line (inline code)
+func unwrapBackticks(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, "`") && strings.HasSuffix(s, "`") && strings.Count(s, "`") == 2 {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// isTableAttachment reports whether an attachment is a CSV or TSV file
+func isTableAttachment(attachment dto.AttachmentResponse) bool {
+	switch strings.ToLower(filepath.Ext(attachment.Filename)) {
+	case ".csv", ".tsv":
+		return true
+	}
+	switch attachment.MimeType {
+	case "text/csv", "text/tab-separated-values":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/llmchatbot/backend/pkg/tabular"
+)
+
+func TestNeedsTableQuery(t *testing.T) {
+	table, err := tabular.Parse("sales_2024.csv", []byte("Region,Unit Price\nEU,3\n"), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tables := []*tabular.Table{table}
+
+	tests := map[string]bool{
+		"What was the total revenue?":             true,
+		"How many orders came from Europe?":       true,
+		"Show me the top 5 products":              true,
+		"Which region sold the most?":             true,
+		"What is the unit price in EU?":           true, // column name
+		"Summarize sales_2024 for me":             true, // table name
+		"Сколько заказов было в марте?":           true,
+		"Какая средняя цена?":                     true,
+		"Write a short poem about autumn":         false,
+		"Thanks, that helps!":                     false,
+		"Can you explain this topic in a summary": false, // no partial-word matches
+		"Переведи это письмо на английский":       false,
+	}
+	for question, want := range tests {
+		if got := needsTableQuery(question, tables); got != want {
+			t.Errorf("needsTableQuery(%q) = %v, want %v", question, got, want)
+		}
+	}
+}
+
+func TestExtractQueries(t *testing.T) {
+	response := "Sure.\nQUERY: from `a.csv` | limit 1\n`query: aggregate count()`\nQUERY:\nNONE\nQuery: limit 3\n"
+
+	want := []string{"from `a.csv` | limit 1", "aggregate count()"}
+	if got := extractQueries(response, 2); !reflect.DeepEqual(got, want) {
+		t.Fatalf("extractQueries() = %q, want %q", got, want)
+	}
+	if got := extractQueries("NONE", 3); len(got) != 0 {
+		t.Fatalf("extractQueries(NONE) = %q, want none", got)
+	}
+}
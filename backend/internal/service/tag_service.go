@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/dto"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+)
+
+// defaultTagColor is used when a tag is created without a color
+const defaultTagColor = "#6b7280"
+
+// TagService handles chat tags
+type TagService struct {
+	tagRepo  *repository.TagRepository
+	chatRepo *repository.ChatRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(tagRepo *repository.TagRepository, chatRepo *repository.ChatRepository) *TagService {
+	return &TagService{
+		tagRepo:  tagRepo,
+		chatRepo: chatRepo,
+	}
+}
+
+// GetTags retrieves all tags of a user
+func (s *TagService) GetTags(userID uuid.UUID) ([]dto.TagResponse, error) {
+	tags, err := s.tagRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toTagResponses(tags), nil
+}
+
+// CreateTag creates a tag; tag names are unique per user (case-insensitive)
+func (s *TagService) CreateTag(userID uuid.UUID, req *dto.CreateTagRequest) (*dto.TagResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if _, err := s.tagRepo.GetByName(userID, name); err == nil {
+		return nil, errors.New("tag already exists")
+	}
+
+	color := req.Color
+	if color == "" {
+		color = defaultTagColor
+	}
+
+	tag := &model.Tag{
+		UserID: userID,
+		Name:   name,
+		Color:  strings.ToLower(color),
+	}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, err
+	}
+
+	return toTagResponse(tag), nil
+}
+
+// UpdateTag renames and recolors a tag
+func (s *TagService) UpdateTag(tagID, userID uuid.UUID, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	tag, err := s.tagRepo.GetByIDAndUserID(tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if existing, err := s.tagRepo.GetByName(userID, name); err == nil && existing.ID != tag.ID {
+		return nil, errors.New("tag already exists")
+	}
+
+	tag.Name = name
+	tag.Color = strings.ToLower(req.Color)
+	if err := s.tagRepo.Update(tag); err != nil {
+		return nil, err
+	}
+
+	return toTagResponse(tag), nil
+}
+
+// DeleteTag deletes a tag and removes it from all chat sessions
+func (s *TagService) DeleteTag(tagID, userID uuid.UUID) error {
+	return s.tagRepo.Delete(tagID, userID)
+}
+
+// TagChats adds tags to multiple chat sessions
+func (s *TagService) TagChats(sessionIDs []uuid.UUID, userID uuid.UUID, tagIDs []uuid.UUID) error {
+	if err := s.checkTags(userID, tagIDs); err != nil {
+		return err
+	}
+	return s.chatRepo.AddTags(sessionIDs, userID, tagIDs)
+}
+
+// UntagChats removes tags from multiple chat sessions
+func (s *TagService) UntagChats(sessionIDs []uuid.UUID, userID uuid.UUID, tagIDs []uuid.UUID) error {
+	if err := s.checkTags(userID, tagIDs); err != nil {
+		return err
+	}
+	return s.chatRepo.RemoveTags(sessionIDs, userID, tagIDs)
+}
+
+// checkTags verifies that all tags belong to the user
+func (s *TagService) checkTags(userID uuid.UUID, tagIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		unique[id] = true
+	}
+
+	count, err := s.tagRepo.CountByIDs(tagIDs, userID)
+	if err != nil {
+		return err
+	}
+	if int(count) != len(unique) {
+		return errors.New("tag not found")
+	}
+	return nil
+}
+
+// toTagResponse converts a Tag model to response DTO
+func toTagResponse(tag *model.Tag) *dto.TagResponse {
+	return &dto.TagResponse{
+		ID:        tag.ID.String(),
+		Name:      tag.Name,
+		Color:     tag.Color,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+// toTagResponses converts Tag models to response DTOs
+func toTagResponses(tags []model.Tag) []dto.TagResponse {
+	responses := make([]dto.TagResponse, len(tags))
+	for i := range tags {
+		responses[i] = *toTagResponse(&tags[i])
+	}
+	return responses
+}
@@ -0,0 +1,64 @@
+package service
+
+import "strings"
+
+// userAgentToken maps a user agent substring to a readable name
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+// Browsers recognized in user agents, in match order: most browsers also
+// mention Chrome and Safari for compatibility
+var userAgentBrowsers = []userAgentToken{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// Operating systems recognized in user agents, in match order: Android
+// mentions Linux and iOS mentions Mac OS X
+var userAgentSystems = []userAgentToken{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// describeDevice returns a short description of a client such as "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if strings.TrimSpace(userAgent) == "" {
+		return "Unknown device"
+	}
+
+	browser := matchUserAgent(userAgent, userAgentBrowsers)
+	system := matchUserAgent(userAgent, userAgentSystems)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	// Other clients usually start with their product name, e.g. "curl/8.4.0"
+	product, _, _ := strings.Cut(userAgent, "/")
+	return strings.TrimSpace(product)
+}
+
+// matchUserAgent returns the name of the first token found in a user agent
+func matchUserAgent(userAgent string, tokens []userAgentToken) string {
+	for _, t := range tokens {
+		if strings.Contains(userAgent, t.token) {
+			return t.name
+		}
+	}
+	return ""
+}
@@ -28,12 +28,14 @@ func (s *UserService) GetProfile(userID uuid.UUID) (*dto.UserResponse, error) {
 	}
 
 	return &dto.UserResponse{
-		ID:          user.ID.String(),
-		Email:       user.Email,
-		Username:    user.Username,
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt,
-		IsActive:    user.IsActive,
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		Username:      user.Username,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
+		IsActive:      user.IsActive,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
 	}, nil
 }
 
@@ -61,11 +63,13 @@ func (s *UserService) UpdateProfile(userID uuid.UUID, req *dto.UpdateUserRequest
 	}
 
 	return &dto.UserResponse{
-		ID:          user.ID.String(),
-		Email:       user.Email,
-		Username:    user.Username,
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt,
-		IsActive:    user.IsActive,
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		Username:      user.Username,
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
+		IsActive:      user.IsActive,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
 	}, nil
 }
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/llmchatbot/backend/internal/config"
+	"github.com/llmchatbot/backend/internal/model"
+	"github.com/llmchatbot/backend/internal/repository"
+	"github.com/llmchatbot/backend/pkg/vectorindex"
+)
+
+const (
+	// vectorIndexFlushInterval is how often changes of the in-memory index are written to disk
+	vectorIndexFlushInterval = 30 * time.Second
+	// vectorIndexPruneBatchSize limits the number of documents checked per query when pruning
+	vectorIndexPruneBatchSize = 1000
+)
+
+// VectorIndex finds document chunks similar to a query embedding
+type VectorIndex interface {
+	// Add indexes chunks of a newly uploaded document
+	Add(chunks []model.DocumentChunk) error
+	// Remove drops all chunks of a document from the index
+	Remove(documentID uuid.UUID) error
+	// Search returns up to k chunks of the given documents with a score of at least minScore
+	Search(query []float32, documentIDs []uuid.UUID, k int, minScore float64) ([]vectorindex.Result, error)
+	// Prune drops chunks of documents deleted without Remove, e.g. by cascades
+	Prune() error
+	// Close releases the index, writing pending changes
+	Close() error
+}
+
+// NewVectorIndex creates the vector index selected in configuration.
+// The in-memory index falls back to Postgres if it cannot be loaded.
+func NewVectorIndex(cfg *config.Config, documentRepo *repository.DocumentRepository) VectorIndex {
+	if cfg.RAG.VectorIndex == "memory" {
+		index, err := NewMemoryVectorIndex(documentRepo, cfg.RAG.IndexPath)
+		if err == nil {
+			return index
+		}
+		log.Printf("Failed to load in-memory vector index, falling back to Postgres: %v", err)
+	}
+	return NewPostgresVectorIndex(documentRepo)
+}
+
+// PostgresVectorIndex scores chunk embeddings stored in Postgres on every search
+type PostgresVectorIndex struct {
+	documentRepo *repository.DocumentRepository
+}
+
+// NewPostgresVectorIndex creates a new Postgres-backed vector index
+func NewPostgresVectorIndex(documentRepo *repository.DocumentRepository) *PostgresVectorIndex {
+	return &PostgresVectorIndex{documentRepo: documentRepo}
+}
+
+// Add is a no-op: chunk embeddings are already stored with the chunks
+func (ix *PostgresVectorIndex) Add(chunks []model.DocumentChunk) error {
+	return nil
+}
+
+// Remove is a no-op: chunk embeddings are deleted with the chunks
+func (ix *PostgresVectorIndex) Remove(documentID uuid.UUID) error {
+	return nil
+}
+
+// Search scores the embeddings of all chunks of the given documents
+func (ix *PostgresVectorIndex) Search(query []float32, documentIDs []uuid.UUID, k int, minScore float64) ([]vectorindex.Result, error) {
+	chunks, err := ix.documentRepo.GetChunkEmbeddings(documentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	index := vectorindex.New()
+	for _, chunk := range chunks {
+		index.Add(chunk.ID, chunk.DocumentID, chunk.Embedding)
+	}
+
+	return index.Search(query, k, minScore, nil), nil
+}
+
+// Prune is a no-op: chunk embeddings are deleted with the chunks
+func (ix *PostgresVectorIndex) Prune() error {
+	return nil
+}
+
+// Close is a no-op
+func (ix *PostgresVectorIndex) Close() error {
+	return nil
+}
+
+// MemoryVectorIndex keeps all chunk embeddings in an in-process flat index.
+// The index is persisted to a file if a path is configured and rebuilt from Postgres otherwise.
+// Changes are written to the file in the background at most every vectorIndexFlushInterval.
+type MemoryVectorIndex struct {
+	index        *vectorindex.FlatIndex
+	documentRepo *repository.DocumentRepository
+	path         string
+	dirty        atomic.Bool
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// NewMemoryVectorIndex loads the index from disk or rebuilds it from Postgres
+func NewMemoryVectorIndex(documentRepo *repository.DocumentRepository, path string) (*MemoryVectorIndex, error) {
+	count, err := documentRepo.CountChunks()
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &MemoryVectorIndex{documentRepo: documentRepo, path: path}
+
+	if path != "" {
+		index, err := vectorindex.Load(path)
+		switch {
+		case err == nil && int64(index.Len()) == count:
+			ix.index = index
+			log.Printf("Loaded vector index with %d chunks from %s", index.Len(), path)
+			ix.startFlusher()
+			return ix, nil
+		case err == nil:
+			log.Printf("Vector index at %s is out of date, rebuilding", path)
+		case !errors.Is(err, os.ErrNotExist):
+			log.Printf("Failed to load vector index from %s, rebuilding: %v", path, err)
+		}
+	}
+
+	ix.index = vectorindex.New()
+	err = documentRepo.EachChunkEmbedding(1000, func(chunks []model.DocumentChunk) error {
+		for _, chunk := range chunks {
+			ix.index.Add(chunk.ID, chunk.DocumentID, chunk.Embedding)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Built vector index with %d chunks", ix.index.Len())
+
+	if err := ix.save(); err != nil {
+		return nil, err
+	}
+	ix.startFlusher()
+	return ix, nil
+}
+
+// Add indexes chunks of a newly uploaded document
+func (ix *MemoryVectorIndex) Add(chunks []model.DocumentChunk) error {
+	for _, chunk := range chunks {
+		ix.index.Add(chunk.ID, chunk.DocumentID, chunk.Embedding)
+	}
+	ix.dirty.Store(true)
+	return nil
+}
+
+// Remove drops all chunks of a document from the index
+func (ix *MemoryVectorIndex) Remove(documentID uuid.UUID) error {
+	if ix.index.RemoveGroup(documentID) > 0 {
+		ix.dirty.Store(true)
+	}
+	return nil
+}
+
+// Prune drops chunks of documents that no longer exist, such as documents
+// deleted together with their chat or their user
+func (ix *MemoryVectorIndex) Prune() error {
+	groups := ix.index.Groups()
+	for start := 0; start < len(groups); start += vectorIndexPruneBatchSize {
+		end := start + vectorIndexPruneBatchSize
+		if end > len(groups) {
+			end = len(groups)
+		}
+		batch := groups[start:end]
+
+		existing, err := ix.documentRepo.GetExistingIDs(batch)
+		if err != nil {
+			return err
+		}
+		exists := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			exists[id] = true
+		}
+
+		for _, id := range batch {
+			if !exists[id] {
+				_ = ix.Remove(id)
+			}
+		}
+	}
+	return nil
+}
+
+// Search returns the chunks of the given documents most similar to the query
+func (ix *MemoryVectorIndex) Search(query []float32, documentIDs []uuid.UUID, k int, minScore float64) ([]vectorindex.Result, error) {
+	groups := make(map[uuid.UUID]bool, len(documentIDs))
+	for _, id := range documentIDs {
+		groups[id] = true
+	}
+	return ix.index.Search(query, k, minScore, groups), nil
+}
+
+// Close stops background persistence and writes pending changes
+func (ix *MemoryVectorIndex) Close() error {
+	if ix.stop != nil {
+		close(ix.stop)
+		<-ix.done
+	}
+	return ix.flush()
+}
+
+// startFlusher periodically writes pending changes if a path is configured
+func (ix *MemoryVectorIndex) startFlusher() {
+	if ix.path == "" {
+		return
+	}
+
+	ix.stop = make(chan struct{})
+	ix.done = make(chan struct{})
+	go func() {
+		defer close(ix.done)
+
+		ticker := time.NewTicker(vectorIndexFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ix.stop:
+				return
+			case <-ticker.C:
+				if err := ix.flush(); err != nil {
+					log.Printf("Failed to save vector index to %s: %v", ix.path, err)
+				}
+			}
+		}
+	}()
+}
+
+// flush saves the index if it changed since the last save
+func (ix *MemoryVectorIndex) flush() error {
+	if !ix.dirty.Swap(false) {
+		return nil
+	}
+	if err := ix.save(); err != nil {
+		// Retry on the next flush
+		ix.dirty.Store(true)
+		return err
+	}
+	return nil
+}
+
+// save persists the index if a path is configured
+func (ix *MemoryVectorIndex) save() error {
+	if ix.path == "" {
+		return nil
+	}
+	return ix.index.Save(ix.path)
+}
@@ -0,0 +1,19 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// Store stores binary objects by key
+type Store interface {
+	// Put stores data under the given key, replacing any existing blob
+	Put(key string, data []byte, contentType string) error
+	// Get opens the blob stored under the given key
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under the given key; deleting a missing blob is not an error
+	Delete(key string) error
+}
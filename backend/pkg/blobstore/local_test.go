@@ -0,0 +1,74 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	key := "attachments/user/file.txt"
+	if err := store.Put(key, []byte("first"), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put(key, []byte("second"), "text/plain"); err != nil {
+		t.Fatalf("Put() replacing error = %v", err)
+	}
+
+	reader, err := store.Get(key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "second" {
+		t.Fatalf("Get() = %q, want %q", data, "second")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(root, "attachments", "user"))
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d entries, want only the blob", len(entries))
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete() of a missing blob error = %v", err)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	for _, key := range []string{"", "/", "../secret", "a/../../secret", "a/.."} {
+		if err := store.Put(key, []byte("x"), ""); err == nil {
+			t.Errorf("Put(%q) should fail", key)
+		}
+		if _, err := store.Get(key); err == nil {
+			t.Errorf("Get(%q) should fail", key)
+		}
+	}
+
+	// Absolute keys stay inside the root
+	if err := store.Put("/abs/key", []byte("x"), ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.root, "abs", "key")); err != nil {
+		t.Fatalf("blob not stored under the root: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+// Package chatgptexport parses the conversations.json file of a ChatGPT data export.
+//
+// Each conversation stores its messages as a tree of mapping nodes (edited prompts
+// and regenerated answers create branches). The branch ending at current_node is
+// the one the user last saw, so it is the one returned.
+package chatgptexport
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Conversation is a linearized ChatGPT conversation
+type Conversation struct {
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Model     string // Model slug of the conversation, e.g. "gpt-4o" (may be empty)
+	Messages  []Message
+}
+
+// Message is a text message of a conversation
+type Message struct {
+	Role      string // "user", "assistant" or "system"
+	Content   string
+	CreatedAt time.Time // Zero if the export has no timestamp for the message
+}
+
+// rawConversation mirrors a conversation object of conversations.json
+type rawConversation struct {
+	Title            string              `json:"title"`
+	CreateTime       *float64            `json:"create_time"`
+	UpdateTime       *float64            `json:"update_time"`
+	CurrentNode      string              `json:"current_node"`
+	DefaultModelSlug string              `json:"default_model_slug"`
+	Mapping          map[string]*rawNode `json:"mapping"`
+}
+
+type rawNode struct {
+	ID       string      `json:"id"`
+	Message  *rawMessage `json:"message"`
+	Parent   *string     `json:"parent"`
+	Children []string    `json:"children"`
+}
+
+type rawMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime *float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+		Text        string            `json:"text"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug                        string `json:"model_slug"`
+		IsVisuallyHiddenFromConversation bool   `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+// IsConversation reports whether a JSON object looks like a ChatGPT conversation
+func IsConversation(data json.RawMessage) bool {
+	var probe struct {
+		Mapping json.RawMessage `json:"mapping"`
+	}
+	return json.Unmarshal(data, &probe) == nil && len(probe.Mapping) > 0 && probe.Mapping[0] == '{'
+}
+
+// ParseConversation parses a single conversation object
+func ParseConversation(data json.RawMessage) (*Conversation, error) {
+	var raw rawConversation
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for id, node := range raw.Mapping {
+		// Exports occasionally contain null mapping entries
+		if node == nil {
+			delete(raw.Mapping, id)
+		}
+	}
+	if len(raw.Mapping) == 0 {
+		return nil, errors.New("conversation has no messages")
+	}
+
+	conversation := &Conversation{
+		Title:     strings.TrimSpace(raw.Title),
+		CreatedAt: unixTime(raw.CreateTime),
+		UpdatedAt: unixTime(raw.UpdateTime),
+		Model:     raw.DefaultModelSlug,
+	}
+
+	for _, node := range raw.branch() {
+		msg := node.Message
+		if msg == nil || msg.Metadata.IsVisuallyHiddenFromConversation {
+			continue
+		}
+
+		role := msg.Author.Role
+		if role != "user" && role != "assistant" && role != "system" {
+			// Tool calls and their outputs are not part of the visible conversation
+			continue
+		}
+
+		content := strings.TrimSpace(msg.text())
+		if content == "" {
+			continue
+		}
+
+		if role == "assistant" && msg.Metadata.ModelSlug != "" {
+			conversation.Model = msg.Metadata.ModelSlug
+		}
+		conversation.Messages = append(conversation.Messages, Message{
+			Role:      role,
+			Content:   content,
+			CreatedAt: unixTime(msg.CreateTime),
+		})
+	}
+
+	if len(conversation.Messages) == 0 {
+		return nil, errors.New("conversation has no text messages")
+	}
+	return conversation, nil
+}
+
+// branch returns the nodes from the root to the current node.
+// If current_node is missing, the branch follows the last child at every fork.
+func (c *rawConversation) branch() []*rawNode {
+	leaf := c.Mapping[c.CurrentNode]
+	if leaf == nil {
+		leaf = c.lastLeaf()
+	}
+
+	var nodes []*rawNode
+	seen := make(map[*rawNode]bool)
+	for node := leaf; node != nil && !seen[node]; {
+		seen[node] = true
+		nodes = append(nodes, node)
+		if node.Parent == nil {
+			break
+		}
+		node = c.Mapping[*node.Parent]
+	}
+
+	// Reverse to get chronological order
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	return nodes
+}
+
+// lastLeaf walks from the root following the last known child of each node
+func (c *rawConversation) lastLeaf() *rawNode {
+	node := c.root()
+
+	seen := make(map[*rawNode]bool)
+	for node != nil && !seen[node] {
+		seen[node] = true
+		var next *rawNode
+		for i := len(node.Children) - 1; i >= 0 && next == nil; i-- {
+			next = c.Mapping[node.Children[i]]
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node
+}
+
+// root returns the node without a (known) parent. If there are several, which only
+// happens in damaged exports, roots with children are preferred and ties are broken
+// by node ID, so the result does not depend on map iteration order.
+func (c *rawConversation) root() *rawNode {
+	var rootID string
+	var root *rawNode
+	for id, candidate := range c.Mapping {
+		if candidate.Parent != nil && c.Mapping[*candidate.Parent] != nil {
+			continue
+		}
+		if root != nil {
+			hasChildren, rootHasChildren := len(candidate.Children) > 0, len(root.Children) > 0
+			if hasChildren != rootHasChildren && !hasChildren {
+				continue
+			}
+			if hasChildren == rootHasChildren && id > rootID {
+				continue
+			}
+		}
+		rootID, root = id, candidate
+	}
+	return root
+}
+
+// text joins the text parts of a message; images and other non-text parts are skipped
+func (m *rawMessage) text() string {
+	if m.Content.Text != "" {
+		return m.Content.Text
+	}
+
+	var parts []string
+	for _, part := range m.Content.Parts {
+		var s string
+		if err := json.Unmarshal(part, &s); err == nil && s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// unixTime converts fractional Unix seconds to time
+func unixTime(seconds *float64) time.Time {
+	if seconds == nil || *seconds <= 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(*seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
@@ -0,0 +1,130 @@
+package chatgptexport
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadFixture(t *testing.T) []json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile("testdata/conversations.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	return items
+}
+
+func roles(conversation *Conversation) string {
+	parts := make([]string, len(conversation.Messages))
+	for i, message := range conversation.Messages {
+		parts[i] = message.Role + ":" + message.Content
+	}
+	return strings.Join(parts, " | ")
+}
+
+func TestParseConversationFollowsCurrentBranch(t *testing.T) {
+	items := loadFixture(t)
+
+	conversation, err := ParseConversation(items[0])
+	if err != nil {
+		t.Fatalf("ParseConversation() error = %v", err)
+	}
+
+	if conversation.Title != "Trip to Lisbon" {
+		t.Errorf("Title = %q", conversation.Title)
+	}
+	if want := time.Unix(1700000000, 250000000).UTC(); !conversation.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", conversation.CreatedAt, want)
+	}
+	if conversation.Model != "gpt-4o" {
+		t.Errorf("Model = %q, want the model of the last assistant message", conversation.Model)
+	}
+
+	// The edited prompt branch, without the hidden system message, the tool
+	// output and the image part
+	want := "user:Plan 3 days in Lisbon around this hotel | " +
+		"assistant:Day 1: Baixa\nDay 2: Belém\nDay 3: Sintra | " +
+		"user:Thanks!"
+	if got := roles(conversation); got != want {
+		t.Errorf("messages = %q\nwant %q", got, want)
+	}
+	if want := time.Unix(1700000120, 750000000).UTC(); !conversation.Messages[1].CreatedAt.Equal(want) {
+		t.Errorf("message CreatedAt = %v, want %v", conversation.Messages[1].CreatedAt, want)
+	}
+}
+
+func TestParseConversationSurvivesDamagedMapping(t *testing.T) {
+	items := loadFixture(t)
+
+	// Null entries and a stray root are skipped instead of panicking, and the
+	// result is the same whatever the map iteration order
+	for i := 0; i < 20; i++ {
+		conversation, err := ParseConversation(items[1])
+		if err != nil {
+			t.Fatalf("ParseConversation() error = %v", err)
+		}
+		if got, want := roles(conversation), "user:What is 2+2? | assistant:4"; got != want {
+			t.Fatalf("messages = %q, want %q", got, want)
+		}
+		if conversation.Model != "text-davinci-002-render-sha" {
+			t.Fatalf("Model = %q", conversation.Model)
+		}
+	}
+}
+
+func TestParseConversationErrors(t *testing.T) {
+	items := loadFixture(t)
+
+	if _, err := ParseConversation(items[2]); err == nil || !strings.Contains(err.Error(), "no messages") {
+		t.Errorf("null mapping error = %v", err)
+	}
+	if _, err := ParseConversation(items[3]); err == nil || !strings.Contains(err.Error(), "no text messages") {
+		t.Errorf("image-only error = %v", err)
+	}
+	if _, err := ParseConversation(json.RawMessage(`{"mapping": {}}`)); err == nil {
+		t.Error("empty mapping should fail")
+	}
+	if _, err := ParseConversation(json.RawMessage(`{"mapping": [`)); err == nil {
+		t.Error("invalid JSON should fail")
+	}
+}
+
+func TestParseConversationFallsBackToLastChild(t *testing.T) {
+	data := json.RawMessage(`{
+		"title": "No current node",
+		"mapping": {
+			"root": {"message": null, "parent": null, "children": ["q"]},
+			"q": {"message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["Q"]}}, "parent": "root", "children": ["a1", "a2"]},
+			"a1": {"message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["first"]}}, "parent": "q", "children": []},
+			"a2": {"message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["regenerated"]}}, "parent": "q", "children": []}
+		}
+	}`)
+
+	conversation, err := ParseConversation(data)
+	if err != nil {
+		t.Fatalf("ParseConversation() error = %v", err)
+	}
+	if got, want := roles(conversation), "user:Q | assistant:regenerated"; got != want {
+		t.Fatalf("messages = %q, want %q", got, want)
+	}
+}
+
+func TestIsConversation(t *testing.T) {
+	items := loadFixture(t)
+	if !IsConversation(items[0]) {
+		t.Error("export conversation not recognized")
+	}
+
+	for _, data := range []string{`{"mapping": null}`, `{"mapping": []}`, `{"title": "x"}`, `[1]`, `"text"`} {
+		if IsConversation(json.RawMessage(data)) {
+			t.Errorf("IsConversation(%s) = true", data)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package denylist
+
+import "time"
+
+// Store keeps revoked access tokens until they would have expired anyway
+type Store interface {
+	// Revoke denies the token with the given ID (JWT "jti") for ttl
+	Revoke(tokenID string, ttl time.Duration) error
+	// RevokeSession denies all tokens of a login session for ttl
+	RevokeSession(sessionID string, ttl time.Duration) error
+	// RevokeUser denies all tokens of a user issued before the given time for ttl
+	RevokeUser(userID string, before time.Time, ttl time.Duration) error
+	// IsRevoked reports whether a token was revoked by its ID, its session or its user
+	IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error)
+	// Close releases the resources of the store
+	Close() error
+}
+
+// issuedBefore reports whether a token was issued before a user revocation.
+// Token issue times have second precision, so tokens issued within the same
+// second as the revocation stay valid rather than rejecting a fresh login.
+func issuedBefore(issuedAt time.Time, before int64) bool {
+	return issuedAt.Unix() < before
+}
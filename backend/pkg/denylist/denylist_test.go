@@ -0,0 +1,143 @@
+package denylist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	before := time.Unix(1000, 0).Unix()
+
+	tests := []struct {
+		issuedAt time.Time
+		want     bool
+	}{
+		{time.Unix(999, 0), true},
+		{time.Unix(999, 999_999_999), true},
+		// Issued within the second of the revocation: a login right after
+		// "sign out everywhere" must keep working
+		{time.Unix(1000, 0), false},
+		{time.Unix(1000, 999_999_999), false},
+		{time.Unix(1001, 0), false},
+	}
+	for _, tt := range tests {
+		if got := issuedBefore(tt.issuedAt, before); got != tt.want {
+			t.Errorf("issuedBefore(%v, %d) = %v, want %v", tt.issuedAt, before, got, tt.want)
+		}
+	}
+}
+
+// testStore checks the behaviour shared by every Store implementation
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	isRevoked := func(tokenID, sessionID, userID string, issuedAt time.Time) bool {
+		t.Helper()
+		revoked, err := store.IsRevoked(tokenID, sessionID, userID, issuedAt)
+		if err != nil {
+			t.Fatalf("IsRevoked() error = %v", err)
+		}
+		return revoked
+	}
+	now := time.Now()
+
+	if isRevoked("token-1", "session-1", "user-1", now) {
+		t.Fatal("token revoked before any revocation")
+	}
+
+	// Token
+	if err := store.Revoke("token-1", time.Minute); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if !isRevoked("token-1", "session-1", "user-1", now) {
+		t.Error("revoked token accepted")
+	}
+	if isRevoked("token-2", "session-1", "user-1", now) {
+		t.Error("other token of the session rejected")
+	}
+
+	// Session
+	if err := store.RevokeSession("session-2", time.Minute); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if !isRevoked("token-3", "session-2", "user-1", now) {
+		t.Error("token of a revoked session accepted")
+	}
+	if isRevoked("token-3", "session-3", "user-1", now) {
+		t.Error("token of another session rejected")
+	}
+
+	// User, with second precision
+	revokedAt := time.Unix(now.Unix(), 600_000_000)
+	if err := store.RevokeUser("user-2", revokedAt, time.Minute); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	if !isRevoked("token-4", "session-4", "user-2", revokedAt.Add(-time.Second)) {
+		t.Error("token issued before the user revocation accepted")
+	}
+	if isRevoked("token-4", "session-4", "user-2", time.Unix(revokedAt.Unix(), 0)) {
+		t.Error("token issued within the second of the user revocation rejected")
+	}
+	if isRevoked("token-4", "session-4", "user-2", revokedAt.Add(time.Second)) {
+		t.Error("token issued after the user revocation rejected")
+	}
+	if isRevoked("token-4", "session-4", "user-3", revokedAt.Add(-time.Second)) {
+		t.Error("token of another user rejected")
+	}
+
+	// A later revocation replaces the earlier one
+	if err := store.RevokeUser("user-2", revokedAt.Add(time.Hour), time.Minute); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	if !isRevoked("token-5", "session-5", "user-2", revokedAt.Add(time.Second)) {
+		t.Error("token issued before the later user revocation accepted")
+	}
+
+	// Non-positive TTLs are ignored: the token has already expired
+	if err := store.Revoke("token-6", 0); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := store.RevokeSession("session-6", -time.Second); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if isRevoked("token-6", "session-6", "user-6", now) {
+		t.Error("revocation with a non-positive TTL stored")
+	}
+
+	// Entries expire with their TTL
+	if err := store.Revoke("token-7", 50*time.Millisecond); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if !isRevoked("token-7", "session-7", "user-7", now) {
+		t.Error("revoked token accepted")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if isRevoked("token-7", "session-7", "user-7", now) {
+		t.Error("token still revoked after the TTL")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	testStore(t, store)
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	store := NewMemoryStore()
+
+	store.Revoke("expired", time.Millisecond)
+	store.RevokeSession("expired", time.Millisecond)
+	store.RevokeUser("expired", time.Now(), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// Pruning runs at most once per interval
+	store.lastPrune = time.Now().Add(-pruneInterval)
+	store.Revoke("live", time.Minute)
+
+	if len(store.tokens) != 1 || len(store.sessions) != 0 || len(store.users) != 0 {
+		t.Fatalf("after prune: %d tokens, %d sessions, %d users; want 1, 0, 0",
+			len(store.tokens), len(store.sessions), len(store.users))
+	}
+}
@@ -0,0 +1,129 @@
+package denylist
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often expired entries are removed from a MemoryStore
+const pruneInterval = time.Minute
+
+// userRevocation denies the tokens of a user issued before a time
+type userRevocation struct {
+	before    int64 // Unix seconds
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the denylist in process memory. Revocations are not shared
+// between instances and are lost on restart, so it suits single-node setups and tests.
+type MemoryStore struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time // Token ID -> expiry of the entry
+	sessions  map[string]time.Time // Session ID -> expiry of the entry
+	users     map[string]userRevocation
+	lastPrune time.Time
+}
+
+// NewMemoryStore creates a new in-memory denylist
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens:    make(map[string]time.Time),
+		sessions:  make(map[string]time.Time),
+		users:     make(map[string]userRevocation),
+		lastPrune: time.Now(),
+	}
+}
+
+// Revoke denies a token ID for ttl
+func (s *MemoryStore) Revoke(tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.tokens[tokenID] = time.Now().Add(ttl)
+	return nil
+}
+
+// RevokeSession denies the tokens of a login session for ttl
+func (s *MemoryStore) RevokeSession(sessionID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.sessions[sessionID] = time.Now().Add(ttl)
+	return nil
+}
+
+// RevokeUser denies the tokens of a user issued before the given time for ttl
+func (s *MemoryStore) RevokeUser(userID string, before time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.users[userID] = userRevocation{
+		before:    before.Unix(),
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token was revoked by its ID, its session or its user
+func (s *MemoryStore) IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := s.tokens[tokenID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if expiresAt, ok := s.sessions[sessionID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if revocation, ok := s.users[userID]; ok && now.Before(revocation.expiresAt) {
+		return issuedBefore(issuedAt, revocation.before), nil
+	}
+	return false, nil
+}
+
+// Close does nothing; the store holds no external resources
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// prune removes expired entries, at most once per pruneInterval.
+// The caller must hold the lock.
+func (s *MemoryStore) prune() {
+	now := time.Now()
+	if now.Sub(s.lastPrune) < pruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	for tokenID, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, tokenID)
+		}
+	}
+	for sessionID, expiresAt := range s.sessions {
+		if !now.Before(expiresAt) {
+			delete(s.sessions, sessionID)
+		}
+	}
+	for userID, revocation := range s.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(s.users, userID)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds a single denylist operation
+const redisTimeout = 2 * time.Second
+
+// Key prefixes of denylist entries
+const (
+	tokenKeyPrefix   = "denylist:token:"
+	sessionKeyPrefix = "denylist:session:"
+	userKeyPrefix    = "denylist:user:"
+)
+
+// RedisConfig holds the connection settings of a Redis denylist
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// RedisStore keeps the denylist in Redis, shared by all instances.
+// Entries expire with Redis key TTLs.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to Redis and creates a new denylist
+func NewRedisStore(cfg RedisConfig) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisStore{client: client}, nil
+}
+
+// Revoke denies a token ID for ttl
+func (s *RedisStore) Revoke(tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return s.client.Set(ctx, tokenKeyPrefix+tokenID, 1, ttl).Err()
+}
+
+// RevokeSession denies the tokens of a login session for ttl
+func (s *RedisStore) RevokeSession(sessionID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return s.client.Set(ctx, sessionKeyPrefix+sessionID, 1, ttl).Err()
+}
+
+// RevokeUser denies the tokens of a user issued before the given time for ttl
+func (s *RedisStore) RevokeUser(userID string, before time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return s.client.Set(ctx, userKeyPrefix+userID, before.Unix(), ttl).Err()
+}
+
+// IsRevoked reports whether a token was revoked by its ID, its session or its user
+func (s *RedisStore) IsRevoked(tokenID, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	values, err := s.client.MGet(ctx, tokenKeyPrefix+tokenID, sessionKeyPrefix+sessionID, userKeyPrefix+userID).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if values[0] != nil || values[1] != nil {
+		return true, nil
+	}
+	if value, ok := values[2].(string); ok {
+		before, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid user revocation for %s: %w", userID, err)
+		}
+		return issuedBefore(issuedAt, before), nil
+	}
+	return false, nil
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
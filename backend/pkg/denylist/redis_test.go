@@ -0,0 +1,204 @@
+package denylist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a minimal RESP2 server implementing the commands RedisStore uses
+type fakeRedis struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeRedis{
+		listener: listener,
+		values:   make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.execute(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "CLIENT", "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		if len(args) < 3 {
+			return "-ERR wrong number of arguments\r\n"
+		}
+		key := args[1]
+		s.values[key] = args[2]
+		delete(s.expires, key)
+		if len(args) == 5 {
+			amount, err := strconv.Atoi(args[4])
+			if err != nil {
+				return "-ERR value is not an integer\r\n"
+			}
+			switch strings.ToUpper(args[3]) {
+			case "EX":
+				s.expires[key] = time.Now().Add(time.Duration(amount) * time.Second)
+			case "PX":
+				s.expires[key] = time.Now().Add(time.Duration(amount) * time.Millisecond)
+			default:
+				return "-ERR syntax error\r\n"
+			}
+		}
+		return "+OK\r\n"
+	case "MGET":
+		reply := fmt.Sprintf("*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			if expiresAt, ok := s.expires[key]; ok && !time.Now().Before(expiresAt) {
+				delete(s.values, key)
+				delete(s.expires, key)
+			}
+			if value, ok := s.values[key]; ok {
+				reply += fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply += "$-1\r\n"
+			}
+		}
+		return reply
+	default:
+		// HELLO included: the client falls back to RESP2
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("invalid array length %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", header)
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:length])
+	}
+	return args, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	server := newFakeRedis(t)
+
+	store, err := NewRedisStore(RedisConfig{Addr: server.addr()})
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer store.Close()
+
+	testStore(t, store)
+
+	server.mu.Lock()
+	before, ok := server.values[userKeyPrefix+"user-2"]
+	server.mu.Unlock()
+	if !ok {
+		t.Fatal("user revocation not stored under its key")
+	}
+	if _, err := strconv.ParseInt(before, 10, 64); err != nil {
+		t.Errorf("user revocation = %q, want Unix seconds", before)
+	}
+}
+
+func TestRedisStoreInvalidUserRevocation(t *testing.T) {
+	server := newFakeRedis(t)
+
+	store, err := NewRedisStore(RedisConfig{Addr: server.addr()})
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	defer store.Close()
+
+	server.mu.Lock()
+	server.values[userKeyPrefix+"user-1"] = "not-a-time"
+	server.mu.Unlock()
+
+	if _, err := store.IsRevoked("token-1", "session-1", "user-1", time.Now()); err == nil {
+		t.Error("IsRevoked() accepted an invalid user revocation")
+	}
+}
+
+func TestNewRedisStoreUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	if _, err := NewRedisStore(RedisConfig{Addr: addr}); err == nil {
+		t.Error("NewRedisStore() connected to a closed port")
+	}
+}
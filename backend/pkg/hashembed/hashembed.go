@@ -0,0 +1,110 @@
+package hashembed
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// wordWeight is the weight of whole-word features
+	wordWeight = 1.0
+	// gramWeight is the weight of character trigram features (robust to word forms and typos)
+	gramWeight = 0.35
+)
+
+// stopWords are frequent English and Russian words that carry no meaning for retrieval
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"do": true, "does": true, "for": true, "from": true, "how": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true, "with": true,
+	"и": true, "в": true, "во": true, "не": true, "что": true, "на": true, "с": true, "со": true,
+	"как": true, "а": true, "то": true, "по": true, "но": true, "из": true, "у": true, "за": true,
+	"от": true, "о": true, "об": true, "же": true, "ли": true, "для": true, "это": true, "или": true,
+	"где": true, "когда": true, "какой": true, "какая": true, "какие": true,
+}
+
+// Embed returns an L2-normalized feature-hashing vector of the given dimensionality.
+// Features are words and character trigrams with sublinear term frequency weights,
+// so texts sharing words or word stems end up close in cosine similarity.
+func Embed(text string, dims int) []float32 {
+	if dims <= 0 {
+		dims = 512
+	}
+
+	words := make(map[string]int)
+	grams := make(map[string]int)
+	for _, word := range Tokenize(text) {
+		words[word]++
+		for _, gram := range trigrams(word) {
+			grams[gram]++
+		}
+	}
+
+	vec := make([]float64, dims)
+	add := func(feature string, count int, weight float64) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+
+		// Signed hashing keeps collisions unbiased
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1.0
+		}
+		vec[sum%uint64(dims)] += sign * weight * (1 + math.Log(float64(count)))
+	}
+	for word, count := range words {
+		add("w:"+word, count, wordWeight)
+	}
+	for gram, count := range grams {
+		add("c:"+gram, count, gramWeight)
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	result := make([]float32, dims)
+	if norm == 0 {
+		return result
+	}
+	for i, v := range vec {
+		result[i] = float32(v / norm)
+	}
+	return result
+}
+
+// Tokenize splits text into lowercase words, dropping stop words and single characters
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) < 2 || stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// trigrams returns character trigrams of a word padded with boundary markers
+func trigrams(word string) []string {
+	runes := []rune("<" + word + ">")
+	if len(runes) < 5 {
+		return nil
+	}
+
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
@@ -0,0 +1,90 @@
+package hashembed
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("What is the Capital of France? Это столица, a 2024 x")
+	want := []string{"capital", "france", "столица", "2024"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize() = %q, want %q", got, want)
+	}
+}
+
+func TestEmbedIsNormalizedAndDeterministic(t *testing.T) {
+	a := Embed("Refunds are issued within fourteen days", 256)
+	b := Embed("Refunds are issued within fourteen days", 256)
+	if len(a) != 256 {
+		t.Fatalf("len = %d, want 256", len(a))
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("Embed() is not deterministic")
+	}
+
+	var norm float64
+	for _, v := range a {
+		norm += float64(v) * float64(v)
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Fatalf("squared norm = %f, want 1", norm)
+	}
+}
+
+func TestEmbedDefaultsAndEmptyText(t *testing.T) {
+	if got := len(Embed("hello world", 0)); got != 512 {
+		t.Fatalf("default dims = %d, want 512", got)
+	}
+	for _, v := range Embed("the of и", 64) {
+		if v != 0 {
+			t.Fatal("text of stop words should embed to the zero vector")
+		}
+	}
+}
+
+func TestEmbedRanksRelevantPassagesFirst(t *testing.T) {
+	passages := []string{
+		"Our office is closed on public holidays and weekends.",
+		"Refunds are issued to the original payment method within fourteen days of the return.",
+		"The API rate limit is one hundred requests per minute per key.",
+		"Passwords must contain at least twelve characters.",
+	}
+	queries := map[string]int{
+		"how long does a refund take":            1,
+		"what is the rate limit for API keys":    2,
+		"minimum password length":                3,
+		"is the office open on holidays":         0,
+		"refunded payments":                      1, // word forms match through trigrams
+		"requests limit":                         2,
+		"when are you closed":                    0,
+		"how many characters in a password must": 3,
+	}
+
+	vectors := make([][]float32, len(passages))
+	for i, passage := range passages {
+		vectors[i] = Embed(passage, 512)
+	}
+
+	for query, want := range queries {
+		q := Embed(query, 512)
+		best, bestScore := -1, -2.0
+		for i, v := range vectors {
+			if score := cosine(q, v); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best != want {
+			t.Errorf("query %q matched passage %d, want %d", query, best, want)
+		}
+	}
+}
@@ -11,7 +11,9 @@ import (
 // Claims represents JWT claims
 type Claims struct {
 	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"session_id"` // Login session of an access token
 	Email     string    `json:"email"`
+	IsGuest   bool      `json:"is_guest,omitempty"`
 	TokenType string    `json:"token_type"` // "access" or "refresh"
 	jwt.RegisteredClaims
 }
@@ -32,11 +34,13 @@ func NewManager(secretKey string, accessExpiry, refreshExpiry time.Duration) *Ma
 	}
 }
 
-// GenerateAccessToken generates a new access token
-func (m *Manager) GenerateAccessToken(userID uuid.UUID, email string) (string, error) {
+// GenerateAccessToken generates a new access token for a login session
+func (m *Manager) GenerateAccessToken(userID, sessionID uuid.UUID, email string, isGuest bool) (string, error) {
 	claims := &Claims{
 		UserID:    userID,
+		SessionID: sessionID,
 		Email:     email,
+		IsGuest:   isGuest,
 		TokenType: "access",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessTokenExpiry)),
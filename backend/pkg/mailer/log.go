@@ -0,0 +1,23 @@
+package mailer
+
+import "log"
+
+// LogMailer writes emails to the application log instead of sending them.
+// It is meant for development and tests.
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer creates a new log mailer
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+// Send logs a message
+func (m *LogMailer) Send(msg Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+	log.Printf("Email from %s to %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
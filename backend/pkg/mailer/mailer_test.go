@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "Chatbot <noreply@example.com>")
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+
+	msg := Message{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Body:    "Hello,\n\nhttps://chat.example.com/reset-password?token=abc\n",
+	}
+	if err := m.Send(msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := m.Send(msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("wrote %d files, want one per email", len(files))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := string(data)
+	for _, want := range []string{
+		"From: Chatbot <noreply@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n",
+		"Hello,\r\n\r\nhttps://chat.example.com/reset-password?token=abc\r\n",
+	} {
+		if !strings.Contains(email, want) {
+			t.Errorf("email does not contain %q:\n%s", want, email)
+		}
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	m := NewLogMailer("noreply@example.com")
+	if err := m.Send(Message{To: "user@example.com", Subject: "Confirm", Body: "https://chat.example.com/verify-email?token=abc"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	logged := out.String()
+	for _, want := range []string{"to user@example.com", "Confirm", "https://chat.example.com/verify-email?token=abc"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log does not contain %q: %s", want, logged)
+		}
+	}
+}
+
+func TestHeaderInjection(t *testing.T) {
+	dir := t.TempDir()
+	fileMailer, err := NewFileMailer(dir, "noreply@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+
+	for _, m := range []Mailer{NewLogMailer("noreply@example.com"), fileMailer} {
+		for _, msg := range []Message{
+			{To: "user@example.com\r\nBcc: victim@example.com", Subject: "Hi"},
+			{To: "user@example.com", Subject: "Hi\nBcc: victim@example.com"},
+		} {
+			if err := m.Send(msg); err == nil {
+				t.Errorf("%T sent a message with an injected header: %+v", m, msg)
+			}
+		}
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("rejected emails were written: %d files", len(files))
+	}
+}
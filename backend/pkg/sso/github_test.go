@@ -0,0 +1,140 @@
+package sso
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// mockGitHub serves the GitHub OAuth token endpoint and the user API
+type mockGitHub struct {
+	server        *httptest.Server
+	codeChallenge string
+	emails        []map[string]interface{}
+}
+
+func newMockGitHub(t *testing.T) *mockGitHub {
+	t.Helper()
+
+	m := &mockGitHub{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		digest := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "github-code" || base64.RawURLEncoding.EncodeToString(digest[:]) != m.codeChallenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad_verification_code"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"gho_token","token_type":"bearer","scope":"read:user,user:email"}`))
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":583231,"login":"octocat","name":"The Octocat"}`))
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(m.emails)
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	endpoint, apiURL := githubEndpoint, githubAPIURL
+	githubEndpoint = oauth2.Endpoint{
+		AuthURL:  m.server.URL + "/login/oauth/authorize",
+		TokenURL: m.server.URL + "/login/oauth/access_token",
+	}
+	githubAPIURL = m.server.URL
+	t.Cleanup(func() { githubEndpoint, githubAPIURL = endpoint, apiURL })
+	return m
+}
+
+func TestGitHubProviderLogin(t *testing.T) {
+	github := newMockGitHub(t)
+	github.emails = []map[string]interface{}{
+		{"email": "octo@users.noreply.github.com", "primary": false, "verified": true},
+		{"email": "octocat@example.com", "primary": true, "verified": true},
+	}
+
+	provider, err := NewGitHubProvider(Config{ClientID: "github-client", ClientSecret: "secret", RedirectURL: testRedirectURL})
+	if err != nil {
+		t.Fatalf("NewGitHubProvider() error = %v", err)
+	}
+	ctx := context.Background()
+	verifier := GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if query.Get("state") != "state-1" || query.Get("code_challenge_method") != "S256" || query.Get("scope") != "read:user user:email" {
+		t.Errorf("consent URL = %s", authURL)
+	}
+	if query.Get("nonce") != "" {
+		t.Error("consent URL carries a nonce GitHub does not use")
+	}
+	github.codeChallenge = query.Get("code_challenge")
+
+	if _, err := provider.Exchange(ctx, "github-code", "", GenerateVerifier()); err == nil {
+		t.Error("Exchange() accepted a wrong PKCE verifier")
+	}
+
+	identity, err := provider.Exchange(ctx, "github-code", "", verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	want := Identity{Subject: "583231", Email: "octocat@example.com", EmailVerified: true, Name: "The Octocat", Username: "octocat"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestGitHubProviderUnverifiedPrimaryEmail(t *testing.T) {
+	github := newMockGitHub(t)
+	github.emails = []map[string]interface{}{
+		{"email": "octocat@example.com", "primary": true, "verified": false},
+		{"email": "verified@example.com", "primary": false, "verified": true},
+	}
+
+	provider, err := NewGitHubProvider(Config{ClientID: "github-client"})
+	if err != nil {
+		t.Fatalf("NewGitHubProvider() error = %v", err)
+	}
+	verifier := GenerateVerifier()
+	authURL, _ := provider.AuthCodeURL(context.Background(), "state", "", verifier)
+	parsed, _ := url.Parse(authURL)
+	github.codeChallenge = parsed.Query().Get("code_challenge")
+
+	identity, err := provider.Exchange(context.Background(), "github-code", "", verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	// Only the primary email is used, and it is not verified
+	if identity.Email != "octocat@example.com" || identity.EmailVerified {
+		t.Errorf("identity = %+v, want the unverified primary email", *identity)
+	}
+}
+
+func TestNewGitHubProviderValidation(t *testing.T) {
+	if _, err := NewGitHubProvider(Config{}); err == nil || !strings.Contains(err.Error(), "client ID") {
+		t.Errorf("NewGitHubProvider() error = %v", err)
+	}
+}
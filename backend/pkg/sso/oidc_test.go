@@ -0,0 +1,399 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	testClientID     = "chatbot"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "https://chat.example.com/api/v1/auth/oidc/test/callback"
+)
+
+// mockIssuer is an OpenID Connect issuer that redeems one authorization code
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu            sync.Mutex
+	code          string
+	codeChallenge string
+	claims        map[string]interface{}
+	signingKey    *rsa.PrivateKey // Signs ID tokens; defaults to the published key
+	discoveries   int
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &mockIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/keys", issuer.keys)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (m *mockIssuer) url() string {
+	return m.server.URL
+}
+
+// authorize plays the user consenting on the issuer's page: it records the PKCE
+// challenge of the consent URL, the claims of the ID token to issue and returns a code
+func (m *mockIssuer) authorize(authURL string, claims map[string]interface{}) string {
+	m.t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("invalid consent URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		m.t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.code = "code-" + query.Get("state")
+	m.codeChallenge = query.Get("code_challenge")
+	m.claims = claims
+	return m.code
+}
+
+func (m *mockIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.discoveries++
+	m.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                m.url(),
+		"authorization_endpoint":                m.url() + "/authorize",
+		"token_endpoint":                        m.url() + "/token",
+		"jwks_uri":                              m.url() + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockIssuer) keys(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &m.key.PublicKey, KeyID: "test-key", Algorithm: "RS256", Use: "sig"},
+	}})
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clientID, clientSecret, _ := r.BasicAuth()
+	if clientID == "" {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != testClientID || clientSecret != testClientSecret {
+		writeTokenError(w, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != m.code || m.code == "" {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+	if r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+	// PKCE: the verifier must hash to the challenge of the consent URL
+	digest := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(digest[:]) != m.codeChallenge {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+	m.code = "" // Codes are single use
+
+	claims := map[string]interface{}{
+		"iss": m.url(),
+		"aud": testClientID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for name, value := range m.claims {
+		claims[name] = value
+	}
+
+	signingKey := m.key
+	if m.signingKey != nil {
+		signingKey = m.signingKey
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: signingKey},
+		(&jose.SignerOptions{}).WithHeader("kid", "test-key"))
+	if err != nil {
+		m.t.Errorf("failed to create signer: %v", err)
+		return
+	}
+	payload, _ := json.Marshal(claims)
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		m.t.Errorf("failed to sign ID token: %v", err)
+		return
+	}
+	idToken, _ := signed.CompactSerialize()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeTokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func newTestOIDCProvider(t *testing.T, issuer *mockIssuer) *OIDCProvider {
+	t.Helper()
+	provider, err := NewOIDCProvider(issuer.url(), Config{
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() error = %v", err)
+	}
+	return provider
+}
+
+func TestOIDCProviderLogin(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := newTestOIDCProvider(t, issuer)
+	ctx := context.Background()
+	verifier := GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if !strings.HasPrefix(authURL, issuer.url()+"/authorize?") {
+		t.Errorf("consent URL = %s", authURL)
+	}
+	for name, want := range map[string]string{
+		"state":         "state-1",
+		"nonce":         "nonce-1",
+		"client_id":     testClientID,
+		"redirect_uri":  testRedirectURL,
+		"response_type": "code",
+		"scope":         "openid email profile",
+	} {
+		if got := query.Get(name); got != want {
+			t.Errorf("consent URL %s = %q, want %q", name, got, want)
+		}
+	}
+	if query.Get("code_challenge") == "" || strings.Contains(authURL, verifier) {
+		t.Error("consent URL must carry the PKCE challenge, not the verifier")
+	}
+
+	code := issuer.authorize(authURL, map[string]interface{}{
+		"sub":                "user-1",
+		"nonce":              "nonce-1",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"name":               "Alice",
+		"preferred_username": "alice",
+	})
+	identity, err := provider.Exchange(ctx, code, "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	want := Identity{Subject: "user-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice", Username: "alice"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+
+	// The discovery document is fetched once
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+	if issuer.discoveries != 1 {
+		t.Errorf("discovery fetched %d times, want 1", issuer.discoveries)
+	}
+}
+
+func TestOIDCProviderExchangeRejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		nonce    string
+		verifier func(verifier string) string
+		issuer   func(issuer *mockIssuer)
+		want     string
+	}{
+		{
+			name:   "nonce mismatch",
+			claims: map[string]interface{}{"sub": "user-1", "nonce": "other-nonce"},
+			want:   "nonce",
+		},
+		{
+			name:   "missing nonce",
+			claims: map[string]interface{}{"sub": "user-1"},
+			want:   "nonce",
+		},
+		{
+			name:     "wrong PKCE verifier",
+			claims:   map[string]interface{}{"sub": "user-1", "nonce": "nonce-1"},
+			verifier: func(string) string { return GenerateVerifier() },
+			want:     "failed to exchange",
+		},
+		{
+			name:   "wrong audience",
+			claims: map[string]interface{}{"sub": "user-1", "nonce": "nonce-1", "aud": "another-client"},
+			want:   "invalid ID token",
+		},
+		{
+			name:   "expired",
+			claims: map[string]interface{}{"sub": "user-1", "nonce": "nonce-1", "exp": time.Now().Add(-time.Hour).Unix()},
+			want:   "invalid ID token",
+		},
+		{
+			name:   "unknown signing key",
+			claims: map[string]interface{}{"sub": "user-1", "nonce": "nonce-1"},
+			issuer: func(issuer *mockIssuer) { issuer.signingKey = otherKey },
+			want:   "invalid ID token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			if tt.issuer != nil {
+				tt.issuer(issuer)
+			}
+			provider := newTestOIDCProvider(t, issuer)
+			ctx := context.Background()
+			verifier := GenerateVerifier()
+
+			authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL() error = %v", err)
+			}
+			code := issuer.authorize(authURL, tt.claims)
+			if tt.verifier != nil {
+				verifier = tt.verifier(verifier)
+			}
+
+			_, err = provider.Exchange(ctx, code, "nonce-1", verifier)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Exchange() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderCodeIsSingleUse(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := newTestOIDCProvider(t, issuer)
+	ctx := context.Background()
+	verifier := GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code := issuer.authorize(authURL, map[string]interface{}{"sub": "user-1", "nonce": "nonce-1"})
+
+	if _, err := provider.Exchange(ctx, code, "nonce-1", verifier); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, "nonce-1", verifier); err == nil {
+		t.Error("Exchange() redeemed a code twice")
+	}
+}
+
+func TestOIDCProviderUnverifiedEmail(t *testing.T) {
+	for _, claims := range []map[string]interface{}{
+		{"sub": "user-1", "nonce": "nonce-1", "email": "alice@example.com"},
+		{"sub": "user-1", "nonce": "nonce-1", "email": "alice@example.com", "email_verified": false},
+	} {
+		issuer := newMockIssuer(t)
+		provider := newTestOIDCProvider(t, issuer)
+		ctx := context.Background()
+		verifier := GenerateVerifier()
+
+		authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+		if err != nil {
+			t.Fatalf("AuthCodeURL() error = %v", err)
+		}
+		identity, err := provider.Exchange(ctx, issuer.authorize(authURL, claims), "nonce-1", verifier)
+		if err != nil {
+			t.Fatalf("Exchange() error = %v", err)
+		}
+		if identity.EmailVerified {
+			t.Errorf("email verified with claims %v", claims)
+		}
+	}
+}
+
+func TestOIDCProviderDiscoveryFailureIsRetried(t *testing.T) {
+	issuer := newMockIssuer(t)
+	var down atomic.Bool
+	down.Store(true)
+	handler := issuer.server.Config.Handler
+	issuer.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	provider := newTestOIDCProvider(t, issuer)
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", GenerateVerifier()); err == nil {
+		t.Fatal("AuthCodeURL() succeeded with the issuer down")
+	}
+	down.Store(false)
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", GenerateVerifier()); err != nil {
+		t.Fatalf("AuthCodeURL() error after the issuer recovered = %v", err)
+	}
+}
+
+func TestNewOIDCProviderValidation(t *testing.T) {
+	if _, err := NewOIDCProvider("", Config{ClientID: testClientID}); err == nil {
+		t.Error("NewOIDCProvider() accepted an empty issuer")
+	}
+	if _, err := NewOIDCProvider("https://issuer.example.com", Config{}); err == nil {
+		t.Error("NewOIDCProvider() accepted an empty client ID")
+	}
+}
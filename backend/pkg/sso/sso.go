@@ -0,0 +1,48 @@
+package sso
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// Identity is a user identity asserted by a provider
+type Identity struct {
+	Subject       string // Stable user ID at the provider
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string // Preferred username, if the provider has one
+}
+
+// Config holds the OAuth2 client settings of a provider
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider signs users in with the authorization code flow and PKCE
+type Provider interface {
+	// AuthCodeURL returns the URL of the provider's consent page
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Exchange redeems an authorization code and returns the signed-in identity
+	Exchange(ctx context.Context, code, nonce, verifier string) (*Identity, error)
+}
+
+// GenerateVerifier generates a PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// oauth2Config returns the oauth2 client configuration for an endpoint
+func (c Config) oauth2Config(endpoint oauth2.Endpoint) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+		Endpoint:     endpoint,
+	}
+}
@@ -0,0 +1,49 @@
+package tabular
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the table as a Markdown table with at most maxRows rows
+func (t *Table) Markdown(maxRows int) string {
+	var b strings.Builder
+
+	b.WriteString("|")
+	for _, column := range t.Columns {
+		b.WriteString(" " + escapeCell(column.Name) + " |")
+	}
+	b.WriteString("\n|")
+	for _, column := range t.Columns {
+		if column.Type == Number {
+			b.WriteString(" ---: |")
+		} else {
+			b.WriteString(" --- |")
+		}
+	}
+	b.WriteString("\n")
+
+	rows := t.Rows
+	if maxRows > 0 && len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+	for _, row := range rows {
+		b.WriteString("|")
+		for _, value := range row {
+			b.WriteString(" " + escapeCell(value.String()) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(rows) < len(t.Rows) {
+		fmt.Fprintf(&b, "\n_Showing %d of %d rows._\n", len(rows), len(t.Rows))
+	}
+	return b.String()
+}
+
+// escapeCell makes a value safe to place in a Markdown table cell
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", " ")
+}
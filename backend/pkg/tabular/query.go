@@ -0,0 +1,444 @@
+package tabular
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed query: an optional source table followed by a pipeline of stages.
+//
+// Grammar (keywords are case-insensitive, stages are separated by "|"):
+//
+//	[from <table>] | <stage> | <stage> ...
+//	where <column> <op> <value> [and <column> <op> <value> ...]   (op: = != < <= > >= contains)
+//	group by <column>, ... [aggregate <agg>, ...]
+//	aggregate <agg>, ... [by <column>, ...]                        (agg: count() | count|sum|avg|min|max(<column>) [as <name>])
+//	select <column>, ...
+//	sort <column> [asc|desc], ...
+//	limit <n>
+//
+// Column names containing spaces or punctuation are quoted with backticks,
+// text values with single or double quotes.
+type Query struct {
+	Table  string // Source table, empty if not specified
+	stages []stage
+}
+
+// stage is a single pipeline step
+type stage interface {
+	apply(t *Table) (*Table, error)
+}
+
+// tokenKind is the kind of a query token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// ParseQuery parses a query string
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.parseQuery()
+}
+
+// Execute runs the query pipeline against a table
+func (q *Query) Execute(t *Table) (*Table, error) {
+	result := t
+	for _, s := range q.stages {
+		var err error
+		if result, err = s.apply(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// lex splits a query into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '`' || r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote %c", r)
+			}
+			kind := tokString
+			if r == '`' {
+				kind = tokQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[i+1 : end])})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.' || runes[end] == 'e' || runes[end] == 'E') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[i:end])})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[i:end])})
+			i = end
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); two == "!=" || two == "<=" || two == ">=" || two == "<>" || two == "==" {
+					symbol = two
+				}
+			}
+			if !strings.Contains("|,()=!<>*", string(r)) {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokSymbol, text: symbol})
+			i += len([]rune(symbol))
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// parser is a recursive descent parser over query tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// isKeyword reports whether the next token is the given unquoted keyword
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.errorf("expected %q", keyword)
+	}
+	return nil
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	tok := p.peek()
+	if tok.kind == tokSymbol && tok.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.errorf("expected %q", symbol)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	tok := p.peek()
+	near := tok.text
+	if tok.kind == tokEOF {
+		near = "end of query"
+	}
+	return fmt.Errorf("%s near %q", fmt.Sprintf(format, args...), near)
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	query := &Query{}
+
+	if p.acceptKeyword("from") {
+		tok := p.peek()
+		if tok.kind != tokIdent && tok.kind != tokQuotedIdent && tok.kind != tokString {
+			return nil, p.errorf("expected table name")
+		}
+		p.pos++
+		query.Table = tok.text
+		if p.peek().kind != tokEOF {
+			if err := p.expectSymbol("|"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for p.peek().kind != tokEOF {
+		s, err := p.parseStage()
+		if err != nil {
+			return nil, err
+		}
+		query.stages = append(query.stages, s)
+
+		if p.peek().kind != tokEOF {
+			if err := p.expectSymbol("|"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return query, nil
+}
+
+func (p *parser) parseStage() (stage, error) {
+	switch {
+	case p.acceptKeyword("where"), p.acceptKeyword("filter"):
+		return p.parseWhere()
+	case p.acceptKeyword("group"):
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		by, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		aggs := []aggregate{{fn: "count", name: "count"}}
+		if p.acceptKeyword("aggregate") {
+			if aggs, err = p.parseAggregates(); err != nil {
+				return nil, err
+			}
+		}
+		return &aggregateStage{aggs: aggs, by: by}, nil
+	case p.acceptKeyword("aggregate"):
+		aggs, err := p.parseAggregates()
+		if err != nil {
+			return nil, err
+		}
+		var by []string
+		if p.acceptKeyword("by") {
+			if by, err = p.parseColumnList(); err != nil {
+				return nil, err
+			}
+		}
+		return &aggregateStage{aggs: aggs, by: by}, nil
+	case p.acceptKeyword("select"):
+		columns, err := p.parseColumnList()
+		if err != nil {
+			return nil, err
+		}
+		return &selectStage{columns: columns}, nil
+	case p.acceptKeyword("sort"), p.acceptKeyword("order"):
+		p.acceptKeyword("by")
+		return p.parseSort()
+	case p.acceptKeyword("limit"):
+		tok := p.peek()
+		n, err := strconv.Atoi(tok.text)
+		if tok.kind != tokNumber || err != nil || n < 0 {
+			return nil, p.errorf("expected row count")
+		}
+		p.pos++
+		return &limitStage{n: n}, nil
+	default:
+		return nil, p.errorf("expected where, group, aggregate, select, sort or limit")
+	}
+}
+
+func (p *parser) parseWhere() (stage, error) {
+	s := &whereStage{}
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+
+		var op string
+		if p.acceptKeyword("contains") {
+			op = "contains"
+		} else {
+			tok := p.peek()
+			switch {
+			case tok.kind != tokSymbol:
+				return nil, p.errorf("expected comparison operator")
+			case tok.text == "=" || tok.text == "==":
+				op = "="
+			case tok.text == "!=" || tok.text == "<>":
+				op = "!="
+			case tok.text == "<" || tok.text == "<=" || tok.text == ">" || tok.text == ">=":
+				op = tok.text
+			default:
+				return nil, p.errorf("expected comparison operator")
+			}
+			p.pos++
+		}
+
+		tok := p.peek()
+		var value Value
+		switch tok.kind {
+		case tokNumber:
+			n, ok := parseNumber(tok.text)
+			if !ok {
+				return nil, fmt.Errorf("invalid number %q", tok.text)
+			}
+			value = Value{Text: tok.text, Num: n, IsNum: true}
+		case tokString, tokIdent:
+			value = Value{Text: tok.text}
+		default:
+			return nil, p.errorf("expected value")
+		}
+		p.pos++
+
+		s.conditions = append(s.conditions, condition{column: column, op: op, value: value})
+		if !p.acceptKeyword("and") {
+			return s, nil
+		}
+	}
+}
+
+func (p *parser) parseSort() (stage, error) {
+	s := &sortStage{}
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+		key := sortKey{column: column}
+		if p.acceptKeyword("desc") {
+			key.desc = true
+		} else {
+			p.acceptKeyword("asc")
+		}
+		s.keys = append(s.keys, key)
+
+		if !p.acceptSymbol(",") {
+			return s, nil
+		}
+	}
+}
+
+func (p *parser) parseAggregates() ([]aggregate, error) {
+	var aggs []aggregate
+	for {
+		tok := p.peek()
+		fn := strings.ToLower(tok.text)
+		if tok.kind != tokIdent || !isAggregateFunc(fn) {
+			return nil, p.errorf("expected count, sum, avg, min or max")
+		}
+		p.pos++
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		agg := aggregate{fn: fn, name: fn}
+		if !p.acceptSymbol(")") {
+			if !p.acceptSymbol("*") {
+				column, err := p.parseColumnName()
+				if err != nil {
+					return nil, err
+				}
+				agg.column = column
+				agg.name = fmt.Sprintf("%s(%s)", fn, column)
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+		}
+		if agg.column == "" && fn != "count" {
+			return nil, fmt.Errorf("%s requires a column", fn)
+		}
+
+		if p.acceptKeyword("as") {
+			name, err := p.parseColumnName()
+			if err != nil {
+				return nil, err
+			}
+			agg.name = name
+		}
+		aggs = append(aggs, agg)
+
+		if !p.acceptSymbol(",") {
+			return aggs, nil
+		}
+	}
+}
+
+func (p *parser) parseColumnList() ([]string, error) {
+	var columns []string
+	for {
+		column, err := p.parseColumn()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+
+		if !p.acceptSymbol(",") {
+			return columns, nil
+		}
+	}
+}
+
+// parseColumn parses a column reference, including aggregate result names such as sum(amount)
+func (p *parser) parseColumn() (string, error) {
+	tok := p.peek()
+	if tok.kind == tokIdent && isAggregateFunc(strings.ToLower(tok.text)) && p.tokens[p.pos+1].text == "(" {
+		p.pos += 2
+		fn := strings.ToLower(tok.text)
+		if p.acceptSymbol(")") || (p.acceptSymbol("*") && p.acceptSymbol(")")) {
+			return fn, nil
+		}
+		column, err := p.parseColumnName()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", fn, column), nil
+	}
+	return p.parseColumnName()
+}
+
+func (p *parser) parseColumnName() (string, error) {
+	tok := p.peek()
+	if tok.kind != tokIdent && tok.kind != tokQuotedIdent {
+		return "", p.errorf("expected column name")
+	}
+	p.pos++
+	return tok.text, nil
+}
+
+// isAggregateFunc reports whether name is a supported aggregate function
+func isAggregateFunc(name string) bool {
+	switch name {
+	case "count", "sum", "avg", "min", "max":
+		return true
+	default:
+		return false
+	}
+}
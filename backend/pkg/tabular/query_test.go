@@ -0,0 +1,164 @@
+package tabular
+
+import (
+	"strings"
+	"testing"
+)
+
+// rows renders the rows of a table as "a,b;c,d" for compact comparisons
+func rows(t *Table) string {
+	lines := make([]string, len(t.Rows))
+	for i, row := range t.Rows {
+		cells := make([]string, len(row))
+		for j, value := range row {
+			cells[j] = value.String()
+		}
+		lines[i] = strings.Join(cells, ",")
+	}
+	return strings.Join(lines, ";")
+}
+
+func run(t *testing.T, table *Table, query string) *Table {
+	t.Helper()
+	parsed, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) error = %v", query, err)
+	}
+	result, err := parsed.Execute(table)
+	if err != nil {
+		t.Fatalf("Execute(%q) error = %v", query, err)
+	}
+	return result
+}
+
+func TestQueries(t *testing.T) {
+	table := mustParse(t, "sales.csv", salesCSV)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"where region = 'eu'", "EU,Widget,100.5,3;EU,Gadget,50,"},
+		{"where amount >= 50 and amount < 200", "EU,Widget,100.5,3;EU,Gadget,50,"},
+		{"where region != EU | select product", "Widget;Gadget"},
+		{"where product contains 'ADG'", "EU,Gadget,50,;us,Gadget,25.25,1"},
+		{"where units > 0 | select units", "3;5;1"},
+		{"where units = ''", "EU,Gadget,50,"},
+		{"group by region", "EU,2;US,2"},
+		{"group by product aggregate sum(amount) as total, avg(units), max(amount) | sort total desc",
+			"Widget,300.5,4,200;Gadget,75.25,1,50"},
+		{"aggregate count(), count(units), sum(units), min(amount)", "4,3,9,25.25"},
+		{"aggregate sum(amount) by region | sort `sum(amount)` desc", "US,225.25;EU,150.5"},
+		{"group by region aggregate count(*) | sort count() desc, region | limit 1", "EU,2"},
+		{"sort units desc | select product, units", "Widget,5;Widget,3;Gadget,1;Gadget,"},
+		{"order by product, amount desc | limit 2 | select amount", "50;25.25"},
+		{"where amount > 1000 | aggregate count(), avg(amount)", "0,"},
+		{"where amount > 1000 | group by region", ""},
+		{"limit 0", ""},
+	}
+	for _, tt := range tests {
+		if got := rows(run(t, table, tt.query)); got != tt.want {
+			t.Errorf("%s\n got %q\nwant %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryResultColumns(t *testing.T) {
+	table := mustParse(t, "sales.csv", salesCSV)
+	result := run(t, table, "group by Region aggregate sum(amount), count() as n")
+
+	names := make([]string, len(result.Columns))
+	for i, column := range result.Columns {
+		names[i] = column.Name
+	}
+	if got := strings.Join(names, ","); got != "region,sum(amount),n" {
+		t.Errorf("columns = %s", got)
+	}
+	if result.Columns[1].Type != Number {
+		t.Error("aggregate columns should be numeric")
+	}
+}
+
+func TestParseQueryTable(t *testing.T) {
+	tests := map[string]string{
+		"from `sales.csv` | limit 1": "sales.csv",
+		"FROM 'my data.tsv'":         "my data.tsv",
+		"from sales.csv":             "sales.csv",
+		"limit 1":                    "",
+	}
+	for input, want := range tests {
+		query, err := ParseQuery(input)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) error = %v", input, err)
+			continue
+		}
+		if query.Table != want {
+			t.Errorf("ParseQuery(%q).Table = %q, want %q", input, query.Table, want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := map[string]string{
+		"delete from sales":              "expected where, group, aggregate",
+		"where amount ~ 3":               "unexpected character",
+		"where amount 3":                 "expected comparison operator",
+		"where amount >":                 "expected value",
+		"where region = 'EU":             "unterminated quote",
+		"group region":                   `expected "by"`,
+		"aggregate median(amount)":       "expected count, sum, avg, min or max",
+		"aggregate sum()":                "sum requires a column",
+		"aggregate sum(amount":           `expected ")"`,
+		"limit -1":                       "expected row count",
+		"limit ten":                      "expected row count",
+		"select region product":          `expected "|"`,
+		"from":                           "expected table name",
+		"select":                         "expected column name near \"end of query\"",
+		"where amount = 1e999":           "invalid number",
+		"sort amount desc | limit 1 2 3": `expected "|"`,
+	}
+	for input, want := range tests {
+		_, err := ParseQuery(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseQuery(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	table := mustParse(t, "sales.csv", salesCSV)
+
+	tests := map[string]string{
+		"where price > 3":                       "unknown column \"price\"",
+		"aggregate sum(product)":                "sum requires a numeric column, \"product\" is text",
+		"group by product | select sum(amount)": "unknown column \"sum(amount)\"",
+		"sort missing":                          "unknown column",
+	}
+	for input, want := range tests {
+		query, err := ParseQuery(input)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) error = %v", input, err)
+		}
+		if _, err := query.Execute(table); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Execute(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestExecuteDoesNotModifySource(t *testing.T) {
+	table := mustParse(t, "sales.csv", salesCSV)
+	before := rows(table)
+
+	run(t, table, "sort amount | where region = EU | select amount | limit 1")
+	if after := rows(table); after != before {
+		t.Fatalf("source table changed:\n%s\n%s", before, after)
+	}
+}
+
+func TestCompareNumbersTolerance(t *testing.T) {
+	table := mustParse(t, "n.csv", "x\n0.3\n")
+	// 0.1 + 0.2 is stored as 0.30000000000000004
+	if got := rows(run(t, table, "where x = 0.30000000000000004")); got != "0.3" {
+		t.Fatalf("tolerant comparison = %q, want 0.3", got)
+	}
+}
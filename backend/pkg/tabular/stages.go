@@ -0,0 +1,314 @@
+package tabular
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// condition is a single comparison of a where stage
+type condition struct {
+	column string
+	op     string
+	value  Value
+}
+
+// whereStage keeps rows matching all conditions
+type whereStage struct {
+	conditions []condition
+}
+
+func (s *whereStage) apply(t *Table) (*Table, error) {
+	indexes := make([]int, len(s.conditions))
+	for i, cond := range s.conditions {
+		index, err := t.columnIndex(cond.column)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+	}
+
+	result := &Table{Name: t.Name, Columns: t.Columns}
+	for _, row := range t.Rows {
+		matches := true
+		for i, cond := range s.conditions {
+			if !cond.matches(row[indexes[i]]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	return result, nil
+}
+
+// matches compares a cell with the condition value.
+// Numbers are compared numerically, everything else as case-insensitive text.
+func (c condition) matches(cell Value) bool {
+	if c.op == "contains" {
+		return strings.Contains(strings.ToLower(cell.String()), strings.ToLower(c.value.Text))
+	}
+
+	var cmp int
+	if cell.IsNum && c.value.IsNum {
+		cmp = compareNumbers(cell.Num, c.value.Num)
+	} else {
+		if cell.IsEmpty() && c.op != "=" && c.op != "!=" {
+			return false
+		}
+		cmp = strings.Compare(strings.ToLower(cell.String()), strings.ToLower(c.value.Text))
+	}
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// aggregate is an aggregate function applied to a column
+type aggregate struct {
+	fn     string // count, sum, avg, min or max
+	column string // Empty for count()
+	name   string // Result column name
+}
+
+// aggregateStage groups rows and computes aggregates per group
+type aggregateStage struct {
+	aggs []aggregate
+	by   []string
+}
+
+// accumulator holds the running state of an aggregate within a group
+type accumulator struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func (s *aggregateStage) apply(t *Table) (*Table, error) {
+	result := &Table{Name: t.Name}
+
+	byIndexes := make([]int, len(s.by))
+	for i, column := range s.by {
+		index, err := t.columnIndex(column)
+		if err != nil {
+			return nil, err
+		}
+		byIndexes[i] = index
+		result.Columns = append(result.Columns, t.Columns[index])
+	}
+
+	aggIndexes := make([]int, len(s.aggs))
+	for i, agg := range s.aggs {
+		aggIndexes[i] = -1
+		if agg.column != "" {
+			index, err := t.columnIndex(agg.column)
+			if err != nil {
+				return nil, err
+			}
+			if agg.fn != "count" && t.Columns[index].Type != Number {
+				return nil, fmt.Errorf("%s requires a numeric column, %q is text", agg.fn, t.Columns[index].Name)
+			}
+			aggIndexes[i] = index
+		}
+		result.Columns = append(result.Columns, Column{Name: agg.name, Type: Number})
+	}
+
+	// Groups keep the order in which they first appear
+	type group struct {
+		key  []Value
+		accs []accumulator
+	}
+	var groups []*group
+	groupsByKey := make(map[string]*group)
+
+	for _, row := range t.Rows {
+		keyParts := make([]string, len(byIndexes))
+		for i, index := range byIndexes {
+			keyParts[i] = strings.ToLower(row[index].String())
+		}
+		key := strings.Join(keyParts, "\x00")
+
+		g, ok := groupsByKey[key]
+		if !ok {
+			g = &group{accs: make([]accumulator, len(s.aggs))}
+			for _, index := range byIndexes {
+				g.key = append(g.key, row[index])
+			}
+			groupsByKey[key] = g
+			groups = append(groups, g)
+		}
+
+		for i, index := range aggIndexes {
+			acc := &g.accs[i]
+			if index < 0 {
+				acc.count++
+				continue
+			}
+			cell := row[index]
+			if cell.IsEmpty() {
+				continue
+			}
+			if cell.IsNum {
+				if acc.count == 0 || cell.Num < acc.min {
+					acc.min = cell.Num
+				}
+				if acc.count == 0 || cell.Num > acc.max {
+					acc.max = cell.Num
+				}
+				acc.sum += cell.Num
+			}
+			acc.count++
+		}
+	}
+
+	// Aggregating without grouping always yields a single row
+	if len(groups) == 0 && len(byIndexes) == 0 {
+		groups = append(groups, &group{accs: make([]accumulator, len(s.aggs))})
+	}
+
+	for _, g := range groups {
+		row := append([]Value{}, g.key...)
+		for i, agg := range s.aggs {
+			row = append(row, g.accs[i].result(agg.fn))
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// result returns the final value of an aggregate
+func (a accumulator) result(fn string) Value {
+	switch fn {
+	case "count":
+		return NumberValue(float64(a.count))
+	case "sum":
+		return NumberValue(a.sum)
+	}
+
+	if a.count == 0 {
+		return Value{}
+	}
+	switch fn {
+	case "avg":
+		return NumberValue(a.sum / float64(a.count))
+	case "min":
+		return NumberValue(a.min)
+	default:
+		return NumberValue(a.max)
+	}
+}
+
+// selectStage keeps the listed columns in the given order
+type selectStage struct {
+	columns []string
+}
+
+func (s *selectStage) apply(t *Table) (*Table, error) {
+	indexes := make([]int, len(s.columns))
+	result := &Table{Name: t.Name, Columns: make([]Column, len(s.columns))}
+	for i, column := range s.columns {
+		index, err := t.columnIndex(column)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+		result.Columns[i] = t.Columns[index]
+	}
+
+	result.Rows = make([][]Value, len(t.Rows))
+	for r, row := range t.Rows {
+		projected := make([]Value, len(indexes))
+		for i, index := range indexes {
+			projected[i] = row[index]
+		}
+		result.Rows[r] = projected
+	}
+	return result, nil
+}
+
+// sortKey is a single sort column
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// sortStage orders rows by one or more columns; missing values go last
+type sortStage struct {
+	keys []sortKey
+}
+
+func (s *sortStage) apply(t *Table) (*Table, error) {
+	indexes := make([]int, len(s.keys))
+	for i, key := range s.keys {
+		index, err := t.columnIndex(key.column)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+	}
+
+	rows := append([][]Value{}, t.Rows...)
+	sort.SliceStable(rows, func(a, b int) bool {
+		for i, key := range s.keys {
+			va, vb := rows[a][indexes[i]], rows[b][indexes[i]]
+			if va.IsEmpty() != vb.IsEmpty() {
+				return vb.IsEmpty()
+			}
+
+			var cmp int
+			if va.IsNum && vb.IsNum {
+				cmp = compareNumbers(va.Num, vb.Num)
+			} else {
+				cmp = strings.Compare(strings.ToLower(va.String()), strings.ToLower(vb.String()))
+			}
+			if cmp != 0 {
+				return (cmp < 0) != key.desc
+			}
+		}
+		return false
+	})
+
+	return &Table{Name: t.Name, Columns: t.Columns, Rows: rows}, nil
+}
+
+// limitStage keeps the first n rows
+type limitStage struct {
+	n int
+}
+
+func (s *limitStage) apply(t *Table) (*Table, error) {
+	rows := t.Rows
+	if len(rows) > s.n {
+		rows = rows[:s.n]
+	}
+	return &Table{Name: t.Name, Columns: t.Columns, Rows: rows}, nil
+}
+
+// compareNumbers compares two numbers with a small tolerance for float rounding
+func compareNumbers(a, b float64) int {
+	switch {
+	case math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b))):
+		return 0
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,240 @@
+// Package tabular implements an in-memory table engine for CSV/TSV data
+// with a restricted query language (filter, group, aggregate, sort).
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ColumnType is the inferred type of a column
+type ColumnType int
+
+const (
+	// Text columns are compared as case-insensitive strings
+	Text ColumnType = iota
+	// Number columns hold numeric values (empty cells are treated as missing)
+	Number
+)
+
+// String returns the type name
+func (t ColumnType) String() string {
+	if t == Number {
+		return "number"
+	}
+	return "text"
+}
+
+// Column describes a table column
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Value is a table cell
+type Value struct {
+	Text  string  // Original cell text (empty for missing or computed values)
+	Num   float64 // Numeric value, valid when IsNum is set
+	IsNum bool
+}
+
+// NumberValue creates a computed numeric value
+func NumberValue(n float64) Value {
+	return Value{Num: n, IsNum: true}
+}
+
+// IsEmpty reports whether the value is missing
+func (v Value) IsEmpty() bool {
+	return !v.IsNum && v.Text == ""
+}
+
+// String returns the display form of the value
+func (v Value) String() string {
+	if v.Text != "" || !v.IsNum {
+		return v.Text
+	}
+	return formatNumber(v.Num)
+}
+
+// Table is an in-memory table
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]Value
+}
+
+// Parse loads a CSV or TSV file into a table.
+// The first record is the header; the delimiter is detected from the file
+// extension or the header line (comma, semicolon or tab).
+func Parse(name string, data []byte, maxRows int) (*Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(name, data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("table is empty")
+		}
+		return nil, fmt.Errorf("failed to parse header: %w", err)
+	}
+
+	table := &Table{Name: name, Columns: make([]Column, len(header))}
+	seen := make(map[string]bool)
+	for i, columnName := range header {
+		columnName = strings.TrimSpace(columnName)
+		if columnName == "" || seen[strings.ToLower(columnName)] {
+			columnName = fmt.Sprintf("column_%d", i+1)
+		}
+		seen[strings.ToLower(columnName)] = true
+		table.Columns[i] = Column{Name: columnName, Type: Text}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse row %d: %w", len(table.Rows)+2, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if maxRows > 0 && len(table.Rows) >= maxRows {
+			return nil, fmt.Errorf("table exceeds %d rows", maxRows)
+		}
+
+		row := make([]Value, len(table.Columns))
+		for i := range row {
+			if i < len(record) {
+				row[i] = Value{Text: strings.TrimSpace(record[i])}
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	table.inferTypes()
+	return table, nil
+}
+
+// RowCount returns the number of rows
+func (t *Table) RowCount() int {
+	return len(t.Rows)
+}
+
+// Describe returns a schema summary with the first sample rows as Markdown
+func (t *Table) Describe(sampleRows int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Table `%s` (%d rows). Columns: ", t.Name, len(t.Rows))
+	for i, column := range t.Columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "`%s` (%s)", column.Name, column.Type)
+	}
+	b.WriteString("\n")
+
+	if sampleRows > 0 && len(t.Rows) > 0 {
+		b.WriteString("\n")
+		b.WriteString(t.Markdown(sampleRows))
+	}
+	return b.String()
+}
+
+// columnIndex resolves a column name (exact match first, then case-insensitive)
+func (t *Table) columnIndex(name string) (int, error) {
+	for i, column := range t.Columns {
+		if column.Name == name {
+			return i, nil
+		}
+	}
+	for i, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q", name)
+}
+
+// inferTypes marks columns whose non-empty cells are all numeric as numbers
+func (t *Table) inferTypes() {
+	for col := range t.Columns {
+		numeric, hasValues := true, false
+		for _, row := range t.Rows {
+			if row[col].Text == "" {
+				continue
+			}
+			hasValues = true
+			if _, ok := parseNumber(row[col].Text); !ok {
+				numeric = false
+				break
+			}
+		}
+		if !numeric || !hasValues {
+			continue
+		}
+
+		t.Columns[col].Type = Number
+		for _, row := range t.Rows {
+			if n, ok := parseNumber(row[col].Text); ok {
+				row[col].Num = n
+				row[col].IsNum = true
+			}
+		}
+	}
+}
+
+// detectDelimiter picks the field delimiter of a CSV/TSV file
+func detectDelimiter(name string, data []byte) rune {
+	if strings.EqualFold(filepath.Ext(name), ".tsv") {
+		return '\t'
+	}
+
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+
+	delimiter, best := ',', bytes.Count(line, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if count := bytes.Count(line, []byte(string(candidate))); count > best {
+			delimiter, best = candidate, count
+		}
+	}
+	return delimiter
+}
+
+// isBlankRecord reports whether all fields of a record are empty
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNumber parses a finite decimal number
+func parseNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// formatNumber formats a number without exponent, rounded to 4 decimals
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*1e4)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,122 @@
+package tabular
+
+import (
+	"strings"
+	"testing"
+)
+
+const salesCSV = "\xef\xbb\xbfregion,product,amount,units\n" +
+	"EU,Widget,100.5,3\n" +
+	"US,Widget,200,5\n" +
+	"EU,Gadget,50,\n" +
+	",,,\n" +
+	"us,Gadget,25.25,1\n"
+
+func mustParse(t *testing.T, name, data string) *Table {
+	t.Helper()
+	table, err := Parse(name, []byte(data), 0)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return table
+}
+
+func TestParseInfersTypesAndSkipsBlankRows(t *testing.T) {
+	table := mustParse(t, "sales.csv", salesCSV)
+
+	if table.RowCount() != 4 {
+		t.Fatalf("RowCount() = %d, want 4", table.RowCount())
+	}
+	want := []Column{{"region", Text}, {"product", Text}, {"amount", Number}, {"units", Number}}
+	for i, column := range want {
+		if table.Columns[i] != column {
+			t.Errorf("column %d = %+v, want %+v", i, table.Columns[i], column)
+		}
+	}
+
+	if v := table.Rows[0][2]; !v.IsNum || v.Num != 100.5 {
+		t.Errorf("amount = %+v, want 100.5", v)
+	}
+	if v := table.Rows[2][3]; !v.IsEmpty() {
+		t.Errorf("missing units = %+v, want empty", v)
+	}
+}
+
+func TestParseDelimitersAndHeaders(t *testing.T) {
+	tests := []struct {
+		name, file, data string
+		columns          []string
+	}{
+		{"semicolon", "data.csv", "a;b;c\n1;2;3\n", []string{"a", "b", "c"}},
+		{"tab by extension", "data.tsv", "a,b\tc\n1,2\t3\n", []string{"a,b", "c"}},
+		{"tab by header", "data.txt", "a\tb\n1\t2\n", []string{"a", "b"}},
+		{"empty and duplicate names", "data.csv", "a,,A\n1,2,3\n", []string{"a", "column_2", "column_3"}},
+	}
+	for _, tt := range tests {
+		table := mustParse(t, tt.file, tt.data)
+		if len(table.Columns) != len(tt.columns) {
+			t.Errorf("%s: %d columns, want %d", tt.name, len(table.Columns), len(tt.columns))
+			continue
+		}
+		for i, name := range tt.columns {
+			if table.Columns[i].Name != name {
+				t.Errorf("%s: column %d = %q, want %q", tt.name, i, table.Columns[i].Name, name)
+			}
+		}
+	}
+}
+
+func TestParseShortAndLongRecords(t *testing.T) {
+	table := mustParse(t, "data.csv", "a,b\n1\n2,3,4\n")
+	if len(table.Rows[0]) != 2 || !table.Rows[0][1].IsEmpty() {
+		t.Errorf("short record = %+v, want padded with an empty cell", table.Rows[0])
+	}
+	if len(table.Rows[1]) != 2 {
+		t.Errorf("long record = %+v, want truncated to the header", table.Rows[1])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("empty.csv", nil, 0); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("empty file error = %v", err)
+	}
+	if _, err := Parse("big.csv", []byte("a\n1\n2\n3\n"), 2); err == nil || !strings.Contains(err.Error(), "exceeds 2 rows") {
+		t.Errorf("row limit error = %v", err)
+	}
+}
+
+func TestValueString(t *testing.T) {
+	tests := []struct {
+		value Value
+		want  string
+	}{
+		{Value{Text: "1.50", Num: 1.5, IsNum: true}, "1.50"},
+		{NumberValue(2.0 / 3), "0.6667"},
+		{NumberValue(-0.00001), "-0"},
+		{Value{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestDescribeAndMarkdown(t *testing.T) {
+	table := mustParse(t, "sales.csv", "name,note\nA|B,\"two\nlines\"\nC,x\n")
+
+	description := table.Describe(1)
+	if !strings.HasPrefix(description, "Table `sales.csv` (2 rows). Columns: `name` (text), `note` (text)\n") {
+		t.Errorf("Describe() = %q", description)
+	}
+
+	want := "| name | note |\n| --- | --- |\n| A\\|B | two lines |\n\n_Showing 1 of 2 rows._\n"
+	if got := table.Markdown(1); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+
+	numbers := mustParse(t, "n.csv", "n\n1\n")
+	if got := numbers.Markdown(0); got != "| n |\n| ---: |\n| 1 |\n" {
+		t.Errorf("Markdown() of a number column = %q", got)
+	}
+}
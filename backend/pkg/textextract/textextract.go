@@ -0,0 +1,86 @@
+package textextract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// MimeTypePlain is the MIME type of plain text documents
+	MimeTypePlain = "text/plain"
+	// MimeTypeMarkdown is the MIME type of Markdown documents
+	MimeTypeMarkdown = "text/markdown"
+	// MimeTypePDF is the MIME type of PDF documents
+	MimeTypePDF = "application/pdf"
+)
+
+// ErrUnsupportedType is returned for documents that cannot be converted to text
+var ErrUnsupportedType = errors.New("unsupported document type")
+
+// DetectMimeType determines a supported document MIME type from the file name and content
+func DetectMimeType(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown":
+		return MimeTypeMarkdown
+	case ".txt", ".text":
+		return MimeTypePlain
+	case ".pdf":
+		return MimeTypePDF
+	}
+
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return MimeTypePDF
+	}
+	if utf8.Valid(data) {
+		return MimeTypePlain
+	}
+	return ""
+}
+
+// Extract converts document content of the given MIME type to plain text
+func Extract(mimeType string, data []byte) (string, error) {
+	switch mimeType {
+	case MimeTypePlain, MimeTypeMarkdown:
+		if !utf8.Valid(data) {
+			return "", errors.New("document is not valid UTF-8 text")
+		}
+		return string(data), nil
+	case MimeTypePDF:
+		return extractPDF(data)
+	default:
+		return "", ErrUnsupportedType
+	}
+}
+
+// extractPDF extracts plain text from a PDF document
+func extractPDF(data []byte) (text string, err error) {
+	// The PDF reader panics on some malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF text: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(plain); err != nil {
+		return "", fmt.Errorf("failed to read PDF text: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,53 @@
+package textextract
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+		want     string
+	}{
+		{"notes.md", "# Title", MimeTypeMarkdown},
+		{"README.Markdown", "text", MimeTypeMarkdown},
+		{"notes.TXT", "text", MimeTypePlain},
+		{"report.pdf", "anything", MimeTypePDF},
+		{"report", "%PDF-1.7\n", MimeTypePDF},
+		{"notes", "Привет, мир", MimeTypePlain},
+		{"image.png", "\x89PNG\r\n\x1a\n\xff\xfe", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectMimeType(tt.filename, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectMimeType(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	got, err := Extract(MimeTypeMarkdown, []byte("# Refunds\n\nWithin 14 days."))
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got != "# Refunds\n\nWithin 14 days." {
+		t.Fatalf("Extract() = %q", got)
+	}
+
+	if _, err := Extract(MimeTypePlain, []byte{0xff, 0xfe, 0xfd}); err == nil {
+		t.Fatal("Extract() accepted invalid UTF-8")
+	}
+}
+
+func TestExtractUnsupportedType(t *testing.T) {
+	if _, err := Extract("", []byte("data")); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("Extract() error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestExtractBrokenPDF(t *testing.T) {
+	if _, err := Extract(MimeTypePDF, []byte("%PDF-1.7\nnot really a pdf")); err == nil {
+		t.Fatal("Extract() accepted a broken PDF")
+	}
+}
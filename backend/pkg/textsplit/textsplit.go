@@ -0,0 +1,97 @@
+package textsplit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Split splits text into chunks of at most size characters with the given overlap.
+// Chunk boundaries prefer paragraph breaks, then sentence ends, then whitespace.
+func Split(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = 1000
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	runes := []rune(normalize(text))
+	var chunks []string
+
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else {
+			end = boundary(runes, start, end)
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		if end == len(runes) {
+			break
+		}
+
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		// Don't start the next chunk in the middle of a word
+		for next < end && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// boundary finds the best place to end a chunk in runes[start:end]
+func boundary(runes []rune, start, end int) int {
+	// Only look for a boundary in the second half of the chunk
+	min := start + (end-start)/2
+
+	for i := end; i > min; i-- {
+		if runes[i-1] == '\n' && i >= 2 && runes[i-2] == '\n' {
+			return i
+		}
+	}
+	for i := end; i > min; i-- {
+		if (runes[i-1] == '.' || runes[i-1] == '!' || runes[i-1] == '?') && unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	for i := end; i > min; i-- {
+		if unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return end
+}
+
+// normalize unifies line endings and collapses runs of blank lines
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	var b strings.Builder
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return strings.TrimSpace(b.String())
+}
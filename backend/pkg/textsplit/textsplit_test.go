@@ -0,0 +1,86 @@
+package textsplit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitShortTextIsOneChunk(t *testing.T) {
+	got := Split("  Refunds are issued within fourteen days.  ", 100, 10)
+	want := []string{"Refunds are issued within fourteen days."}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Split() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitEmptyText(t *testing.T) {
+	if got := Split(" \n\r\n\t ", 100, 10); len(got) != 0 {
+		t.Fatalf("Split() = %q, want no chunks", got)
+	}
+}
+
+func TestSplitPrefersParagraphBreaks(t *testing.T) {
+	text := "First paragraph is here. It has two sentences.\r\n\r\n\r\nSecond paragraph follows."
+	got := Split(text, 60, 0)
+	want := []string{
+		"First paragraph is here. It has two sentences.",
+		"Second paragraph follows.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Split() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitPrefersSentenceEnds(t *testing.T) {
+	got := Split("One two three four. Five six seven eight nine ten", 30, 0)
+	want := []string{"One two three four.", "Five six seven eight nine ten"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Split() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitRespectsSizeAndOverlap(t *testing.T) {
+	words := make([]string, 200)
+	for i := range words {
+		words[i] = "слово"
+	}
+	text := strings.Join(words, " ")
+
+	chunks := Split(text, 100, 20)
+	if len(chunks) < 2 {
+		t.Fatalf("Split() returned %d chunks, want several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 100 {
+			t.Fatalf("chunk %d has %d characters, want at most 100", i, n)
+		}
+		// Chunks never start or end in the middle of a word
+		for _, word := range strings.Fields(chunk) {
+			if word != "слово" {
+				t.Fatalf("chunk %d contains a split word %q", i, word)
+			}
+		}
+	}
+
+	// Neighbouring chunks overlap, so together they hold more words than the text
+	total := 0
+	for _, chunk := range chunks {
+		total += len(strings.Fields(chunk))
+	}
+	if total <= len(words) {
+		t.Fatalf("chunks hold %d words, want more than %d with overlap", total, len(words))
+	}
+}
+
+func TestSplitInvalidOverlapIsIgnored(t *testing.T) {
+	text := strings.Repeat("word ", 50)
+	withoutOverlap := Split(text, 40, 0)
+	if got := Split(text, 40, 40); !reflect.DeepEqual(got, withoutOverlap) {
+		t.Fatalf("Split() with overlap >= size = %q, want %q", got, withoutOverlap)
+	}
+	if got := Split(text, 40, -5); !reflect.DeepEqual(got, withoutOverlap) {
+		t.Fatalf("Split() with negative overlap = %q, want %q", got, withoutOverlap)
+	}
+}
@@ -0,0 +1,132 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 test key of RFC 6238 appendix B, "12345678901234567890", base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfcVectors are the SHA-1 test vectors of RFC 6238 appendix B, truncated to 6 digits
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		got, err := Generate(rfcSecret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if got != v.code {
+			t.Errorf("Generate(T=%d) = %s, want %s", v.unix, got, v.code)
+		}
+	}
+
+	if _, err := Generate("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("Generate() accepted an invalid secret")
+	}
+}
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		at := time.Unix(v.unix, 0)
+		step, ok := Validate(rfcSecret, v.code, at, 0)
+		if !ok || step != Step(at) {
+			t.Errorf("Validate(T=%d, %s) = %d, %v; want step %d", v.unix, v.code, step, ok, Step(at))
+		}
+	}
+
+	// Secrets are accepted in lower case and codes with surrounding spaces
+	if _, ok := Validate(strings.ToLower(rfcSecret), " 287082 ", time.Unix(59, 0), 0); !ok {
+		t.Error("Validate() rejected a lower case secret or a padded code")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	code := "081804"
+
+	// One step later the code is accepted with a skew of one and reports its own step
+	later := at.Add(Period * time.Second)
+	step, ok := Validate(rfcSecret, code, later, 1)
+	if !ok || step != Step(at) {
+		t.Fatalf("Validate() one step later = %d, %v; want step %d", step, ok, Step(at))
+	}
+	if _, ok := Validate(rfcSecret, code, later, 0); ok {
+		t.Error("Validate() accepted a code of the previous step without skew")
+	}
+	if _, ok := Validate(rfcSecret, code, at.Add(2*Period*time.Second), 1); ok {
+		t.Error("Validate() accepted a code two steps old with a skew of one")
+	}
+	if _, ok := Validate(rfcSecret, code, at.Add(-Period*time.Second), 1); !ok {
+		t.Error("Validate() rejected a code one step ahead with a skew of one")
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	at := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870822", "94287082", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, at, 1); ok {
+			t.Errorf("Validate(%q) accepted a malformed code", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "287082", at, 1); ok {
+		t.Error("Validate() accepted an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	b, _ := GenerateSecret()
+	if a == b {
+		t.Fatal("GenerateSecret() returned the same secret twice")
+	}
+
+	key, err := encoding.DecodeString(a)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("GenerateSecret() = %q, want a base32 encoded 160-bit key", a)
+	}
+
+	// A generated secret works with the current code
+	now := time.Now()
+	code, err := Generate(a, now)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, ok := Validate(a, code, now, 0); !ok {
+		t.Fatal("Validate() rejected the current code of a generated secret")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("LLM Chatbot", "user@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("ProvisioningURI() is not a URL: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/LLM Chatbot:user@example.com" {
+		t.Fatalf("ProvisioningURI() = %s", uri)
+	}
+
+	query := uri.Query()
+	want := map[string]string{"secret": rfcSecret, "issuer": "LLM Chatbot", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("ProvisioningURI() %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 
 	"golang.org/x/crypto/bcrypt"
@@ -35,3 +36,9 @@ func GenerateRandomString(length int) string {
 	}
 	return hex.EncodeToString(bytes)[:length]
 }
+
+// HashToken returns the hex-encoded SHA-256 of a token for storage and lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
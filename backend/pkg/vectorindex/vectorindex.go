@@ -0,0 +1,196 @@
+package vectorindex
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Result is a vector matching a search query
+type Result struct {
+	ID    uuid.UUID
+	Group uuid.UUID
+	Score float64
+}
+
+// entry is a vector stored in the index
+type entry struct {
+	Group  uuid.UUID
+	Vector []float32
+}
+
+// FlatIndex is a thread-safe in-memory vector index with exact (brute-force) cosine search.
+// Vectors belong to a group (e.g. a document), which can be removed at once.
+type FlatIndex struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID]entry
+}
+
+// New creates an empty flat index
+func New() *FlatIndex {
+	return &FlatIndex{
+		entries: make(map[uuid.UUID]entry),
+	}
+}
+
+// Add adds or replaces a vector
+func (ix *FlatIndex) Add(id, group uuid.UUID, vector []float32) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.entries[id] = entry{Group: group, Vector: vector}
+}
+
+// RemoveGroup removes all vectors of a group and returns the number of vectors removed
+func (ix *FlatIndex) RemoveGroup(group uuid.UUID) int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	removed := 0
+	for id, e := range ix.entries {
+		if e.Group == group {
+			delete(ix.entries, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Groups returns the distinct groups with vectors in the index
+func (ix *FlatIndex) Groups() []uuid.UUID {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	seen := make(map[uuid.UUID]bool)
+	groups := make([]uuid.UUID, 0)
+	for _, e := range ix.entries {
+		if !seen[e.Group] {
+			seen[e.Group] = true
+			groups = append(groups, e.Group)
+		}
+	}
+	return groups
+}
+
+// Len returns the number of vectors in the index
+func (ix *FlatIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	return len(ix.entries)
+}
+
+// Search returns up to k vectors most similar to the query with a score of at least minScore.
+// If groups is not nil, only vectors of the given groups are considered.
+func (ix *FlatIndex) Search(query []float32, k int, minScore float64, groups map[uuid.UUID]bool) []Result {
+	if k <= 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	h := &resultHeap{}
+	for id, e := range ix.entries {
+		if groups != nil && !groups[e.Group] {
+			continue
+		}
+
+		score := Cosine(query, e.Vector)
+		if score < minScore {
+			continue
+		}
+
+		if h.Len() < k {
+			heap.Push(h, Result{ID: id, Group: e.Group, Score: score})
+		} else if score > (*h)[0].Score {
+			(*h)[0] = Result{ID: id, Group: e.Group, Score: score}
+			heap.Fix(h, 0)
+		}
+	}
+
+	// Pop from the min-heap to get results ordered by descending score
+	results := make([]Result, h.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(h).(Result)
+	}
+	return results
+}
+
+// Save writes the index to a file atomically
+func (ix *FlatIndex) Save(path string) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(ix.entries); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads an index previously written with Save
+func Load(path string) (*FlatIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ix := New()
+	if err := gob.NewDecoder(file).Decode(&ix.entries); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+	return ix, nil
+}
+
+// Cosine computes cosine similarity between two vectors (0 for vectors of different length)
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// resultHeap is a min-heap of results by score
+type resultHeap []Result
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(Result)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,176 @@
+package vectorindex
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchOrdersByScore(t *testing.T) {
+	ix := New()
+	group := uuid.New()
+	best, middle, worst := uuid.New(), uuid.New(), uuid.New()
+	ix.Add(worst, group, []float32{0, 1})
+	ix.Add(best, group, []float32{1, 0})
+	ix.Add(middle, group, []float32{1, 1})
+
+	results := ix.Search([]float32{1, 0}, 2, -1, nil)
+	if len(results) != 2 {
+		t.Fatalf("len = %d, want 2", len(results))
+	}
+	if results[0].ID != best || results[1].ID != middle {
+		t.Fatalf("results = %v, want best then middle", results)
+	}
+	if math.Abs(results[1].Score-1/math.Sqrt2) > 1e-9 {
+		t.Fatalf("score = %f, want %f", results[1].Score, 1/math.Sqrt2)
+	}
+}
+
+func TestSearchFiltersByScoreAndGroup(t *testing.T) {
+	ix := New()
+	a, b := uuid.New(), uuid.New()
+	inA, inB, orthogonal := uuid.New(), uuid.New(), uuid.New()
+	ix.Add(inA, a, []float32{1, 0})
+	ix.Add(inB, b, []float32{1, 0.1})
+	ix.Add(orthogonal, a, []float32{0, 1})
+
+	results := ix.Search([]float32{1, 0}, 10, 0.5, nil)
+	if len(results) != 2 {
+		t.Fatalf("minScore: len = %d, want 2", len(results))
+	}
+
+	results = ix.Search([]float32{1, 0}, 10, -1, map[uuid.UUID]bool{b: true})
+	if len(results) != 1 || results[0].ID != inB || results[0].Group != b {
+		t.Fatalf("groups: results = %v, want only the vector of group b", results)
+	}
+
+	if results := ix.Search([]float32{1, 0}, 0, -1, nil); results != nil {
+		t.Fatalf("k = 0: results = %v, want nil", results)
+	}
+}
+
+func TestAddReplacesAndRemoveGroup(t *testing.T) {
+	ix := New()
+	a, b := uuid.New(), uuid.New()
+	id := uuid.New()
+	ix.Add(id, a, []float32{1, 0})
+	ix.Add(id, b, []float32{0, 1})
+	ix.Add(uuid.New(), b, []float32{1, 1})
+	ix.Add(uuid.New(), a, []float32{1, 1})
+
+	if ix.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", ix.Len())
+	}
+
+	groups := ix.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("Groups() = %v, want 2 groups", groups)
+	}
+
+	if removed := ix.RemoveGroup(b); removed != 2 {
+		t.Fatalf("RemoveGroup() = %d, want 2", removed)
+	}
+	if removed := ix.RemoveGroup(b); removed != 0 {
+		t.Fatalf("second RemoveGroup() = %d, want 0", removed)
+	}
+	if ix.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", ix.Len())
+	}
+	if groups := ix.Groups(); len(groups) != 1 || groups[0] != a {
+		t.Fatalf("Groups() = %v, want [%s]", groups, a)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	ix := New()
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	group := uuid.New()
+	for i, id := range ids {
+		ix.Add(id, group, []float32{float32(i), 1, 2})
+	}
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// Saving again replaces the file
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("second Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Len() != len(ids) {
+		t.Fatalf("Len() = %d, want %d", loaded.Len(), len(ids))
+	}
+
+	want := ix.Search([]float32{1, 1, 1}, 3, -1, nil)
+	got := loaded.Search([]float32{1, 1, 1}, 3, -1, nil)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("result %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Fatalf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.gob")); err == nil {
+		t.Fatal("Load() of a missing file should fail")
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2}, []float32{2, 4}, 1},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"different length", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Cosine() = %f, want %f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMatchesBruteForce(t *testing.T) {
+	ix := New()
+	vectors := make(map[uuid.UUID][]float32)
+	for i := 0; i < 200; i++ {
+		v := []float32{float32(i % 7), float32(i % 11), float32(i % 13), 1}
+		id := uuid.New()
+		vectors[id] = v
+		ix.Add(id, uuid.Nil, v)
+	}
+
+	query := []float32{3, 1, 4, 1}
+	scores := make([]float64, 0, len(vectors))
+	for _, v := range vectors {
+		scores = append(scores, Cosine(query, v))
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+
+	results := ix.Search(query, 10, -1, nil)
+	for i, r := range results {
+		if math.Abs(r.Score-scores[i]) > 1e-12 {
+			t.Fatalf("result %d score = %f, want %f", i, r.Score, scores[i])
+		}
+	}
+}
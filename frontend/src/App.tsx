@@ -6,6 +6,9 @@ import { selectIsGuest } from './stores/selectors/auth.selectors';
 import { ErrorBoundary, Header, ErrorModal } from './components/common';
 import { LoginForm } from './components/auth/LoginForm';
 import { RegisterForm } from './components/auth/RegisterForm';
+import { OIDCCallback } from './components/auth/OIDCCallback';
+import { ResetPasswordForm } from './components/auth/ResetPasswordForm';
+import { VerifyEmail } from './components/auth/VerifyEmail';
 import { WelcomePage } from './pages/WelcomePage';
 import { ChatPage } from './pages/ChatPage';
 import { PrivateRoute } from './components/auth/PrivateRoute';
@@ -44,6 +47,9 @@ function InnerApp() {
         <Route path="/" element={<WelcomePage />} />
         <Route path="/login" element={<LoginForm />} />
         <Route path="/register" element={<RegisterForm />} />
+        <Route path="/oidc/callback" element={<OIDCCallback />} />
+        <Route path="/reset-password" element={<ResetPasswordForm />} />
+        <Route path="/verify-email" element={<VerifyEmail />} />
         <Route
           path="/chat"
           element={
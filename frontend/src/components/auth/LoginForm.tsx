@@ -1,7 +1,10 @@
 import { useState, FormEvent, useEffect } from 'react';
-import { useNavigate } from 'react-router-dom';
+import { useNavigate, useSearchParams, useLocation } from 'react-router-dom';
 import { useAuth } from '@/hooks/useAuth';
 import { useTranslation } from 'react-i18next';
+import { authApi } from '@/services/api/authApi';
+import type { OIDCProvider, MFAChallenge } from '@/types/auth.types';
+import { MFAChallengeForm } from './MFAChallengeForm';
 import styles from './LoginForm.module.scss';
 
 /**
@@ -11,17 +14,37 @@ export const LoginForm = () => {
   const navigate = useNavigate();
   const { login, loading, error, isAuthenticated } = useAuth();
   const { t } = useTranslation();
+  const [searchParams] = useSearchParams();
+  const location = useLocation();
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
+  const [providers, setProviders] = useState<OIDCProvider[]>([]);
+  const [verificationRequired, setVerificationRequired] = useState(false);
+  // Second sign-in step, also passed on from single sign-on and registration
+  const [mfa, setMFA] = useState<MFAChallenge | null>(
+    (location.state as { mfa?: MFAChallenge } | null)?.mfa ?? null
+  );
+  const ssoError = searchParams.get('sso_error');
 
   /**
-   * Redirect if already authenticated
+   * Redirect if already authenticated. During the second sign-in step
+   * the step itself redirects, after showing new recovery codes.
    */
   useEffect(() => {
-    if (isAuthenticated) {
+    if (isAuthenticated && !mfa) {
       navigate('/chat');
     }
-  }, [isAuthenticated, navigate]);
+  }, [isAuthenticated, mfa, navigate]);
+
+  /**
+   * Load single sign-on providers
+   */
+  useEffect(() => {
+    authApi
+      .getOIDCProviders()
+      .then(setProviders)
+      .catch(() => setProviders([]));
+  }, []);
 
   /**
    * Handle form submission
@@ -31,14 +54,35 @@ export const LoginForm = () => {
     const result = await login({ email, password });
     if (result.success) {
       navigate('/chat');
+    } else if (result.mfa) {
+      setMFA(result.mfa);
+    } else {
+      setVerificationRequired(result.verificationRequired ?? false);
     }
   };
 
+  if (mfa) {
+    return (
+      <div className={styles['auth-container']}>
+        <div className={styles['auth-form']}>
+          <MFAChallengeForm challenge={mfa} onComplete={() => navigate('/chat')} />
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className={styles['auth-container']}>
       <div className={styles['auth-form']}>
         <h2>{t('auth.loginTitle')}</h2>
-        {error && <div className="error-message">{error}</div>}
+        {(error || ssoError) && <div className="error-message">{error || ssoError}</div>}
+        {verificationRequired && (
+          <p className={styles['auth-link']}>
+            <a href={`/verify-email?email=${encodeURIComponent(email)}`}>
+              {t('auth.resendVerification')}
+            </a>
+          </p>
+        )}
         <form onSubmit={handleSubmit}>
           <div className="form-group">
             <label htmlFor="email">{t('auth.email')}</label>
@@ -66,6 +110,23 @@ export const LoginForm = () => {
             {loading ? t('common.loading') : t('auth.login')}
           </button>
         </form>
+        {providers.length > 0 && (
+          <div className={styles['sso-providers']}>
+            <p>{t('auth.orContinueWith')}</p>
+            {providers.map((provider) => (
+              <a
+                key={provider.id}
+                href={`/api/v1/auth/oidc/${provider.id}/login`}
+                className="btn-secondary"
+              >
+                {provider.name}
+              </a>
+            ))}
+          </div>
+        )}
+        <p className={styles['auth-link']}>
+          <a href="/reset-password">{t('auth.forgotPassword')}</a>
+        </p>
         <p className={styles['auth-link']}>
           {t('auth.noAccount')}{' '}
           <a href="/register">{t('auth.register')}</a>
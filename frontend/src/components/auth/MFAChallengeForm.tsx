@@ -0,0 +1,115 @@
+import { useState, useEffect, FormEvent } from 'react';
+import { useAuth } from '@/hooks/useAuth';
+import { useTranslation } from 'react-i18next';
+import { authApi } from '@/services/api/authApi';
+import type { MFAChallenge, TOTPEnrollment } from '@/types/auth.types';
+import styles from './LoginForm.module.scss';
+
+interface MFAChallengeFormProps {
+  challenge: MFAChallenge;
+  onComplete: () => void;
+}
+
+/**
+ * Second sign-in step: asks for a TOTP or recovery code, or sets up
+ * an authenticator first when the account requires one
+ */
+export const MFAChallengeForm = ({ challenge, onComplete }: MFAChallengeFormProps) => {
+  const { verifyMFA, confirmMFAEnrollment, loading, error } = useAuth();
+  const { t } = useTranslation();
+  const [code, setCode] = useState('');
+  const [enrollment, setEnrollment] = useState<TOTPEnrollment | null>(null);
+  const [enrollmentError, setEnrollmentError] = useState<string | null>(null);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+
+  /**
+   * Start authenticator setup when the account requires it
+   */
+  useEffect(() => {
+    if (!challenge.enrollmentRequired) {
+      return;
+    }
+    authApi
+      .beginMFAEnrollment(challenge.mfaToken)
+      .then(setEnrollment)
+      .catch((err: { response?: { data?: { error?: string } }; message?: string }) =>
+        setEnrollmentError(err?.response?.data?.error || err?.message || 'Setup failed')
+      );
+  }, [challenge]);
+
+  /**
+   * Handle form submission
+   */
+  const handleSubmit = async (e: FormEvent) => {
+    e.preventDefault();
+
+    if (challenge.enrollmentRequired) {
+      const result = await confirmMFAEnrollment(challenge.mfaToken, code);
+      if (result.success) {
+        setRecoveryCodes(result.recoveryCodes ?? []);
+      }
+      return;
+    }
+
+    const result = await verifyMFA(challenge.mfaToken, code);
+    if (result.success) {
+      onComplete();
+    }
+  };
+
+  if (recoveryCodes.length > 0) {
+    return (
+      <>
+        <h2>{t('auth.mfaRecoveryTitle')}</h2>
+        <p>{t('auth.mfaRecoveryHint')}</p>
+        <ul className={styles['recovery-codes']}>
+          {recoveryCodes.map((recoveryCode) => (
+            <li key={recoveryCode}>{recoveryCode}</li>
+          ))}
+        </ul>
+        <button type="button" className="btn-primary" onClick={onComplete}>
+          {t('auth.mfaContinue')}
+        </button>
+      </>
+    );
+  }
+
+  return (
+    <>
+      <h2>{challenge.enrollmentRequired ? t('auth.mfaEnrollTitle') : t('auth.mfaTitle')}</h2>
+      {(error || enrollmentError) && (
+        <div className="error-message">{error || enrollmentError}</div>
+      )}
+      {challenge.enrollmentRequired ? (
+        enrollment && (
+          <div className={styles['mfa-enrollment']}>
+            <p>{t('auth.mfaEnrollHint')}</p>
+            <label>{t('auth.mfaSecret')}</label>
+            <code>{enrollment.secret}</code>
+            <a href={enrollment.provisioning_uri}>{t('auth.mfaOpenApp')}</a>
+          </div>
+        )
+      ) : (
+        <p>{t('auth.mfaCodeHint')}</p>
+      )}
+      <form onSubmit={handleSubmit}>
+        <div className="form-group">
+          <label htmlFor="mfa-code">{t('auth.mfaCode')}</label>
+          <input
+            type="text"
+            id="mfa-code"
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            autoComplete="one-time-code"
+            autoFocus
+            required
+            disabled={loading}
+          />
+        </div>
+        <button type="submit" disabled={loading} className="btn-primary">
+          {loading ? t('common.loading') : t('auth.mfaVerify')}
+        </button>
+      </form>
+    </>
+  );
+};
@@ -0,0 +1,51 @@
+import { useEffect, useRef } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { useAuth } from '@/hooks/useAuth';
+import { useTranslation } from 'react-i18next';
+import styles from './LoginForm.module.scss';
+
+/**
+ * Completes single sign-on: exchanges the login code from the backend
+ * callback for tokens and opens the chat
+ */
+export const OIDCCallback = () => {
+  const navigate = useNavigate();
+  const { loginWithSSO } = useAuth();
+  const { t } = useTranslation();
+  const [searchParams] = useSearchParams();
+  const started = useRef(false);
+
+  useEffect(() => {
+    // The login code is single use, so exchange it only once
+    if (started.current) {
+      return;
+    }
+    started.current = true;
+
+    const code = searchParams.get('code');
+    if (!code) {
+      navigate('/login', { replace: true });
+      return;
+    }
+
+    loginWithSSO(code).then((result) => {
+      if (result.success) {
+        navigate('/chat', { replace: true });
+      } else if (result.mfa) {
+        navigate('/login', { replace: true, state: { mfa: result.mfa } });
+      } else {
+        navigate(`/login?sso_error=${encodeURIComponent(result.error ?? '')}`, {
+          replace: true,
+        });
+      }
+    });
+  }, [loginWithSSO, navigate, searchParams]);
+
+  return (
+    <div className={styles['auth-container']}>
+      <div className={styles['auth-form']}>
+        <h2>{t('auth.ssoSigningIn')}</h2>
+      </div>
+    </div>
+  );
+};
@@ -15,6 +15,7 @@ export const RegisterForm = () => {
   const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
   const [confirmPassword, setConfirmPassword] = useState('');
+  const [verificationSent, setVerificationSent] = useState(false);
 
   /**
    * Handle form submission
@@ -29,6 +30,10 @@ export const RegisterForm = () => {
     const result = await register({ email, username, password });
     if (result.success) {
       navigate('/chat');
+    } else if (result.mfa) {
+      navigate('/login', { state: { mfa: result.mfa } });
+    } else if (result.verificationRequired) {
+      setVerificationSent(true);
     }
   };
 
@@ -38,6 +43,20 @@ export const RegisterForm = () => {
     return null;
   }
 
+  if (verificationSent) {
+    return (
+      <div className={styles['auth-container']}>
+        <div className={styles['auth-form']}>
+          <h2>{t('auth.verifyEmailTitle')}</h2>
+          <p className={styles['auth-notice']}>{t('auth.verificationSent', { email })}</p>
+          <p className={styles['auth-link']}>
+            <a href="/login">{t('auth.login')}</a>
+          </p>
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className={styles['auth-container']}>
       <div className={styles['auth-form']}>
@@ -0,0 +1,138 @@
+import { useState, FormEvent } from 'react';
+import { useSearchParams } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import { authApi } from '@/services/api/authApi';
+import styles from './LoginForm.module.scss';
+
+/**
+ * Extract the error message of a failed request
+ */
+const getErrorMessage = (err: unknown, fallback: string): string =>
+  (err as { response?: { data?: { error?: string } } })?.response?.data?.error ||
+  (err as { message?: string })?.message ||
+  fallback;
+
+/**
+ * Password recovery: without a token it requests a reset email,
+ * with the token from the emailed link it sets a new password
+ */
+export const ResetPasswordForm = () => {
+  const { t } = useTranslation();
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get('token');
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [confirmPassword, setConfirmPassword] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [done, setDone] = useState(false);
+
+  /**
+   * Request a reset email or set the new password
+   */
+  const handleSubmit = async (e: FormEvent) => {
+    e.preventDefault();
+    if (token && password !== confirmPassword) {
+      return;
+    }
+
+    setLoading(true);
+    setError(null);
+    try {
+      if (token) {
+        await authApi.resetPassword(token, password);
+      } else {
+        await authApi.forgotPassword(email);
+      }
+      setDone(true);
+    } catch (err: unknown) {
+      setError(getErrorMessage(err, t('auth.resetPasswordError')));
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  if (done) {
+    return (
+      <div className={styles['auth-container']}>
+        <div className={styles['auth-form']}>
+          <h2>{t('auth.resetPasswordTitle')}</h2>
+          <p className={styles['auth-notice']}>
+            {token ? t('auth.passwordReset') : t('auth.resetLinkSent', { email })}
+          </p>
+          <p className={styles['auth-link']}>
+            <a href="/login">{t('auth.login')}</a>
+          </p>
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className={styles['auth-container']}>
+      <div className={styles['auth-form']}>
+        <h2>{t('auth.resetPasswordTitle')}</h2>
+        {error && <div className="error-message">{error}</div>}
+        {token && password !== confirmPassword && confirmPassword && (
+          <div className="error-message">{t('auth.passwordsNotMatch')}</div>
+        )}
+        <form onSubmit={handleSubmit}>
+          {token ? (
+            <>
+              <div className="form-group">
+                <label htmlFor="password">{t('auth.newPassword')}</label>
+                <input
+                  type="password"
+                  id="password"
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  required
+                  minLength={8}
+                  disabled={loading}
+                />
+              </div>
+              <div className="form-group">
+                <label htmlFor="confirmPassword">{t('auth.confirmPassword')}</label>
+                <input
+                  type="password"
+                  id="confirmPassword"
+                  value={confirmPassword}
+                  onChange={(e) => setConfirmPassword(e.target.value)}
+                  required
+                  disabled={loading}
+                />
+              </div>
+            </>
+          ) : (
+            <div className="form-group">
+              <label htmlFor="email">{t('auth.email')}</label>
+              <input
+                type="email"
+                id="email"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                required
+                disabled={loading}
+              />
+              <p className="form-hint">{t('auth.resetPasswordHint')}</p>
+            </div>
+          )}
+          <button
+            type="submit"
+            disabled={loading || (!!token && password !== confirmPassword)}
+            className="btn-primary"
+          >
+            {loading
+              ? t('common.loading')
+              : token
+                ? t('auth.setPassword')
+                : t('auth.sendResetLink')}
+          </button>
+        </form>
+        <p className={styles['auth-link']}>
+          <a href="/login">{t('auth.login')}</a>
+        </p>
+      </div>
+    </div>
+  );
+};
@@ -0,0 +1,107 @@
+import { useState, useEffect, useRef, FormEvent } from 'react';
+import { useSearchParams } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import { authApi } from '@/services/api/authApi';
+import styles from './LoginForm.module.scss';
+
+type VerificationStatus = 'verifying' | 'verified' | 'failed' | 'resend' | 'sent';
+
+/**
+ * Email verification: verifies the token from the emailed link,
+ * or requests a new link when there is no token or it has expired
+ */
+export const VerifyEmail = () => {
+  const { t } = useTranslation();
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get('token');
+  const [status, setStatus] = useState<VerificationStatus>(token ? 'verifying' : 'resend');
+  const [email, setEmail] = useState(searchParams.get('email') ?? '');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const started = useRef(false);
+
+  useEffect(() => {
+    // The token is single use, so verify it only once
+    if (!token || started.current) {
+      return;
+    }
+    started.current = true;
+
+    authApi
+      .verifyEmail(token)
+      .then(() => setStatus('verified'))
+      .catch((err: unknown) => {
+        setError(
+          (err as { response?: { data?: { error?: string } } })?.response?.data?.error ||
+            t('auth.verifyEmailError')
+        );
+        setStatus('failed');
+      });
+  }, [token, t]);
+
+  /**
+   * Request a new verification email
+   */
+  const handleResend = async (e: FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError(null);
+    try {
+      await authApi.resendVerification(email);
+      setStatus('sent');
+    } catch (err: unknown) {
+      setError(
+        (err as { response?: { data?: { error?: string } } })?.response?.data?.error ||
+          t('auth.verifyEmailError')
+      );
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className={styles['auth-container']}>
+      <div className={styles['auth-form']}>
+        <h2>{t('auth.verifyEmailTitle')}</h2>
+        {error && <div className="error-message">{error}</div>}
+        {status === 'verifying' && <p className={styles['auth-notice']}>{t('auth.verifyingEmail')}</p>}
+        {status === 'verified' && (
+          <>
+            <p className={styles['auth-notice']}>{t('auth.emailVerified')}</p>
+            <p className={styles['auth-link']}>
+              <a href="/login">{t('auth.login')}</a>
+            </p>
+          </>
+        )}
+        {status === 'sent' && (
+          <p className={styles['auth-notice']}>{t('auth.verificationSent', { email })}</p>
+        )}
+        {status === 'failed' && (
+          <p className={styles['auth-link']}>
+            <button type="button" className="btn-secondary" onClick={() => setStatus('resend')}>
+              {t('auth.resendVerification')}
+            </button>
+          </p>
+        )}
+        {status === 'resend' && (
+          <form onSubmit={handleResend}>
+            <div className="form-group">
+              <label htmlFor="email">{t('auth.email')}</label>
+              <input
+                type="email"
+                id="email"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                required
+                disabled={loading}
+              />
+            </div>
+            <button type="submit" disabled={loading} className="btn-primary">
+              {loading ? t('common.loading') : t('auth.resendVerification')}
+            </button>
+          </form>
+        )}
+      </div>
+    </div>
+  );
+};
@@ -9,11 +9,39 @@ import {
   selectIsAuthenticated,
   selectIsGuest,
   selectAccessToken,
+  selectRefreshToken,
   selectAuthLoading,
   selectAuthError,
 } from '@/stores';
 import { authApi } from '@/services/api/authApi';
-import type { RegisterRequest, LoginRequest } from '@/types/auth.types';
+import type {
+  RegisterRequest,
+  LoginRequest,
+  AuthResponse,
+  MFAChallenge,
+} from '@/types/auth.types';
+
+/**
+ * Result of a sign-in attempt
+ */
+interface AuthResult {
+  success: boolean;
+  error?: string;
+  mfa?: MFAChallenge;
+  recoveryCodes?: string[];
+  verificationRequired?: boolean;
+}
+
+/**
+ * Extract the MFA challenge of a sign-in response, if a second step is needed
+ */
+const getMFAChallenge = (response: AuthResponse): MFAChallenge | undefined =>
+  response.mfa_required && response.mfa_token
+    ? {
+        mfaToken: response.mfa_token,
+        enrollmentRequired: response.mfa_enrollment_required ?? false,
+      }
+    : undefined;
 
 /**
  * Hook for authentication operations
@@ -23,6 +51,7 @@ export const useAuth = () => {
   const isAuthenticated = useAppSelector(selectIsAuthenticated);
   const isGuest = useAppSelector(selectIsGuest);
   const accessToken = useAppSelector(selectAccessToken);
+  const refreshToken = useAppSelector(selectRefreshToken);
   const loading = useAppSelector(selectAuthLoading);
   const error = useAppSelector(selectAuthError);
 
@@ -30,11 +59,19 @@ export const useAuth = () => {
    * Register a new user
    */
   const register = useCallback(
-    async (data: RegisterRequest) => {
+    async (data: RegisterRequest): Promise<AuthResult> => {
       try {
         dispatch(setAuthLoading(true));
         dispatch(setAuthError(null));
         const response = await authApi.register(data);
+        // The account must be verified by email before signing in
+        if (response.email_verification_required) {
+          return { success: false, verificationRequired: true };
+        }
+        const mfa = getMFAChallenge(response);
+        if (mfa) {
+          return { success: false, mfa };
+        }
         dispatch(setAuth(response));
         return { success: true };
       } catch (err: unknown) {
@@ -55,11 +92,72 @@ export const useAuth = () => {
    * Login user
    */
   const login = useCallback(
-    async (data: LoginRequest) => {
+    async (data: LoginRequest): Promise<AuthResult> => {
       try {
         dispatch(setAuthLoading(true));
         dispatch(setAuthError(null));
         const response = await authApi.login(data);
+        const mfa = getMFAChallenge(response);
+        if (mfa) {
+          return { success: false, mfa };
+        }
+        dispatch(setAuth(response));
+        return { success: true };
+      } catch (err: unknown) {
+        const errorMessage =
+          (err as { response?: { data?: { error?: string }; message?: string }; message?: string })?.response?.data?.error ||
+          (err as { message?: string })?.message ||
+          'Login failed';
+        const verificationRequired =
+          (err as { response?: { data?: { email_verification_required?: boolean } } })?.response?.data
+            ?.email_verification_required ?? false;
+        dispatch(setAuthError(errorMessage));
+        return { success: false, error: errorMessage, verificationRequired };
+      } finally {
+        dispatch(setAuthLoading(false));
+      }
+    },
+    [dispatch]
+  );
+
+  /**
+   * Complete single sign-on with the login code from the callback
+   */
+  const loginWithSSO = useCallback(
+    async (code: string): Promise<AuthResult> => {
+      try {
+        dispatch(setAuthLoading(true));
+        dispatch(setAuthError(null));
+        const response = await authApi.exchangeOIDCCode(code);
+        const mfa = getMFAChallenge(response);
+        if (mfa) {
+          return { success: false, mfa };
+        }
+        dispatch(setAuth(response));
+        return { success: true };
+      } catch (err: unknown) {
+        const errorMessage =
+          (err as { response?: { data?: { error?: string }; message?: string }; message?: string })?.response?.data?.error ||
+          (err as { message?: string })?.message ||
+          'Login failed';
+        dispatch(setAuthError(errorMessage));
+        return { success: false, error: errorMessage };
+      } finally {
+        dispatch(setAuthLoading(false));
+      }
+    },
+    [dispatch]
+  );
+
+  /**
+   * Complete sign-in with a TOTP or recovery code
+   */
+  const verifyMFA = useCallback(
+    async (mfaToken: string, code: string): Promise<AuthResult> => {
+      try {
+        dispatch(setAuthLoading(true));
+        dispatch(setAuthError(null));
+        const response = await authApi.verifyMFA(mfaToken, code);
         dispatch(setAuth(response));
         return { success: true };
       } catch (err: unknown) {
@@ -76,6 +174,32 @@ export const useAuth = () => {
     [dispatch]
   );
 
+  /**
+   * Confirm the authenticator set up during sign-in and complete the sign-in.
+   * The result carries the recovery codes to show once.
+   */
+  const confirmMFAEnrollment = useCallback(
+    async (mfaToken: string, code: string): Promise<AuthResult> => {
+      try {
+        dispatch(setAuthLoading(true));
+        dispatch(setAuthError(null));
+        const response = await authApi.confirmMFAEnrollment(mfaToken, code);
+        dispatch(setAuth(response));
+        return { success: true, recoveryCodes: response.recovery_codes };
+      } catch (err: unknown) {
+        const errorMessage =
+          (err as { response?: { data?: { error?: string }; message?: string }; message?: string })?.response?.data?.error ||
+          (err as { message?: string })?.message ||
+          'Login failed';
+        dispatch(setAuthError(errorMessage));
+        return { success: false, error: errorMessage };
+      } finally {
+        dispatch(setAuthLoading(false));
+      }
+    },
+    [dispatch]
+  );
+
   /**
    * Start guest session (creates temporary account)
    */
@@ -109,13 +233,13 @@ export const useAuth = () => {
     }
 
     try {
-      await authApi.logout();
+      await authApi.logout(refreshToken);
     } catch {
       // Ignore logout errors
     } finally {
       dispatch(clearAuth());
     }
-  }, [dispatch, isGuest]);
+  }, [dispatch, isGuest, refreshToken]);
 
   return {
     isAuthenticated,
@@ -125,6 +249,9 @@ export const useAuth = () => {
     error,
     register,
     login,
+    loginWithSSO,
+    verifyMFA,
+    confirmMFAEnrollment,
     startGuestSession,
     logout,
   };
@@ -4,6 +4,8 @@ import type {
   LoginRequest,
   AuthResponse,
   RefreshTokenRequest,
+  OIDCProvider,
+  TOTPEnrollment,
 } from '@/types/auth.types';
 
 /**
@@ -46,10 +48,100 @@ export const authApi = {
   },
 
   /**
-   * Logout user
+   * Logout user and revoke the refresh token
    */
-  logout: async (): Promise<void> => {
-    await apiClient.post('/api/v1/auth/logout');
+  logout: async (refreshToken: string | null): Promise<void> => {
+    await apiClient.post('/api/v1/auth/logout', {
+      refresh_token: refreshToken ?? '',
+    });
+  },
+
+  /**
+   * Complete sign-in with a TOTP or recovery code
+   */
+  verifyMFA: async (mfaToken: string, code: string): Promise<AuthResponse> => {
+    const response = await apiClient.post<AuthResponse>(
+      '/api/v1/auth/mfa/verify',
+      { mfa_token: mfaToken, code }
+    );
+    return response.data;
+  },
+
+  /**
+   * Set up a required authenticator during sign-in
+   */
+  beginMFAEnrollment: async (mfaToken: string): Promise<TOTPEnrollment> => {
+    const response = await apiClient.post<TOTPEnrollment>(
+      '/api/v1/auth/mfa/enroll',
+      { mfa_token: mfaToken }
+    );
+    return response.data;
+  },
+
+  /**
+   * Confirm the authenticator set up during sign-in
+   */
+  confirmMFAEnrollment: async (
+    mfaToken: string,
+    code: string
+  ): Promise<AuthResponse> => {
+    const response = await apiClient.post<AuthResponse>(
+      '/api/v1/auth/mfa/enroll/confirm',
+      { mfa_token: mfaToken, code }
+    );
+    return response.data;
+  },
+
+  /**
+   * Request a password reset email
+   */
+  forgotPassword: async (email: string): Promise<void> => {
+    await apiClient.post('/api/v1/auth/password/forgot', { email });
+  },
+
+  /**
+   * Set a new password with the token from a password reset email
+   */
+  resetPassword: async (token: string, newPassword: string): Promise<void> => {
+    await apiClient.post('/api/v1/auth/password/reset', {
+      token,
+      new_password: newPassword,
+    });
+  },
+
+  /**
+   * Verify an email address with the token from a verification email
+   */
+  verifyEmail: async (token: string): Promise<void> => {
+    await apiClient.post('/api/v1/auth/verify-email', { token });
+  },
+
+  /**
+   * Request a new verification email
+   */
+  resendVerification: async (email: string): Promise<void> => {
+    await apiClient.post('/api/v1/auth/verify-email/resend', { email });
+  },
+
+  /**
+   * Get configured single sign-on providers
+   */
+  getOIDCProviders: async (): Promise<OIDCProvider[]> => {
+    const response = await apiClient.get<OIDCProvider[]>(
+      '/api/v1/auth/oidc/providers'
+    );
+    return response.data;
+  },
+
+  /**
+   * Exchange a single sign-on login code for tokens
+   */
+  exchangeOIDCCode: async (code: string): Promise<AuthResponse> => {
+    const response = await apiClient.post<AuthResponse>(
+      '/api/v1/auth/oidc/exchange',
+      { code }
+    );
+    return response.data;
   },
 
   /**
@@ -23,6 +23,35 @@ export interface AuthResponse {
   refresh_token: string;
   token_type: string;
   expires_in: number;
+  mfa_required?: boolean;
+  mfa_enrollment_required?: boolean;
+  mfa_token?: string;
+  recovery_codes?: string[];
+  email_verification_required?: boolean;
+}
+
+/**
+ * Second sign-in step: a code is needed, or an authenticator must be set up first
+ */
+export interface MFAChallenge {
+  mfaToken: string;
+  enrollmentRequired: boolean;
+}
+
+/**
+ * New authenticator secret for two-factor authentication
+ */
+export interface TOTPEnrollment {
+  secret: string;
+  provisioning_uri: string;
+}
+
+/**
+ * Single sign-on provider
+ */
+export interface OIDCProvider {
+  id: string;
+  name: string;
 }
 
 /**
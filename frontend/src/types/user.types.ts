@@ -9,6 +9,7 @@ export interface UserProfile {
   updated_at: string;
   last_login_at?: string;
   is_active: boolean;
+  role: string;
 }
 
 /**
@@ -5,12 +5,24 @@ from typing import List, Optional, Dict
 from pydantic import BaseModel, Field
 
 
+class ImageDTO(BaseModel):
+    """
+    Base64-encoded image content part
+    """
+    mime_type: str = Field(..., description="Image MIME type, e.g. 'image/png'")
+    data: str = Field(..., description="Base64-encoded image data")
+
+
 class MessageDTO(BaseModel):
     """
     Message DTO for conversation history
     """
     role: str = Field(..., description="Message role: 'user' or 'assistant'")
     content: str = Field(..., description="Message content")
+    images: Optional[List[ImageDTO]] = Field(
+        default=None,
+        description="Images attached to the message (vision models only)"
+    )
 
 
 class GenerationRequest(BaseModel):
@@ -26,6 +38,11 @@ class GenerationRequest(BaseModel):
         default=None,
         description="Model name (optional, uses default if not specified)"
     )
+    max_tokens: Optional[int] = Field(
+        default=None,
+        gt=0,
+        description="Maximum tokens to generate (capped by MAX_NEW_TOKENS)"
+    )
 
 
 class TokenResponse(BaseModel):
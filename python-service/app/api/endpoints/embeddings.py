@@ -1,10 +1,11 @@
 """
-Embeddings endpoints for future RAG functionality
+Embeddings endpoints for RAG functionality
 """
 import logging
 from fastapi import APIRouter, HTTPException
 
 from app.api.dto import EmbeddingRequest, EmbeddingResponse
+from app.service import InferenceService
 
 logger = logging.getLogger(__name__)
 
@@ -14,7 +15,7 @@ router = APIRouter()
 @router.post("/", response_model=EmbeddingResponse)
 async def create_embeddings(request: EmbeddingRequest):
     """
-    Create embeddings for text (placeholder for future RAG)
+    Create embeddings for text (used by the Go backend for document retrieval)
     
     Args:
         request: Embedding request with text
@@ -22,10 +23,15 @@ async def create_embeddings(request: EmbeddingRequest):
     Returns:
         Embedding vector
     """
-    # TODO: Implement embedding generation when RAG is needed
-    # For now, return placeholder
-    raise HTTPException(
-        status_code=501,
-        detail="Embeddings endpoint not yet implemented"
-    )
-
+    try:
+        service = InferenceService()
+        embedding = await service.embed(request.text)
+        
+        return EmbeddingResponse(
+            embedding=embedding,
+            dimensions=len(embedding)
+        )
+    
+    except Exception as e:
+        logger.error(f"Error in create_embeddings: {e}", exc_info=True)
+        raise HTTPException(status_code=500, detail=str(e))
@@ -1,19 +1,25 @@
 """
 Generation endpoints for text generation
 """
+import base64
+import binascii
+import io
 import json
 import logging
-from typing import List, Dict
+from typing import List, Dict, Optional
 
 from fastapi import APIRouter, HTTPException
 from fastapi.responses import StreamingResponse
+from PIL import Image
 
 from app.api.dto import (
     GenerationRequest,
     GenerationResponse,
     TokenResponse,
-    MessageDTO
+    MessageDTO,
+    ImageDTO
 )
+from app.model import ModelManager
 from app.service import InferenceService
 
 logger = logging.getLogger(__name__)
@@ -21,6 +27,45 @@ logger = logging.getLogger(__name__)
 router = APIRouter()
 
 
+def _build_history(request: GenerationRequest) -> Optional[List[Dict]]:
+    """
+    Convert history DTOs to the dict format of the inference service.
+    Images are decoded for vision-language models and rejected for text-only models.
+    """
+    if not request.history:
+        return None
+    
+    has_images = any(msg.images for msg in request.history)
+    if has_images and not ModelManager.get_instance().supports_images():
+        raise HTTPException(
+            status_code=400,
+            detail="Loaded model does not support image inputs"
+        )
+    
+    history = []
+    for msg in request.history:
+        history.append({
+            "role": msg.role,
+            "content": msg.content,
+            "images": [_decode_image(image) for image in msg.images or []]
+        })
+    return history
+
+
+def _decode_image(image: ImageDTO) -> Image.Image:
+    """
+    Decode a base64-encoded image content part
+    """
+    try:
+        decoded = Image.open(io.BytesIO(base64.b64decode(image.data, validate=True)))
+        return decoded.convert("RGB")
+    except (binascii.Error, OSError, ValueError) as e:
+        raise HTTPException(
+            status_code=400,
+            detail=f"Invalid {image.mime_type} image: {e}"
+        )
+
+
 @router.post("/", response_model=GenerationResponse)
 async def generate_completion(request: GenerationRequest):
     """
@@ -32,19 +77,13 @@ async def generate_completion(request: GenerationRequest):
     Returns:
         Generated response
     """
+    history = _build_history(request)
+
     try:
         service = InferenceService()
         
-        # Convert DTOs to dict format
-        history = None
-        if request.history:
-            history = [
-                {"role": msg.role, "content": msg.content}
-                for msg in request.history
-            ]
-        
         # Generate response
-        response = await service.generate(request.prompt, history)
+        response = await service.generate(request.prompt, history, request.max_tokens)
         
         # Count tokens (approximate)
         tokenizer = service.get_tokenizer()
@@ -74,20 +113,14 @@ async def stream_completion(request: GenerationRequest):
     """
     from fastapi.responses import StreamingResponse
     
+    history = _build_history(request)
+    
     async def json_stream_generator():
         """
         Generator for newline-delimited JSON stream
         """
         service = InferenceService()
         
-        # Convert DTOs to dict format
-        history = None
-        if request.history:
-            history = [
-                {"role": msg.role, "content": msg.content}
-                for msg in request.history
-            ]
-        
         # Track full response and token count
         full_response = ""
         token_count = 0
@@ -96,7 +129,7 @@ async def stream_completion(request: GenerationRequest):
         
         try:
             # Stream tokens
-            async for token_chunk in service.generate_stream(request.prompt, history):
+            async for token_chunk in service.generate_stream(request.prompt, history, request.max_tokens):
                 has_tokens = True
                 full_response += token_chunk
                 token_count += len(tokenizer.encode(token_chunk))
@@ -188,24 +221,17 @@ async def websocket_generate(websocket):
         # Receive request
         data = await websocket.receive_json()
         request = GenerationRequest(**data)
+        history = _build_history(request)
         
         service = InferenceService()
         
-        # Convert DTOs to dict format
-        history = None
-        if request.history:
-            history = [
-                {"role": msg.role, "content": msg.content}
-                for msg in request.history
-            ]
-        
         # Track full response
         full_response = ""
         token_count = 0
         tokenizer = service.get_tokenizer()
         
         # Stream tokens
-        async for token_chunk in service.generate_stream(request.prompt, history):
+        async for token_chunk in service.generate_stream(request.prompt, history, request.max_tokens):
             full_response += token_chunk
             token_count += len(tokenizer.encode(token_chunk))
             
@@ -26,6 +26,7 @@ class Settings(BaseSettings):
     DEVICE: str = "cuda"  # or "cpu"
     QUANTIZATION: str = "none"  # "4bit" (for 7B), "8bit", or "none" (for 3B)
     DTYPE: str = "bfloat16"  # "float16", "bfloat16" (recommended for Qwen), or "float32"
+    VISION: bool = False  # MODEL_NAME is a vision-language model accepting images, e.g. "Qwen/Qwen2-VL-2B-Instruct"
     
     # Generation settings
     MAX_NEW_TOKENS: int = 512
@@ -6,6 +6,8 @@ import torch
 from typing import Optional, Any
 from transformers import (
     AutoModelForCausalLM,
+    AutoModelForVision2Seq,
+    AutoProcessor,
     AutoTokenizer,
     BitsAndBytesConfig
 )
@@ -31,6 +33,7 @@ class ModelManager:
         # Initialize instance variables
         self._model: Optional[Any] = None
         self._tokenizer: Optional[Any] = None
+        self._processor: Optional[Any] = None
         self._device: Optional[str] = None
         
         # Initialize only once (prevent re-initialization)
@@ -84,11 +87,20 @@ class ModelManager:
             if settings.HUGGINGFACE_TOKEN:
                 tokenizer_kwargs["token"] = settings.HUGGINGFACE_TOKEN
             
-            self._tokenizer = AutoTokenizer.from_pretrained(
-                settings.MODEL_NAME,
-                trust_remote_code=True,
-                **tokenizer_kwargs
-            )
+            # Vision-language models use a processor that prepares both text and images
+            if settings.VISION:
+                self._processor = AutoProcessor.from_pretrained(
+                    settings.MODEL_NAME,
+                    trust_remote_code=True,
+                    **tokenizer_kwargs
+                )
+                self._tokenizer = self._processor.tokenizer
+            else:
+                self._tokenizer = AutoTokenizer.from_pretrained(
+                    settings.MODEL_NAME,
+                    trust_remote_code=True,
+                    **tokenizer_kwargs
+                )
             
             # Set pad_token_id if not set (use eos_token_id as fallback)
             if self._tokenizer.pad_token_id is None:
@@ -137,7 +149,8 @@ class ModelManager:
                 else:
                     model_kwargs["torch_dtype"] = torch.float32
             
-            self._model = AutoModelForCausalLM.from_pretrained(
+            model_class = AutoModelForVision2Seq if settings.VISION else AutoModelForCausalLM
+            self._model = model_class.from_pretrained(
                 settings.MODEL_NAME,
                 **model_kwargs
             )
@@ -176,6 +189,26 @@ class ModelManager:
             self.load_model()
         return self._tokenizer
     
+    def get_processor(self):
+        """
+        Get the loaded processor of a vision-language model (loads if not already loaded)
+        
+        Returns:
+            Loaded processor, or None for text-only models
+        """
+        if settings.VISION and self._processor is None:
+            self.load_model()
+        return self._processor
+    
+    def supports_images(self) -> bool:
+        """
+        Check if the configured model accepts image inputs
+        
+        Returns:
+            True for vision-language models
+        """
+        return settings.VISION
+    
     def is_model_loaded(self) -> bool:
         """
         Check if model is loaded
@@ -215,6 +248,10 @@ class ModelManager:
             del self._tokenizer
             self._tokenizer = None
         
+        if self._processor is not None:
+            del self._processor
+            self._processor = None
+        
         if self._device == "cuda":
             torch.cuda.empty_cache()
         
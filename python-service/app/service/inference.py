@@ -14,6 +14,15 @@ from app.config import settings
 logger = logging.getLogger(__name__)
 
 
+def _max_new_tokens(max_tokens: Optional[int]) -> int:
+    """
+    Limit of generated tokens: the requested maximum, capped by MAX_NEW_TOKENS
+    """
+    if max_tokens is None:
+        return settings.MAX_NEW_TOKENS
+    return min(max_tokens, settings.MAX_NEW_TOKENS)
+
+
 class StopOnTokens(StoppingCriteria):
     """
     Custom stopping criteria that stops generation when any stop token is encountered
@@ -33,16 +42,18 @@ class StreamingGenerator:
     Handles streaming text generation with token buffering
     """
     
-    def __init__(self, model, tokenizer):
+    def __init__(self, model, tokenizer, processor=None):
         """
         Initialize streaming generator
         
         Args:
             model: Loaded model
             tokenizer: Loaded tokenizer
+            processor: Processor of a vision-language model (None for text-only models)
         """
         self.model = model
         self.tokenizer = tokenizer
+        self.processor = processor
         self.device = model.device if hasattr(model, 'device') else next(model.parameters()).device
     
     def _get_stop_tokens(self) -> list:
@@ -74,7 +85,7 @@ class StreamingGenerator:
         
         Args:
             prompt: Current user prompt
-            history: List of previous messages with 'role' and 'content'
+            history: List of previous messages with 'role', 'content' and optional 'images'
         
         Returns:
             Formatted prompt string
@@ -96,11 +107,24 @@ class StreamingGenerator:
             role = msg.get("role", "")
             content = msg.get("content", "")
             if role in ["user", "assistant"]:
-                messages.append({"role": role, "content": content})
+                messages.append({"role": role, "content": content, "images": msg.get("images") or []})
         
         # Add current user prompt
         messages.append({"role": "user", "content": prompt})
         
+        # Vision-language models take content parts with image placeholders
+        if self.processor is not None:
+            for msg in messages:
+                images = msg.pop("images", [])
+                msg["content"] = [{"type": "image"} for _ in images] + [{"type": "text", "text": msg["content"]}]
+            return self.processor.apply_chat_template(
+                messages,
+                tokenize=False,
+                add_generation_prompt=True
+            )
+        for msg in messages:
+            msg.pop("images", None)
+        
         # Use tokenizer's apply_chat_template for proper model format
         try:
             formatted = self.tokenizer.apply_chat_template(
@@ -121,10 +145,40 @@ class StreamingGenerator:
         
         return formatted
     
+    def prepare_inputs(self, prompt: str, history: List[Dict]):
+        """
+        Tokenize the formatted prompt together with the images of the history
+        
+        Args:
+            prompt: Current user prompt
+            history: Conversation history
+        
+        Returns:
+            Model inputs on the model device
+        """
+        formatted_prompt = self.format_prompt(prompt, history)
+        
+        if self.processor is None:
+            return self.tokenizer(
+                formatted_prompt,
+                return_tensors="pt",
+                truncation=True,
+                max_length=settings.CONTEXT_WINDOW
+            ).to(self.device)
+        
+        # Images in the order of their placeholders in the prompt
+        images = [image for msg in history if msg.get("role") in ["user", "assistant"] for image in msg.get("images") or []]
+        return self.processor(
+            text=[formatted_prompt],
+            images=images or None,
+            return_tensors="pt"
+        ).to(self.device)
+    
     async def generate_stream(
         self,
         prompt: str,
-        history: Optional[List[Dict[str, str]]] = None
+        history: Optional[List[Dict[str, str]]] = None,
+        max_tokens: Optional[int] = None
     ) -> AsyncGenerator[str, None]:
         """
         Generate response stream token by token
@@ -132,6 +186,7 @@ class StreamingGenerator:
         Args:
             prompt: User prompt
             history: Conversation history
+            max_tokens: Maximum tokens to generate, capped by MAX_NEW_TOKENS
         
         Yields:
             Generated tokens as strings
@@ -139,16 +194,8 @@ class StreamingGenerator:
         if history is None:
             history = []
         
-        # Format prompt with history
-        formatted_prompt = self.format_prompt(prompt, history)
-        
-        # Tokenize input
-        inputs = self.tokenizer(
-            formatted_prompt,
-            return_tensors="pt",
-            truncation=True,
-            max_length=settings.CONTEXT_WINDOW
-        ).to(self.device)
+        # Format prompt with history and tokenize input
+        inputs = self.prepare_inputs(prompt, history)
         
         # Create streamer for token-by-token generation
         streamer = TextIteratorStreamer(
@@ -167,7 +214,7 @@ class StreamingGenerator:
         # Two stopping conditions: stop tokens (via stopping_criteria) and max tokens (via max_new_tokens)
         generation_kwargs = {
             **inputs,
-            "max_new_tokens": settings.MAX_NEW_TOKENS,
+            "max_new_tokens": _max_new_tokens(max_tokens),
             "eos_token_id": self.tokenizer.eos_token_id,
             "pad_token_id": self.tokenizer.pad_token_id or self.tokenizer.eos_token_id,
             "temperature": settings.TEMPERATURE,
@@ -247,7 +294,8 @@ class StreamingGenerator:
     def generate_sync(
         self,
         prompt: str,
-        history: Optional[List[Dict[str, str]]] = None
+        history: Optional[List[Dict[str, str]]] = None,
+        max_tokens: Optional[int] = None
     ) -> str:
         """
         Generate response synchronously (for testing)
@@ -255,6 +303,7 @@ class StreamingGenerator:
         Args:
             prompt: User prompt
             history: Conversation history
+            max_tokens: Maximum tokens to generate, capped by MAX_NEW_TOKENS
         
         Returns:
             Generated response
@@ -262,16 +311,8 @@ class StreamingGenerator:
         if history is None:
             history = []
         
-        # Format prompt
-        formatted_prompt = self.format_prompt(prompt, history)
-        
-        # Tokenize
-        inputs = self.tokenizer(
-            formatted_prompt,
-            return_tensors="pt",
-            truncation=True,
-            max_length=settings.CONTEXT_WINDOW
-        ).to(self.device)
+        # Format prompt and tokenize
+        inputs = self.prepare_inputs(prompt, history)
         
         # Get stop tokens
         stop_token_ids = self._get_stop_tokens()
@@ -283,7 +324,7 @@ class StreamingGenerator:
         with torch.no_grad():
             outputs = self.model.generate(
                 **inputs,
-                max_new_tokens=settings.MAX_NEW_TOKENS,
+                max_new_tokens=_max_new_tokens(max_tokens),
                 eos_token_id=self.tokenizer.eos_token_id,
                 pad_token_id=self.tokenizer.pad_token_id or self.tokenizer.eos_token_id,
                 temperature=settings.TEMPERATURE,
@@ -325,8 +366,9 @@ class InferenceService:
         if self._generator is None:
             model = self.model_manager.get_model()
             tokenizer = self.model_manager.get_tokenizer()
+            processor = self.model_manager.get_processor()
             
-            self._generator = StreamingGenerator(model, tokenizer)
+            self._generator = StreamingGenerator(model, tokenizer, processor)
         
         return self._generator
     
@@ -342,7 +384,8 @@ class InferenceService:
     async def generate_stream(
         self,
         prompt: str,
-        history: Optional[List[Dict[str, str]]] = None
+        history: Optional[List[Dict[str, str]]] = None,
+        max_tokens: Optional[int] = None
     ) -> AsyncGenerator[str, None]:
         """
         Generate streaming response
@@ -350,18 +393,20 @@ class InferenceService:
         Args:
             prompt: User prompt
             history: Conversation history
+            max_tokens: Maximum tokens to generate, capped by MAX_NEW_TOKENS
         
         Yields:
             Generated tokens
         """
         generator = self._get_generator()
-        async for token in generator.generate_stream(prompt, history):
+        async for token in generator.generate_stream(prompt, history, max_tokens):
             yield token
     
     async def generate(
         self,
         prompt: str,
-        history: Optional[List[Dict[str, str]]] = None
+        history: Optional[List[Dict[str, str]]] = None,
+        max_tokens: Optional[int] = None
     ) -> str:
         """
         Generate complete response (synchronous)
@@ -369,10 +414,55 @@ class InferenceService:
         Args:
             prompt: User prompt
             history: Conversation history
+            max_tokens: Maximum tokens to generate, capped by MAX_NEW_TOKENS
         
         Returns:
             Generated response
         """
         generator = self._get_generator()
-        return generator.generate_sync(prompt, history)
+        return generator.generate_sync(prompt, history, max_tokens)
 
+    
+    async def embed(self, text: str) -> List[float]:
+        """
+        Compute text embedding (mean-pooled last hidden state, L2-normalized)
+        
+        Args:
+            text: Text to embed
+        
+        Returns:
+            Embedding vector
+        """
+        return await asyncio.to_thread(self._embed_sync, text)
+    
+    def _embed_sync(self, text: str) -> List[float]:
+        """
+        Compute text embedding in a worker thread
+        
+        Args:
+            text: Text to embed
+        
+        Returns:
+            Embedding vector
+        """
+        model = self.model_manager.get_model()
+        tokenizer = self.model_manager.get_tokenizer()
+        device = model.device if hasattr(model, 'device') else next(model.parameters()).device
+        
+        inputs = tokenizer(
+            text,
+            return_tensors="pt",
+            truncation=True,
+            max_length=512
+        ).to(device)
+        
+        with torch.no_grad():
+            outputs = model(**inputs, output_hidden_states=True)
+        
+        # Mean pooling over non-padding tokens
+        hidden = outputs.hidden_states[-1]
+        mask = inputs["attention_mask"].unsqueeze(-1).to(hidden.dtype)
+        pooled = (hidden * mask).sum(dim=1) / mask.sum(dim=1).clamp(min=1)
+        pooled = torch.nn.functional.normalize(pooled.float(), dim=-1)
+        
+        return pooled[0].cpu().tolist()